@@ -16,6 +16,7 @@
 	"strings"
 	"time"
 
+	"github.com/leighmacdonald/bd-api/domain"
 	"github.com/leighmacdonald/steamid/v4/steamid"
 )
 
@@ -34,6 +35,15 @@
 	errTooMany            = errors.New("too many results requested")
 	errLoadFailed         = errors.New("could not load remote resource")
 	errInternalError      = errors.New("internal server error, please try again later")
+	errInvalidCategory    = errors.New("invalid ban category")
+	errInvalidFormat      = errors.New("invalid match format")
+	errInvalidDate        = errors.New("invalid date")
+	errInvalidClass       = errors.New("invalid player class")
+	errInvalidInterval    = errors.New("invalid interval")
+	errInvalidStat        = errors.New("invalid leaderboard stat")
+	errUnauthorized       = errors.New("invalid or missing upload key")
+	errInvalidLogFile     = errors.New("invalid log file")
+	errInvalidDemo        = errors.New("invalid demo file")
 )
 
 func createRouter(database *pgStore, cacheHandler cache, config appConfig) (*http.ServeMux, error) {
@@ -44,6 +54,9 @@ func createRouter(database *pgStore, cacheHandler cache, config appConfig) (*htt
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /log/{log_id}", handleGetLogByID(database))
+	mux.HandleFunc("POST /log/upload", handlePostLogUpload(database, config))
+	mux.HandleFunc("POST /upload", handlePostLogsTFUpload(database, config))
+	mux.HandleFunc("POST /demo", handlePostDemo(database, cacheHandler))
 	mux.HandleFunc("GET /bans", handleGetBans())
 	mux.HandleFunc("GET /summary", handleGetSummary(cacheHandler))
 	mux.HandleFunc("GET /profile", handleGetProfile(database, cacheHandler))
@@ -54,14 +67,27 @@ func createRouter(database *pgStore, cacheHandler cache, config appConfig) (*htt
 	mux.HandleFunc("GET /bd", handleGetBotDetector(database))
 	mux.HandleFunc("GET /log/player/{steam_id}", handleGetLogsSummary(database))
 	mux.HandleFunc("GET /log/player/{steam_id}/list", handleGetLogsList(database))
+	mux.HandleFunc("GET /log/player/{steam_id}/classes", handleGetLogsClassStats(database))
+	mux.HandleFunc("GET /log/player/{steam_id}/teammates", handleGetLogsTeammates(database))
+	mux.HandleFunc("GET /log/together", handleGetLogsShared(database))
+	mux.HandleFunc("GET /log/leaderboard", handleGetLogsLeaderboard(database))
+	mux.HandleFunc("GET /log/maps", handleGetLogsMaps(database))
+	mux.HandleFunc("GET /log/formats", handleGetLogsFormats(database))
 	mux.HandleFunc("GET /serveme", handleGetServemeList(database))
 	mux.HandleFunc("GET /steamid/{steam_id}", handleGetSteamID())
 	mux.HandleFunc("GET /", handleGetIndex())
 	mux.HandleFunc("GET /stats", handleGetStats(database))
 	mux.HandleFunc("GET /list/rgl", handleGetRGLList(database, config))
 	mux.HandleFunc("GET /list/etf2l", handleGetETF2LList(database, config))
+	mux.HandleFunc("GET /list/ugc", handleGetUGCList(database, config))
 	mux.HandleFunc("GET /list/serveme", handleGetServemeListBD(database, config))
 	mux.HandleFunc("GET /rgl/player_history", handleGetRGLPlayerHistory(database))
+	mux.HandleFunc("GET /rgl/seasons", handleGetRGLSeasons(database))
+	mux.HandleFunc("GET /rgl/seasons/{season_id}", handleGetRGLSeason(database))
+	mux.HandleFunc("GET /rgl/teams/{team_id}", handleGetRGLTeam(database))
+	mux.HandleFunc("GET /rgl/matches/{match_id}", handleGetRGLMatch(database))
+	mux.HandleFunc("GET /rgl/player/{steam_id}/matches", handleGetRGLPlayerMatches(database))
+	mux.HandleFunc("GET /etf2l/player_history", handleGetETF2LPlayerHistory(database))
 	mux.HandleFunc("GET /league_bans", handleGetLeagueBans(database))
 
 	return mux, nil
@@ -90,9 +116,11 @@ func renderResponse(writer http.ResponseWriter, request *http.Request, status in
 		data = []string{}
 	}
 
-	// steamids and logs never change, use very long cache timeout
-	perm := strings.HasPrefix(request.URL.Path, "/steamid/") ||
-		(strings.HasPrefix(request.URL.Path, "/log/") && !strings.HasPrefix(request.URL.Path, "/log/player"))
+	// steamids and logs never change, use very long cache timeout. Other /log/ paths are aggregates that change
+	// as new logs are added.
+	_, errLogID := strconv.Atoi(strings.TrimPrefix(request.URL.Path, "/log/"))
+	perm := request.Method == http.MethodGet && (strings.HasPrefix(request.URL.Path, "/steamid/") ||
+		(strings.HasPrefix(request.URL.Path, "/log/") && errLogID == nil))
 
 	if strings.Contains(strings.ToLower(request.Header.Get("Accept")), "text/html") {
 		renderHTMLResponse(writer, request, status, data, perm, title)
@@ -229,6 +257,94 @@ func getSteamIDs(writer http.ResponseWriter, request *http.Request) (steamid.Col
 	return validIDs, true
 }
 
+// getBanCategories parses the optional comma separated categories query parameter used to filter ban results.
+func getBanCategories(writer http.ResponseWriter, request *http.Request) ([]domain.BanCategory, bool) {
+	categoryQuery := request.URL.Query().Get("categories")
+	if categoryQuery == "" {
+		return nil, true
+	}
+
+	var categories []domain.BanCategory
+
+	for _, value := range strings.Split(categoryQuery, ",") {
+		category := domain.BanCategory(strings.ToLower(strings.TrimSpace(value)))
+		if !slices.Contains(domain.BanCategories(), category) {
+			responseErr(writer, request, http.StatusBadRequest, errInvalidCategory,
+				fmt.Sprintf("Invalid category: %s", value))
+
+			return nil, false
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, true
+}
+
+// getLogsTFFormat parses the optional format query value, returning domain.FormatUnknown when it's not set.
+func getLogsTFFormat(writer http.ResponseWriter, request *http.Request) (domain.LogsTFFormat, bool) {
+	formatQuery := request.URL.Query().Get("format")
+	if formatQuery == "" {
+		return domain.FormatUnknown, true
+	}
+
+	format := domain.LogsTFFormat(strings.ToLower(strings.TrimSpace(formatQuery)))
+	if !slices.Contains(domain.LogsTFFormats(), format) {
+		responseErr(writer, request, http.StatusBadRequest, errInvalidFormat,
+			fmt.Sprintf("Invalid format: %s", formatQuery))
+
+		return domain.FormatUnknown, false
+	}
+
+	return format, true
+}
+
+// getLogsTFStatsFilter parses the optional format, map, since and until query values used to filter logs.tf stats.
+// Dates accept either a date (2006-01-02) or a full RFC3339 timestamp. A date for until includes the whole day.
+func getLogsTFStatsFilter(writer http.ResponseWriter, request *http.Request) (logsTFStatsFilter, bool) {
+	var filter logsTFStatsFilter
+
+	query := request.URL.Query()
+
+	format, formatOk := getLogsTFFormat(writer, request)
+	if !formatOk {
+		return filter, false
+	}
+
+	filter.Format = format
+	filter.Map = strings.ToLower(strings.TrimSpace(query.Get("map")))
+
+	for _, param := range []string{"since", "until"} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+
+		date, errDate := time.Parse(time.RFC3339, value)
+		if errDate != nil {
+			date, errDate = time.Parse(time.DateOnly, value)
+			if errDate == nil && param == "until" {
+				date = date.AddDate(0, 0, 1)
+			}
+		}
+
+		if errDate != nil {
+			responseErr(writer, request, http.StatusBadRequest, errInvalidDate,
+				fmt.Sprintf("Invalid %s date: %s", param, value))
+
+			return filter, false
+		}
+
+		if param == "since" {
+			filter.Since = date
+		} else {
+			filter.Until = date
+		}
+	}
+
+	return filter, true
+}
+
 func intParam(w http.ResponseWriter, r *http.Request, param string) (int, bool) {
 	intStr := r.PathValue(param)
 	if intStr == "" {
@@ -239,6 +355,8 @@ func intParam(w http.ResponseWriter, r *http.Request, param string) (int, bool)
 
 	intVal, err := strconv.Atoi(intStr)
 	if err != nil {
+		responseErr(w, r, http.StatusBadRequest, errInvalidQueryParams, "Invalid parameter")
+
 		return 0, false
 	}
 
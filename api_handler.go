@@ -1,12 +1,17 @@
 package main
 
 import (
+	"cmp"
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -144,7 +149,12 @@ func handleGetSourceBansMany(database *pgStore) http.HandlerFunc {
 			return
 		}
 
-		bans, errBans := database.sourcebansRecordBySID(request.Context(), ids)
+		categories, catOk := getBanCategories(writer, request)
+		if !catOk {
+			return
+		}
+
+		bans, errBans := database.sourcebansRecordBySID(request.Context(), ids, categories)
 		if errBans != nil {
 			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "")
 
@@ -162,7 +172,12 @@ func handleGetSourceBans(database *pgStore) http.HandlerFunc {
 			return
 		}
 
-		bans, errBans := database.sourcebansRecordBySID(request.Context(), steamid.Collection{sid})
+		categories, catOk := getBanCategories(writer, request)
+		if !catOk {
+			return
+		}
+
+		bans, errBans := database.sourcebansRecordBySID(request.Context(), steamid.Collection{sid}, categories)
 		if errBans != nil {
 			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "")
 
@@ -259,6 +274,441 @@ func handleGetLogsSummary(database *pgStore) http.HandlerFunc {
 	}
 }
 
+// handleGetLogsClassStats returns a users logs.tf stats for each class they have played.
+func handleGetLogsClassStats(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		steamID, found := steamIDFromSlug(writer, request)
+		if !found {
+			return
+		}
+
+		filter, filterOk := getLogsTFStatsFilter(writer, request)
+		if !filterOk {
+			return
+		}
+
+		stats, err := database.logsTFPlayerClassStats(request.Context(), steamID, filter)
+		if err != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Unhandled error")
+
+			return
+		}
+
+		if stats == nil {
+			stats = []domain.LogsTFPlayerClassStats{}
+		}
+
+		responseOk(writer, request, stats, fmt.Sprintf("Logs.tf Class Stats %s", steamID.String()))
+	}
+}
+
+// handleGetLogsLeaderboard returns the top players for a stat on a single class. The leaderboards are rebuilt
+// periodically, so they lag behind the newest logs.
+func handleGetLogsLeaderboard(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+
+		stat := logsTFLeaderboardStat(strings.ToLower(query.Get("stat")))
+		if _, validStat := stat.expr(); !validStat {
+			responseErr(writer, request, http.StatusBadRequest, errInvalidStat, "Invalid stat")
+
+			return
+		}
+
+		class, validClass := parsePlayerClass(query.Get("class"))
+		if !validClass || (stat.medicOnly() && class != domain.Medic) {
+			responseErr(writer, request, http.StatusBadRequest, errInvalidClass, "Invalid class")
+
+			return
+		}
+
+		format := leaderboardAllFormats
+		if query.Get("format") != leaderboardAllFormats {
+			parsedFormat, formatOk := getLogsTFFormat(writer, request)
+			if !formatOk {
+				return
+			}
+
+			if parsedFormat != domain.FormatUnknown {
+				format = string(parsedFormat)
+			}
+		}
+
+		minGames, limit := leaderboardMinGames, leaderboardMaxLimit
+
+		if minGamesQuery := query.Get("min_games"); minGamesQuery != "" {
+			value, errValue := strconv.Atoi(minGamesQuery)
+			if errValue != nil || value < 1 {
+				responseErr(writer, request, http.StatusBadRequest, errInvalidQueryParams, "Invalid min_games")
+
+				return
+			}
+
+			minGames = value
+		}
+
+		if limitQuery := query.Get("limit"); limitQuery != "" {
+			value, errValue := strconv.Atoi(limitQuery)
+			if errValue != nil || value < 1 || value > leaderboardMaxLimit {
+				responseErr(writer, request, http.StatusBadRequest, errInvalidQueryParams, "Invalid limit")
+
+				return
+			}
+
+			limit = value
+		}
+
+		entries, err := database.logsTFLeaderboard(request.Context(), stat, class, format, minGames, uint64(limit))
+		if err != nil && !errors.Is(err, errDatabaseNoResults) {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Unhandled error")
+
+			return
+		}
+
+		if entries == nil {
+			entries = []domain.LogsTFLeaderboardEntry{}
+		}
+
+		responseOk(writer, request, entries, fmt.Sprintf("Logs.tf Leaderboard %s", stat))
+	}
+}
+
+// handlePostLogUpload imports a tf2 server log, sent as the logfile field of a multipart form. An upload key, or
+// the logs_upload_key from the config, must be sent as a bearer token.
+func handlePostLogUpload(database *pgStore, config appConfig) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		uploadKey, errKey := checkUploadKey(request.Context(), database, config,
+			strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer "))
+		if errKey != nil {
+			if errors.Is(errKey, errUnauthorized) {
+				responseErr(writer, request, http.StatusUnauthorized, errUnauthorized, "")
+			} else {
+				responseErr(writer, request, http.StatusInternalServerError, errInternalError, "")
+			}
+
+			return
+		}
+
+		request.Body = http.MaxBytesReader(writer, request.Body, tf2LogMaxSize)
+
+		body, errBody := readLogFile(request)
+		if errBody != nil {
+			responseErr(writer, request, http.StatusBadRequest, errInvalidLogFile, "Missing logfile")
+
+			return
+		}
+
+		match, errImport := importTF2Log(request.Context(), database, body, request.FormValue("title"),
+			request.FormValue("map"))
+		if errImport != nil {
+			status, errResponse := importErrStatus(errImport)
+			responseErr(writer, request, status, errResponse, "")
+
+			return
+		}
+
+		logUploadKeyUsed(request.Context(), database, uploadKey)
+
+		responseOk(writer, request, match, fmt.Sprintf("Log %d", match.LogID))
+	}
+}
+
+// handlePostLogsTFUpload accepts the same multipart form as https://logs.tf/upload (title, map, key and logfile),
+// so servers running plugins such as logstf.smx can upload their logs here instead.
+func handlePostLogsTFUpload(database *pgStore, config appConfig) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		respond := func(status int, response logsTFUploadResponse) {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.Header().Set("Cache-Control", "no-store")
+			writer.WriteHeader(status)
+
+			if errEncode := encodeJSONIndent(writer, response); errEncode != nil {
+				slog.Error("Failed to write upload response", ErrAttr(errEncode))
+			}
+		}
+
+		request.Body = http.MaxBytesReader(writer, request.Body, tf2LogMaxSize)
+
+		body, errBody := readLogFile(request)
+		if errBody != nil {
+			respond(http.StatusBadRequest, logsTFUploadResponse{Error: "Missing logfile"}) //nolint:exhaustruct
+
+			return
+		}
+
+		uploadKey, errKey := checkUploadKey(request.Context(), database, config, request.FormValue("key"))
+		if errKey != nil {
+			if errors.Is(errKey, errUnauthorized) {
+				respond(http.StatusUnauthorized, logsTFUploadResponse{Error: "Invalid API key"}) //nolint:exhaustruct
+			} else {
+				slog.Error("Failed to check upload key", ErrAttr(errKey))
+				respond(http.StatusInternalServerError, logsTFUploadResponse{Error: errInternalError.Error()}) //nolint:exhaustruct
+			}
+
+			return
+		}
+
+		match, errImport := importTF2Log(request.Context(), database, body, request.FormValue("title"),
+			request.FormValue("map"))
+		if errImport != nil {
+			status, errResponse := importErrStatus(errImport)
+			respond(status, logsTFUploadResponse{Error: errResponse.Error()}) //nolint:exhaustruct
+
+			return
+		}
+
+		logUploadKeyUsed(request.Context(), database, uploadKey)
+
+		respond(http.StatusOK, logsTFUploadResponse{
+			Success: true,
+			Error:   "",
+			LogID:   match.LogID,
+			URL:     fmt.Sprintf("/log/%d", match.LogID),
+		})
+	}
+}
+
+// readLogFile reads the logfile field of the multipart form.
+func readLogFile(request *http.Request) ([]byte, error) {
+	file, _, errFile := request.FormFile("logfile")
+	if errFile != nil {
+		return nil, errors.Join(errFile, errInvalidLogFile)
+	}
+
+	defer logCloser(file)
+
+	body, errBody := io.ReadAll(file)
+	if errBody != nil {
+		return nil, errors.Join(errBody, errInvalidLogFile)
+	}
+
+	return body, nil
+}
+
+// importErrStatus maps the errors from importing a log to the response status and the error shown to the user.
+func importErrStatus(errImport error) (int, error) {
+	switch {
+	case errors.Is(errImport, errTF2LogDuplicate):
+		return http.StatusConflict, errTF2LogDuplicate
+	case errors.Is(errImport, errTF2LogNoMatch):
+		return http.StatusBadRequest, errTF2LogNoMatch
+	case errors.Is(errImport, errTF2LogRead):
+		return http.StatusBadRequest, errInvalidLogFile
+	default:
+		slog.Error("Failed to import log", ErrAttr(errImport))
+
+		return http.StatusInternalServerError, errInternalError
+	}
+}
+
+func logUploadKeyUsed(ctx context.Context, database *pgStore, uploadKey logsTFUploadKey) {
+	if uploadKey.UploadKeyID == 0 {
+		return
+	}
+
+	if errUsed := database.logsTFUploadKeyUsed(ctx, uploadKey.UploadKeyID); errUsed != nil {
+		slog.Error("Failed to update upload key", ErrAttr(errUsed))
+	}
+}
+
+// handlePostDemo inspects a demo, sent as the demo field of a multipart form, returning its header, the players
+// found in it and their profiles. The demo is parsed while it is being received and is never stored.
+func handlePostDemo(database *pgStore, cache cache) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		request.Body = http.MaxBytesReader(writer, request.Body, demoMaxSize)
+
+		info, errDemo := readDemo(request)
+		if errDemo != nil {
+			responseErr(writer, request, http.StatusBadRequest, errInvalidDemo, "")
+
+			return
+		}
+
+		inspection, errProfiles := demoProfiles(request.Context(), database, cache, info)
+		if errProfiles != nil {
+			slog.Error("Failed to load demo profiles", ErrAttr(errProfiles))
+			responseErr(writer, request, http.StatusInternalServerError, errLoadFailed, "")
+
+			return
+		}
+
+		responseOk(writer, request, inspection, fmt.Sprintf("Demo %s", info.Header.Map))
+	}
+}
+
+// readDemo parses the demo field of the multipart form without buffering the whole demo.
+func readDemo(request *http.Request) (*domain.DemoInfo, error) {
+	reader, errReader := request.MultipartReader()
+	if errReader != nil {
+		return nil, errors.Join(errReader, errInvalidDemo)
+	}
+
+	for {
+		part, errPart := reader.NextPart()
+		if errPart != nil {
+			return nil, errors.Join(errPart, errInvalidDemo)
+		}
+
+		if part.FormName() != "demo" {
+			continue
+		}
+
+		info, errParse := parseDemo(part)
+		logCloser(part)
+
+		return info, errParse
+	}
+}
+
+// handleGetLogsMaps returns the match results for each map, used when choosing map pools.
+func handleGetLogsMaps(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		filter, filterOk := getLogsTFStatsFilter(writer, request)
+		if !filterOk {
+			return
+		}
+
+		limit := logsTFMapsDefaultLimit
+
+		if limitQuery := request.URL.Query().Get("limit"); limitQuery != "" {
+			value, errValue := strconv.Atoi(limitQuery)
+			if errValue != nil || value < 1 || value > logsTFMapsMaxLimit {
+				responseErr(writer, request, http.StatusBadRequest, errInvalidQueryParams, "Invalid limit")
+
+				return
+			}
+
+			limit = value
+		}
+
+		maps, err := database.logsTFMapStats(request.Context(), filter, uint64(limit))
+		if err != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Unhandled error")
+
+			return
+		}
+
+		if maps == nil {
+			maps = []domain.LogsTFMapStats{}
+		}
+
+		responseOk(writer, request, maps, "Logs.tf Maps")
+	}
+}
+
+// handleGetLogsFormats returns how many matches are played in each format over time.
+func handleGetLogsFormats(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		filter, filterOk := getLogsTFStatsFilter(writer, request)
+		if !filterOk {
+			return
+		}
+
+		interval, validInterval := parseTrendInterval(request.URL.Query().Get("interval"))
+		if !validInterval {
+			responseErr(writer, request, http.StatusBadRequest, errInvalidInterval, "Invalid interval")
+
+			return
+		}
+
+		trends, err := database.logsTFFormatTrends(request.Context(), filter, interval)
+		if err != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Unhandled error")
+
+			return
+		}
+
+		if trends == nil {
+			trends = []domain.LogsTFFormatTrend{}
+		}
+
+		responseOk(writer, request, trends, "Logs.tf Formats")
+	}
+}
+
+// handleGetLogsShared returns the logs that a group of players all played in together.
+func handleGetLogsShared(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		steamIDs, ok := getSteamIDs(writer, request)
+		if !ok {
+			return
+		}
+
+		if len(steamIDs) < 2 {
+			responseErr(writer, request, http.StatusBadRequest, errInvalidQueryParams, "At least 2 steamids required")
+
+			return
+		}
+
+		if len(steamIDs) > logsTFSharedMaxPlayers {
+			responseErr(writer, request, http.StatusBadRequest, errTooMany,
+				fmt.Sprintf("Max %d steamids allowed", logsTFSharedMaxPlayers))
+
+			return
+		}
+
+		// getSteamIDs sorts the raw input, which only matches numeric order when every id is in the same format.
+		slices.SortFunc(steamIDs, func(a, b steamid.SteamID) int {
+			return cmp.Compare(a.Int64(), b.Int64())
+		})
+
+		shared, err := database.logsTFShared(request.Context(), steamIDs)
+		if err != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Unhandled error")
+
+			return
+		}
+
+		for _, record := range []*domain.LogsTFSharedRecord{&shared.SameTeam, &shared.OpposingTeams} {
+			if record.Logs == nil {
+				record.Logs = []domain.LogsTFMatchInfo{}
+			}
+
+			if record.Players == nil {
+				record.Players = []domain.LogsTFSharedPlayerStats{}
+			}
+		}
+
+		responseOk(writer, request, shared, "Logs.tf Shared Logs")
+	}
+}
+
+// handleGetLogsTeammates returns the players that most often played on the same team as the player.
+func handleGetLogsTeammates(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		steamID, found := steamIDFromSlug(writer, request)
+		if !found {
+			return
+		}
+
+		limit := logsTFTeammatesMaxLimit
+
+		if limitQuery := request.URL.Query().Get("limit"); limitQuery != "" {
+			value, errValue := strconv.Atoi(limitQuery)
+			if errValue != nil || value < 1 || value > logsTFTeammatesMaxLimit {
+				responseErr(writer, request, http.StatusBadRequest, errInvalidQueryParams, "Invalid limit")
+
+				return
+			}
+
+			limit = value
+		}
+
+		teammates, err := database.logsTFTeammates(request.Context(), steamID, limit)
+		if err != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Unhandled error")
+
+			return
+		}
+
+		if teammates == nil {
+			teammates = []domain.LogsTFTeammate{}
+		}
+
+		responseOk(writer, request, teammates, fmt.Sprintf("Logs.tf Teammates %s", steamID.String()))
+	}
+}
+
 // handleGetLogsList returns a list of a users logstf matches.
 func handleGetLogsList(database *pgStore) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
@@ -291,7 +741,12 @@ func handleGetLogsList(database *pgStore) http.HandlerFunc {
 // handleGetServemeList returns a list of all known serveme bans.
 func handleGetServemeList(database *pgStore) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
-		list, err := database.servemeRecords(request.Context())
+		categories, catOk := getBanCategories(writer, request)
+		if !catOk {
+			return
+		}
+
+		list, err := database.servemeRecords(request.Context(), categories)
 		if err != nil && !errors.Is(err, errDatabaseNoResults) {
 			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Unhandled error")
 
@@ -395,6 +850,181 @@ func handleGetStats(database *pgStore) func(http.ResponseWriter, *http.Request)
 	}
 }
 
+func handleGetRGLSeasons(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		seasons, err := database.rglSeasons(request.Context())
+		if err != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Failed to load seasons")
+
+			return
+		}
+
+		responseOk(writer, request, seasons, "RGL Seasons")
+	}
+}
+
+// handleGetRGLSeason returns a season along with the standings of its teams.
+func handleGetRGLSeason(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		seasonID, ok := intParam(writer, request, "season_id")
+		if !ok {
+			return
+		}
+
+		season, errSeason := database.rglSeasonGet(request.Context(), seasonID)
+		if errSeason != nil {
+			if errors.Is(errSeason, errDatabaseNoResults) {
+				responseErr(writer, request, http.StatusNotFound, errDatabaseNoResults, "Unknown season id")
+
+				return
+			}
+
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Unhandled error")
+
+			return
+		}
+
+		standings, errStandings := database.rglSeasonStandings(request.Context(), seasonID)
+		if errStandings != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Failed to load standings")
+
+			return
+		}
+
+		responseOk(writer, request, domain.RGLSeasonDetail{RGLSeason: season, Teams: standings},
+			fmt.Sprintf("RGL Season #%d - %s", season.SeasonID, season.Name))
+	}
+}
+
+// handleGetRGLTeam returns a team along with its roster and match results.
+func handleGetRGLTeam(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		teamID, ok := intParam(writer, request, "team_id")
+		if !ok {
+			return
+		}
+
+		team, errTeam := database.rglTeamGet(request.Context(), teamID)
+		if errTeam != nil {
+			if errors.Is(errTeam, errDatabaseNoResults) {
+				responseErr(writer, request, http.StatusNotFound, errDatabaseNoResults, "Unknown team id")
+
+				return
+			}
+
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Unhandled error")
+
+			return
+		}
+
+		roster, errRoster := database.rglTeamMembers(request.Context(), teamID)
+		if errRoster != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Failed to load roster")
+
+			return
+		}
+
+		matches, errMatches := database.rglTeamMatches(request.Context(), teamID)
+		if errMatches != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Failed to load matches")
+
+			return
+		}
+
+		responseOk(writer, request, domain.RGLTeamDetail{RGLTeam: team, Roster: roster, Matches: matches},
+			fmt.Sprintf("RGL Team #%d - %s", team.TeamID, team.TeamName))
+	}
+}
+
+// handleGetRGLMatch returns a match along with both of its teams.
+func handleGetRGLMatch(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		matchID, ok := intParam(writer, request, "match_id")
+		if !ok {
+			return
+		}
+
+		match, errMatch := database.rglMatchGet(request.Context(), matchID)
+		if errMatch != nil {
+			if errors.Is(errMatch, errDatabaseNoResults) {
+				responseErr(writer, request, http.StatusNotFound, errDatabaseNoResults, "Unknown match id")
+
+				return
+			}
+
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Unhandled error")
+
+			return
+		}
+
+		teamA, errTeamA := database.rglTeamGet(request.Context(), match.TeamIDA)
+		if errTeamA != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Failed to load team")
+
+			return
+		}
+
+		teamB, errTeamB := database.rglTeamGet(request.Context(), match.TeamIDB)
+		if errTeamB != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Failed to load team")
+
+			return
+		}
+
+		responseOk(writer, request, domain.RGLMatchDetail{RGLMatch: match, TeamA: teamA, TeamB: teamB},
+			fmt.Sprintf("RGL Match #%d - %s", match.MatchID, match.MatchName))
+	}
+}
+
+// handleGetRGLPlayerMatches returns every match played by the players teams, while they were on the roster.
+func handleGetRGLPlayerMatches(database *pgStore) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		steamID, found := steamIDFromSlug(writer, request)
+		if !found {
+			return
+		}
+
+		matches, err := database.rglPlayerMatches(request.Context(), steamID)
+		if err != nil {
+			responseErr(writer, request, http.StatusInternalServerError, errInternalError, "Failed to load matches")
+
+			return
+		}
+
+		responseOk(writer, request, matches, fmt.Sprintf("RGL Matches %s", steamID.String()))
+	}
+}
+
+func handleGetETF2LPlayerHistory(database *pgStore) func(http.ResponseWriter, *http.Request) {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		sids, sidOk := getSteamIDs(writer, request)
+		if !sidOk {
+			return
+		}
+
+		history, err := database.etf2lPlayerTeamHistory(request.Context(), sids)
+		if err != nil {
+			responseErr(writer, request, http.StatusInternalServerError, err, "Failed to generate histories")
+
+			return
+		}
+
+		histMap := map[string][]domain.ETF2LPlayerTeamHistory{}
+
+		for _, sid := range sids {
+			histMap[sid.String()] = []domain.ETF2LPlayerTeamHistory{}
+
+			for _, hist := range history {
+				if sid == hist.SteamID {
+					histMap[sid.String()] = append(histMap[sid.String()], hist)
+				}
+			}
+		}
+
+		responseOk(writer, request, histMap, "Player ETF2L Team Histories")
+	}
+}
+
 func handleGetRGLPlayerHistory(database *pgStore) func(http.ResponseWriter, *http.Request) {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		sids, sidOk := getSteamIDs(writer, request)
@@ -437,7 +1067,12 @@ func handleGetRGLList(database *pgStore, config appConfig) func(http.ResponseWri
 	extURL = strings.TrimSuffix(extURL, "/")
 
 	return func(writer http.ResponseWriter, request *http.Request) {
-		bans, errBans := database.rglBansGetAll(request.Context())
+		categories, catOk := getBanCategories(writer, request)
+		if !catOk {
+			return
+		}
+
+		bans, errBans := database.rglBansGetAll(request.Context(), categories)
 		if errBans != nil {
 			responseErr(writer, request, http.StatusInternalServerError, errBans, "Failed to get ban list")
 
@@ -486,7 +1121,12 @@ func handleGetETF2LList(database *pgStore, config appConfig) func(http.ResponseW
 	extURL = strings.TrimSuffix(extURL, "/")
 
 	return func(writer http.ResponseWriter, request *http.Request) {
-		bans, errBans := database.rglBansGetAll(request.Context())
+		categories, catOk := getBanCategories(writer, request)
+		if !catOk {
+			return
+		}
+
+		bans, errBans := database.rglBansGetAll(request.Context(), categories)
 		if errBans != nil {
 			responseErr(writer, request, http.StatusInternalServerError, errBans, "Failed to get ban list")
 
@@ -525,6 +1165,60 @@ func handleGetETF2LList(database *pgStore, config appConfig) func(http.ResponseW
 	}
 }
 
+func handleGetUGCList(database *pgStore, config appConfig) func(http.ResponseWriter, *http.Request) {
+	//goland:noinspection ALL
+	extURL := "http://" + config.ListenAddr + "/"
+	if config.ExternalURL != "" {
+		extURL = config.ExternalURL
+	}
+
+	extURL = strings.TrimSuffix(extURL, "/")
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		categories, catOk := getBanCategories(writer, request)
+		if !catOk {
+			return
+		}
+
+		bans, errBans := database.ugcBansQuery(request.Context(), nil, categories)
+		if errBans != nil && !errors.Is(errBans, errDatabaseNoResults) {
+			responseErr(writer, request, http.StatusInternalServerError, errBans, "Failed to get ban list")
+
+			return
+		}
+
+		list := domain.TF2BDSchema{
+			Schema: "https://raw.githubusercontent.com/leighmacdonald/bd-api/master/schemas/playerlist.schema.json",
+			FileInfo: domain.FileInfo{
+				Authors:     []string{"ugc league", "bd-api"},
+				Description: "All league bans and infractions",
+				Title:       "UGC Bans",
+				UpdateURL:   extURL + "/list/ugc",
+			},
+			Players: make([]domain.TF2BDPlayer, len(bans)),
+		}
+
+		for banIdx, ban := range bans {
+			player := domain.TF2BDPlayer{
+				Attributes: []string{"ugc"},
+				LastSeen: domain.LastSeen{
+					PlayerName: ban.Alias,
+					Time:       int(ban.CreatedAt.Unix()),
+				},
+				Steamid: ban.SteamID,
+				Proof:   []string{ban.Reason},
+			}
+			if !ban.ExpiresAt.Before(ugcPermanent()) {
+				player.Proof = append(player.Proof, "Permanent Ban")
+			}
+
+			list.Players[banIdx] = player
+		}
+
+		responseOk(writer, request, list, "UGC Ban List")
+	}
+}
+
 func handleGetServemeListBD(database *pgStore, config appConfig) func(http.ResponseWriter, *http.Request) {
 	//goland:noinspection ALL
 	extURL := "http://" + config.ListenAddr + "/"
@@ -535,7 +1229,12 @@ func handleGetServemeListBD(database *pgStore, config appConfig) func(http.Respo
 	extURL = strings.TrimSuffix(extURL, "/")
 
 	return func(writer http.ResponseWriter, request *http.Request) {
-		bans, errBans := database.servemeRecords(request.Context())
+		categories, catOk := getBanCategories(writer, request)
+		if !catOk {
+			return
+		}
+
+		bans, errBans := database.servemeRecords(request.Context(), categories)
 		if errBans != nil {
 			responseErr(writer, request, http.StatusInternalServerError, errBans, "Failed to get serveme ban list")
 
@@ -578,20 +1277,40 @@ func handleGetLeagueBans(database *pgStore) http.HandlerFunc {
 			return
 		}
 
-		etf2lBans, errETF2L := database.etf2lBansQuery(request.Context(), ids)
+		categories, catOk := getBanCategories(writer, request)
+		if !catOk {
+			return
+		}
+
+		etf2lBans, errETF2L := database.etf2lBansQuery(request.Context(), ids, categories)
 		if errETF2L != nil && !errors.Is(errETF2L, errDatabaseNoResults) {
 			responseErr(writer, request, http.StatusBadRequest, errLoadFailed, "could not load etf2l bans")
 
 			return
 		}
 
-		rglBans, errRGL := database.rglBansQuery(request.Context(), ids)
+		rglBans, errRGL := database.rglBansQuery(request.Context(), ids, categories)
 		if errRGL != nil && !errors.Is(errRGL, errDatabaseNoResults) {
 			responseErr(writer, request, http.StatusBadRequest, errLoadFailed, "could not load rgl bans")
 
 			return
 		}
 
-		responseOk(writer, request, assembleLeagueBans(ids, etf2lBans, rglBans), "League Bans")
+		ugcBans, errUGC := database.ugcBansQuery(request.Context(), ids, categories)
+		if errUGC != nil && !errors.Is(errUGC, errDatabaseNoResults) {
+			responseErr(writer, request, http.StatusBadRequest, errLoadFailed, "could not load ugc bans")
+
+			return
+		}
+
+		ozfortressBans, errOzfortress := database.ozfortressBansQuery(request.Context(), ids, categories)
+		if errOzfortress != nil && !errors.Is(errOzfortress, errDatabaseNoResults) {
+			responseErr(writer, request, http.StatusBadRequest, errLoadFailed, "could not load ozfortress bans")
+
+			return
+		}
+
+		responseOk(writer, request, assembleLeagueBans(ids, etf2lBans, rglBans, ugcBans, ozfortressBans),
+			"League Bans")
 	}
 }
@@ -139,6 +139,35 @@ func testReq(t *testing.T, router *http.ServeMux, method string, path string, ta
 	return nil
 }
 
+// newFixtureServer serves the fixture files keyed by request uri and a 404 for anything else. Requests missing
+// any of the required headers get a 401. The server is closed once the test finishes.
+func newFixtureServer(t *testing.T, fixtures map[string]string, required http.Header) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		for key := range required {
+			if request.Header.Get(key) != required.Get(key) {
+				http.Error(writer, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+				return
+			}
+		}
+
+		fixture, found := fixtures[request.URL.RequestURI()]
+		if !found {
+			http.NotFound(writer, request)
+
+			return
+		}
+
+		http.ServeFile(writer, request, fixture)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
 func apiTestBans(router *http.ServeMux) func(t *testing.T) {
 	return func(t *testing.T) {
 		t.Run("success", func(t *testing.T) {
@@ -192,6 +221,17 @@ func apiTestInvalidQueries(router *http.ServeMux) func(t *testing.T) {
 			require.NoError(t, testReq(t, router, http.MethodGet, path, &err))
 			require.Equal(t, err.Error, errInvalidSteamID.Error())
 		})
+		t.Run("invalidIntParam", func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				err  apiErr
+				path = "/rgl/seasons/abc"
+			)
+
+			require.NoError(t, testReq(t, router, http.MethodGet, path, &err))
+			require.Equal(t, "Invalid parameter", err.Error)
+		})
 		t.Run("tooManyRequested", func(t *testing.T) {
 			t.Parallel()
 
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+var (
+	errBrowserClosed    = errors.New("browser pool is closed")
+	errBrowserPage      = errors.New("failed to open browser page")
+	errBrowserNavigate  = errors.New("failed to navigate browser page")
+	errBrowserChallenge = errors.New("could not pass cloudflare challenge")
+	errBrowserNoPool    = errors.New("no browser pool attached to transport")
+)
+
+const defaultBrowserMaxPages = 2
+
+// browserPool manages the headless browsers used to scrape cloudflare protected sites. Each profile, typically
+// one per site, gets its own browser process and user data directory so that clearance cookies are kept
+// separately. The total number of open pages across all browsers is capped.
+type browserPool struct {
+	ctx        context.Context //nolint:containedctx
+	mu         sync.Mutex
+	profileDir string
+	headless   bool
+	pages      chan struct{}
+	browsers   map[string]*rod.Browser
+	launchers  map[string]*launcher.Launcher
+	closed     bool
+	// done is closed by Close, so the shutdown goroutine does not outlive the pool
+	done chan struct{}
+}
+
+// newBrowserPool creates a new pool. All browsers are shut down once the context is cancelled or Close is called.
+func newBrowserPool(ctx context.Context, profileDir string, maxPages int, headless bool) *browserPool {
+	if maxPages <= 0 {
+		maxPages = defaultBrowserMaxPages
+	}
+
+	pool := &browserPool{
+		ctx:        ctx,
+		profileDir: profileDir,
+		headless:   headless,
+		pages:      make(chan struct{}, maxPages),
+		browsers:   map[string]*rod.Browser{},
+		launchers:  map[string]*launcher.Launcher{},
+		done:       make(chan struct{}),
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			pool.Close()
+		case <-pool.done:
+		}
+	}()
+
+	return pool
+}
+
+// newConfigBrowserPool creates a browser pool using the app config. Browser profiles are stored under the cache dir.
+func newConfigBrowserPool(ctx context.Context, config appConfig) *browserPool {
+	return newBrowserPool(ctx, filepath.Join(config.CacheDir, "browser"), config.BrowserMaxPages,
+		!config.BrowserShowWindow)
+}
+
+// browser returns the browser for the profile, launching it if it's not already running.
+func (p *browserPool) browser(profile string) (*rod.Browser, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, errBrowserClosed
+	}
+
+	if browser, found := p.browsers[profile]; found {
+		return browser, nil
+	}
+
+	browserLauncher := launcher.New().
+		Context(p.ctx).
+		Leakless(true).
+		Headless(p.headless).
+		UserDataDir(filepath.Join(p.profileDir, profile)).
+		Set("disable-default-apps").
+		Set("no-first-run")
+
+	controlURL, errLaunch := browserLauncher.Launch()
+	if errLaunch != nil {
+		return nil, errors.Join(errLaunch, errScrapeLauncherInit)
+	}
+
+	browser := rod.New().Context(p.ctx).ControlURL(controlURL)
+	if errConnect := browser.Connect(); errConnect != nil {
+		browserLauncher.Kill()
+
+		return nil, errors.Join(errConnect, errScrapeLauncherInit)
+	}
+
+	p.browsers[profile] = browser.NoDefaultDevice()
+	p.launchers[profile] = browserLauncher
+
+	slog.Debug("Launched browser", slog.String("profile", profile), slog.Bool("headless", p.headless))
+
+	return p.browsers[profile], nil
+}
+
+// acquire blocks until a page slot is free. The returned func must be called to release it.
+func (p *browserPool) acquire(ctx context.Context) (func(), error) {
+	select {
+	case p.pages <- struct{}{}:
+		return func() { <-p.pages }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.ctx.Done():
+		return nil, errBrowserClosed
+	case <-p.done:
+		return nil, errBrowserClosed
+	}
+}
+
+// Close shuts down all the running browsers. The profile directories are kept so that clearance
+// cookies survive restarts.
+func (p *browserPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	p.closed = true
+	close(p.done)
+
+	for profile, browser := range p.browsers {
+		if errClose := browser.Close(); errClose != nil {
+			slog.Warn("Failed to close browser", slog.String("profile", profile), ErrAttr(errClose))
+		}
+
+		p.launchers[profile].Kill()
+	}
+
+	p.browsers = map[string]*rod.Browser{}
+	p.launchers = map[string]*launcher.Launcher{}
+}
+
+// cfTransport implements http.RoundTripper using a real browser from the pool so that cloudflare protected
+// sites can be integrated into the colly.Collector pipeline.
+type cfTransport struct {
+	pool           *browserPool
+	profile        string
+	waitStableTime time.Duration
+	maxAttempts    int
+	retryDelay     time.Duration
+}
+
+func newCFTransport(profile string) *cfTransport {
+	const (
+		stableWaitTimeout = time.Second * 5
+		challengeAttempts = 4
+		challengeDelay    = time.Second * 5
+	)
+
+	return &cfTransport{
+		pool:           nil,
+		profile:        profile,
+		waitStableTime: stableWaitTimeout,
+		maxAttempts:    challengeAttempts,
+		retryDelay:     challengeDelay,
+	}
+}
+
+// cfResponse is the final document response observed by the browser.
+type cfResponse struct {
+	mu         sync.Mutex
+	statusCode int
+	header     http.Header
+}
+
+func (r *cfResponse) update(event *proto.NetworkResponseReceived) {
+	if event.Type != proto.NetworkResourceTypeDocument || event.Response == nil {
+		return
+	}
+
+	header := http.Header{}
+
+	for key, value := range event.Response.Headers {
+		// Multiple values for the same header are joined by newlines.
+		for _, headerValue := range strings.Split(value.Str(), "\n") {
+			header.Add(key, headerValue)
+		}
+	}
+
+	// The body we return is already decoded.
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+
+	r.mu.Lock()
+	r.statusCode = event.Response.Status
+	r.header = header
+	r.mu.Unlock()
+}
+
+func (r *cfResponse) get() (int, http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.statusCode == 0 {
+		return http.StatusOK, http.Header{}
+	}
+
+	return r.statusCode, r.header.Clone()
+}
+
+func (t *cfTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.pool == nil {
+		return nil, errBrowserNoPool
+	}
+
+	release, errAcquire := t.pool.acquire(req.Context())
+	if errAcquire != nil {
+		return nil, errAcquire
+	}
+
+	defer release()
+
+	browser, errBrowser := t.pool.browser(t.profile)
+	if errBrowser != nil {
+		return nil, errBrowser
+	}
+
+	// Cancelling the page context also stops the event listener below.
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	page, errPage := browser.Context(ctx).Page(proto.TargetCreateTarget{}) //nolint:exhaustruct
+	if errPage != nil {
+		return nil, errors.Join(errPage, errBrowserPage)
+	}
+
+	defer func() {
+		if errClose := page.Close(); errClose != nil {
+			slog.Warn("Failed to close browser page", ErrAttr(errClose))
+		}
+	}()
+
+	response := &cfResponse{} //nolint:exhaustruct
+
+	waitEvents := page.EachEvent(response.update)
+	go waitEvents()
+
+	body, errFetch := t.fetch(ctx, page, req.URL.String(), response)
+	if errFetch != nil {
+		return nil, errFetch
+	}
+
+	statusCode, header := response.get()
+
+	return &http.Response{ //nolint:exhaustruct
+		Request:    req,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		StatusCode: statusCode,
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		Header:     header,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+	}, nil
+}
+
+// fetch navigates to the url and waits for any cloudflare challenge to be solved. The challenge
+// usually resolves on its own and redirects to the real page, so we just keep checking for a while.
+func (t *cfTransport) fetch(ctx context.Context, page *rod.Page, url string, response *cfResponse) (string, error) {
+	if errNavigate := page.Navigate(url); errNavigate != nil {
+		return "", errors.Join(errNavigate, errBrowserNavigate)
+	}
+
+	for attempt := 1; attempt <= t.maxAttempts; attempt++ {
+		if errWait := page.WaitStable(t.waitStableTime); errWait != nil {
+			return "", errors.Join(errWait, errScrapeWait)
+		}
+
+		body, errBody := page.HTML()
+		if errBody != nil {
+			return "", errors.Join(errBody, errResponseRead)
+		}
+
+		statusCode, header := response.get()
+		if !isCFChallenge(statusCode, header, body) {
+			return body, nil
+		}
+
+		slog.Debug("Waiting on cloudflare challenge", slog.String("url", url), slog.Int("attempt", attempt))
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(t.retryDelay):
+		}
+
+		// Give it a fresh load half way through in case the challenge got stuck.
+		if attempt == t.maxAttempts/2 {
+			if errReload := page.Reload(); errReload != nil {
+				return "", errors.Join(errReload, errBrowserNavigate)
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", errBrowserChallenge, url)
+}
+
+// isCFChallenge checks if the response is a cloudflare challenge page instead of the real content.
+func isCFChallenge(statusCode int, header http.Header, body string) bool {
+	if header.Get("Cf-Mitigated") == "challenge" || strings.Contains(body, "<title>Just a moment...</title>") {
+		return true
+	}
+
+	if statusCode != http.StatusForbidden && statusCode != http.StatusServiceUnavailable {
+		return false
+	}
+
+	for _, marker := range []string{"window._cf_chl_opt", "challenges.cloudflare.com", "cf-browser-verification"} {
+		if strings.Contains(body, marker) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsCFChallenge(t *testing.T) {
+	t.Parallel()
+
+	mitigated := http.Header{}
+	mitigated.Set("Cf-Mitigated", "challenge")
+
+	for _, testCase := range []struct {
+		name       string
+		statusCode int
+		header     http.Header
+		body       string
+		expected   bool
+	}{
+		{"ok", http.StatusOK, http.Header{}, "<html><title>Bans</title></html>", false},
+		{"mitigated header", http.StatusForbidden, mitigated, "", true},
+		{"interstitial title", http.StatusOK, http.Header{}, "<title>Just a moment...</title>", true},
+		{"challenge script", http.StatusForbidden, http.Header{}, "<script>window._cf_chl_opt={}</script>", true},
+		{"turnstile", http.StatusServiceUnavailable, http.Header{}, "https://challenges.cloudflare.com/x", true},
+		{"marker on real page", http.StatusOK, http.Header{}, "see challenges.cloudflare.com", false},
+		{"plain forbidden", http.StatusForbidden, http.Header{}, "<html>Forbidden</html>", false},
+	} {
+		require.Equal(t, testCase.expected, isCFChallenge(testCase.statusCode, testCase.header, testCase.body),
+			testCase.name)
+	}
+}
+
+func TestCFResponse(t *testing.T) {
+	t.Parallel()
+
+	response := &cfResponse{} //nolint:exhaustruct
+
+	status, header := response.get()
+	require.Equal(t, http.StatusOK, status)
+	require.Empty(t, header)
+
+	var script proto.NetworkResponseReceived
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "Script", "response": {"status": 500, "headers": {}}}`), &script))
+	response.update(&script)
+
+	status, _ = response.get()
+	require.Equal(t, http.StatusOK, status)
+
+	var document proto.NetworkResponseReceived
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "Document", "response": {"status": 403, "headers": {
+		"Cf-Mitigated": "challenge",
+		"Set-Cookie": "a=1\nb=2",
+		"Content-Encoding": "br",
+		"Content-Length": "1234"
+	}}}`), &document))
+	response.update(&document)
+
+	status, header = response.get()
+	require.Equal(t, http.StatusForbidden, status)
+	require.Equal(t, "challenge", header.Get("Cf-Mitigated"))
+	require.Equal(t, []string{"a=1", "b=2"}, header.Values("Set-Cookie"))
+	require.Equal(t, "", header.Get("Content-Encoding"))
+	require.Equal(t, "", header.Get("Content-Length"))
+}
+
+func TestBrowserPoolClose(t *testing.T) {
+	t.Parallel()
+
+	pool := newBrowserPool(context.Background(), t.TempDir(), 1, true)
+
+	// Hold the only page slot, so acquire below can only return once the pool is closed.
+	release, errHold := pool.acquire(context.Background())
+	require.NoError(t, errHold)
+
+	defer release()
+
+	pool.Close()
+	pool.Close()
+
+	done := false
+
+	select {
+	case <-pool.done:
+		done = true
+	default:
+	}
+
+	require.True(t, done, "Pool not marked as done")
+
+	_, errBrowser := pool.browser("test")
+	require.ErrorIs(t, errBrowser, errBrowserClosed)
+
+	_, errAcquire := pool.acquire(context.Background())
+	require.ErrorIs(t, errAcquire, errBrowserClosed)
+}
@@ -1,15 +1,22 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/leighmacdonald/bd-api/domain"
 	"github.com/spf13/cobra"
 )
@@ -146,6 +153,692 @@ func bdListCmd() *cobra.Command {
 	return bdCmd
 }
 
+func sourcebansCmd() *cobra.Command {
+	sbCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:     "sourcebans",
+		Aliases: []string{"sb"},
+		Short:   "Sourcebans scraper commands",
+	}
+
+	var (
+		probeFile string
+		samples   = 5
+	)
+
+	probeCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "probe <url>",
+		Short: "Detect the theme of a sourcebans ban list and print a site definition",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			doc, errDoc := loadProbeDocument(cmd.Context(), args[0], probeFile)
+			if errDoc != nil {
+				slog.Error("Failed to load ban list", ErrAttr(errDoc))
+
+				return
+			}
+
+			result, errProbe := probeSourcebans(doc.Selection, args[0])
+			if errProbe != nil {
+				slog.Error("Failed to probe ban list", ErrAttr(errProbe))
+
+				return
+			}
+
+			if _, err := fmt.Fprintf(os.Stdout, "theme: %s records: %d skipped: %d time_errors: %d next: %s\n\n%s\n",
+				result.Theme, len(result.Records), result.Skipped, result.TimeErrors, result.NextPage,
+				result.siteDefinition()); err != nil {
+				slog.Error("Failed to write output", ErrAttr(err))
+
+				return
+			}
+
+			for idx, record := range result.Records {
+				if idx >= samples {
+					break
+				}
+
+				_, err := fmt.Fprintf(os.Stdout, "sid: %s name: %s created: %s length: %s perm: %t reason: %s\n",
+					record.SteamID.String(), record.Name, record.CreatedOn.Format(time.DateTime),
+					record.Length, record.Permanent, record.Reason)
+				if err != nil {
+					slog.Error("Failed to write output", ErrAttr(err))
+				}
+			}
+		},
+	}
+
+	probeCmd.Flags().StringVar(&probeFile, "file", "", "Read the ban list from a local html file instead of fetching the url")
+	probeCmd.Flags().IntVar(&samples, "samples", 5, "Number of parsed records to print")
+
+	sbCmd.AddCommand(probeCmd)
+	sbCmd.AddCommand(sourcebansScrapeCmd())
+	sbCmd.AddCommand(sourcebansFixtureCmd())
+	sbCmd.AddCommand(sourcebansSitesCmd())
+
+	return sbCmd
+}
+
+func sourcebansScrapeCmd() *cobra.Command {
+	var (
+		site     string
+		dryRun   bool
+		maxPages int
+		asJSON   bool
+	)
+
+	scrapeCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "scrape",
+		Short: "Run the scraper for a single sourcebans site",
+		Run: func(cmd *cobra.Command, _ []string) {
+			if site == "" {
+				slog.Error("Site cannot be empty")
+
+				return
+			}
+
+			var (
+				config   appConfig
+				database *pgStore
+			)
+
+			if dryRun {
+				if errConfig := readConfig(&config); errConfig != nil {
+					slog.Error("Failed to read config", ErrAttr(errConfig))
+
+					return
+				}
+			} else {
+				conf, _, store, errSetup := createAppDeps(cmd.Context())
+				if errSetup != nil {
+					slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+					return
+				}
+
+				config, database = conf, store
+			}
+
+			scraper, errScraper := findScraper(config.CacheDir, domain.Site(site))
+			if errScraper != nil {
+				slog.Error("Failed to create scraper", ErrAttr(errScraper))
+
+				return
+			}
+
+			scraper.dryRun = dryRun
+			if maxPages > 0 {
+				scraper.maxPages = maxPages
+			}
+
+			browsers := newConfigBrowserPool(cmd.Context(), config)
+			defer browsers.Close()
+
+			scraper.setBrowserPool(browsers)
+
+			if !dryRun {
+				sbSite, errSite := scraper.attachSite(cmd.Context(), database)
+				if errSite != nil {
+					slog.Error("Failed to load site", ErrAttr(errSite))
+
+					return
+				}
+
+				if !sbSite.Enabled {
+					slog.Error("Site is disabled, enable it first with: sourcebans sites enable " + site)
+
+					return
+				}
+			}
+
+			if config.ProxiesEnabled {
+				if errProxies := attachCollectorProxies(scraper.Collector, &config); errProxies != nil {
+					slog.Error("Failed to attach proxies", ErrAttr(errProxies))
+
+					return
+				}
+			}
+
+			scraper.start(cmd.Context(), database)
+
+			if errOutput := writeScrapeResults(os.Stdout, scraper, asJSON); errOutput != nil {
+				slog.Error("Failed to write output", ErrAttr(errOutput))
+			}
+		},
+	}
+
+	scrapeCmd.Flags().StringVar(&site, "site", "", "Name of the site to scrape, eg: skial")
+	scrapeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Parse the results without writing them to the database")
+	scrapeCmd.Flags().IntVar(&maxPages, "pages", 0, "Maximum number of pages to fetch. 0 uses the site policy")
+	scrapeCmd.Flags().BoolVar(&asJSON, "json", false, "Output the results as json")
+
+	return scrapeCmd
+}
+
+func sourcebansFixtureCmd() *cobra.Command {
+	var (
+		site   string
+		outDir string
+	)
+
+	fixtureCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "fixture",
+		Short: "Refresh the test fixture of a sourcebans site from the live site",
+		Run: func(cmd *cobra.Command, _ []string) {
+			if site == "" {
+				slog.Error("Site cannot be empty")
+
+				return
+			}
+
+			scraper, errScraper := findScraper(os.TempDir(), domain.Site(site))
+			if errScraper != nil {
+				slog.Error("Failed to create scraper", ErrAttr(errScraper))
+
+				return
+			}
+
+			browsers := newBrowserPool(cmd.Context(), filepath.Join(os.TempDir(), "bdapi-browser"), 1, true)
+			defer browsers.Close()
+
+			scraper.setBrowserPool(browsers)
+
+			outPath, errFixture := scraper.fetchFixture(outDir)
+			if errFixture != nil {
+				slog.Error("Failed to refresh fixture", ErrAttr(errFixture))
+
+				return
+			}
+
+			slog.Info("Updated fixture, regenerate the golden files with: go test -run TestSourcebansGolden -update",
+				slog.String("path", outPath))
+		},
+	}
+
+	fixtureCmd.Flags().StringVar(&site, "site", "", "Name of the site to refresh, eg: skial")
+	fixtureCmd.Flags().StringVar(&outDir, "out", "testdata", "Directory to write the fixture to")
+
+	return fixtureCmd
+}
+
+func sourcebansSitesCmd() *cobra.Command {
+	sitesCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "sites",
+		Short: "Manage the scraped sourcebans sites",
+	}
+
+	sitesCmd.AddCommand(&cobra.Command{ //nolint:exhaustruct
+		Use:     "list",
+		Aliases: []string{"l"},
+		Short:   "List the sites with their ban counts and last completed scrape",
+		Run: func(cmd *cobra.Command, _ []string) {
+			_, _, database, errSetup := createAppDeps(cmd.Context())
+			if errSetup != nil {
+				slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+				return
+			}
+
+			sites, errSites := database.sourcebansSiteSummaries(cmd.Context())
+			if errSites != nil {
+				slog.Error("Failed to load sites", ErrAttr(errSites))
+
+				return
+			}
+
+			for _, site := range sites {
+				lastScraped := "never"
+				if site.LastScrapedOn != nil {
+					lastScraped = site.LastScrapedOn.Format(time.DateTime)
+				}
+
+				_, err := fmt.Fprintf(os.Stdout, "id: %d name: %s enabled: %t bans: %d last_scraped: %s\n",
+					site.SiteID, site.Name, site.Enabled, site.BanCount, lastScraped)
+				if err != nil {
+					slog.Error("Failed to write output", ErrAttr(err))
+				}
+			}
+		},
+	})
+
+	sitesCmd.AddCommand(sourcebansSiteEnabledCmd("disable", "Stop scraping a site, keeping its existing bans", false))
+	sitesCmd.AddCommand(sourcebansSiteEnabledCmd("enable", "Resume scraping a disabled site", true))
+
+	var confirmed bool
+
+	purgeCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "purge <name>",
+		Short: "Delete all bans collected from a site and disable it",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			_, _, database, errSetup := createAppDeps(cmd.Context())
+			if errSetup != nil {
+				slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+				return
+			}
+
+			var site domain.SbSite
+			if errSite := database.sourcebansSiteGetByName(cmd.Context(), domain.Site(args[0]), &site); errSite != nil {
+				slog.Error("Failed to find site by name", slog.String("name", args[0]), ErrAttr(errSite))
+
+				return
+			}
+
+			if !confirmed && !confirmPurge(os.Stdin, os.Stdout, string(site.Name)) {
+				slog.Info("Purge cancelled")
+
+				return
+			}
+
+			deleted, errPurge := database.sourcebansSitePurge(cmd.Context(), site.SiteID)
+			if errPurge != nil {
+				slog.Error("Failed to purge site", ErrAttr(errPurge))
+
+				return
+			}
+
+			slog.Info("Purged site successfully", slog.String("name", string(site.Name)), slog.Int64("bans", deleted))
+		},
+	}
+
+	purgeCmd.Flags().BoolVar(&confirmed, "yes", false, "Skip the confirmation prompt")
+
+	sitesCmd.AddCommand(purgeCmd)
+
+	return sitesCmd
+}
+
+func sourcebansSiteEnabledCmd(use string, short string, enabled bool) *cobra.Command {
+	return &cobra.Command{ //nolint:exhaustruct
+		Use:   use + " <name>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			_, _, database, errSetup := createAppDeps(cmd.Context())
+			if errSetup != nil {
+				slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+				return
+			}
+
+			var site domain.SbSite
+			if errSite := database.sourcebansSiteGetByName(cmd.Context(), domain.Site(args[0]), &site); errSite != nil {
+				slog.Error("Failed to find site by name", slog.String("name", args[0]), ErrAttr(errSite))
+
+				return
+			}
+
+			site.Enabled = enabled
+
+			if errSave := database.sourcebansSiteSave(cmd.Context(), &site); errSave != nil {
+				slog.Error("Failed to update site", ErrAttr(errSave))
+
+				return
+			}
+
+			slog.Info("Updated site successfully", slog.String("name", string(site.Name)), slog.Bool("enabled", enabled))
+		},
+	}
+}
+
+func logstfCmd() *cobra.Command {
+	logsCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "logstf",
+		Short: "Logs.tf commands",
+	}
+
+	var (
+		fromID int
+		toID   int
+	)
+
+	backfillCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "backfill",
+		Short: "Queue a range of log ids to be fetched by the gap retry worker",
+		Run: func(cmd *cobra.Command, _ []string) {
+			if fromID <= 0 || toID < fromID {
+				slog.Error("Invalid log id range", slog.Int("from", fromID), slog.Int("to", toID))
+
+				return
+			}
+
+			_, _, database, errSetup := createAppDeps(cmd.Context())
+			if errSetup != nil {
+				slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+				return
+			}
+
+			gaps := newLogsTFBackfill(fromID, toID, time.Now())
+			if errBackfill := database.logsTFGapsBackfill(cmd.Context(), fromID, toID, gaps); errBackfill != nil {
+				slog.Error("Failed to queue backfill", ErrAttr(errBackfill))
+
+				return
+			}
+
+			slog.Info("Queued backfill successfully", slog.Int("from", fromID), slog.Int("to", toID),
+				slog.Int("gaps", len(gaps)))
+		},
+	}
+
+	backfillCmd.Flags().IntVar(&fromID, "from", 0, "First log id to fetch")
+	backfillCmd.Flags().IntVar(&toID, "to", 0, "Last log id to fetch, inclusive")
+
+	logsCmd.AddCommand(backfillCmd)
+
+	logsCmd.AddCommand(&cobra.Command{ //nolint:exhaustruct
+		Use:   "gaps",
+		Short: "List the log id ranges that could not be fetched",
+		Run: func(cmd *cobra.Command, _ []string) {
+			_, _, database, errSetup := createAppDeps(cmd.Context())
+			if errSetup != nil {
+				slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+				return
+			}
+
+			gaps, errGaps := database.logsTFGaps(cmd.Context())
+			if errGaps != nil {
+				slog.Error("Failed to load gaps", ErrAttr(errGaps))
+
+				return
+			}
+
+			for _, gap := range gaps {
+				_, err := fmt.Fprintf(os.Stdout, "ids: %d-%d status: %s reason: %s attempts: %d next_attempt: %s\n",
+					gap.StartID, gap.EndID, gap.Status, gap.Reason, gap.Attempts, gap.NextAttempt.Format(time.DateTime))
+				if err != nil {
+					slog.Error("Failed to write output", ErrAttr(err))
+				}
+			}
+		},
+	})
+
+	return logsCmd
+}
+
+func logsCmd() *cobra.Command {
+	logsCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "logs",
+		Short: "TF2 server log commands",
+	}
+
+	var (
+		title   string
+		mapName string
+	)
+
+	importCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "import <file>",
+		Short: "Import a tf2 server log as a local match",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			body, errRead := os.ReadFile(args[0])
+			if errRead != nil {
+				slog.Error("Failed to read log file", ErrAttr(errRead))
+
+				return
+			}
+
+			_, _, database, errSetup := createAppDeps(cmd.Context())
+			if errSetup != nil {
+				slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+				return
+			}
+
+			if title == "" {
+				title = filepath.Base(args[0])
+			}
+
+			match, errImport := importTF2Log(cmd.Context(), database, body, title, mapName)
+			if errImport != nil {
+				slog.Error("Failed to import log", ErrAttr(errImport))
+
+				return
+			}
+
+			slog.Info("Imported log successfully", slog.Int("log_id", match.LogID), slog.String("map", match.Map),
+				slog.Int("players", len(match.Players)), slog.Int("rounds", len(match.Rounds)))
+		},
+	}
+
+	importCmd.Flags().StringVar(&title, "title", "", "Match title, defaults to the file name")
+	importCmd.Flags().StringVar(&mapName, "map", "", "Map name, used when the log does not include the map")
+
+	logsCmd.AddCommand(importCmd)
+	logsCmd.AddCommand(logsKeysCmd())
+
+	return logsCmd
+}
+
+func logsKeysCmd() *cobra.Command {
+	keysCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "keys",
+		Short: "Manage the keys used to upload logs",
+	}
+
+	keysCmd.AddCommand(&cobra.Command{ //nolint:exhaustruct
+		Use:   "add <name>",
+		Short: "Create a new upload key, the key is only shown once",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			_, _, database, errSetup := createAppDeps(cmd.Context())
+			if errSetup != nil {
+				slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+				return
+			}
+
+			key, keyHash, errKey := newUploadKey()
+			if errKey != nil {
+				slog.Error("Failed to generate key", ErrAttr(errKey))
+
+				return
+			}
+
+			if _, errCreate := database.logsTFUploadKeyCreate(cmd.Context(), args[0], keyHash); errCreate != nil {
+				slog.Error("Failed to create upload key", ErrAttr(errCreate))
+
+				return
+			}
+
+			if _, err := fmt.Fprintf(os.Stdout, "name: %s key: %s\n", args[0], key); err != nil {
+				slog.Error("Failed to write output", ErrAttr(err))
+			}
+		},
+	})
+
+	keysCmd.AddCommand(&cobra.Command{ //nolint:exhaustruct
+		Use:   "list",
+		Short: "List the upload keys",
+		Run: func(cmd *cobra.Command, _ []string) {
+			_, _, database, errSetup := createAppDeps(cmd.Context())
+			if errSetup != nil {
+				slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+				return
+			}
+
+			keys, errKeys := database.logsTFUploadKeys(cmd.Context())
+			if errKeys != nil {
+				slog.Error("Failed to load upload keys", ErrAttr(errKeys))
+
+				return
+			}
+
+			for _, uploadKey := range keys {
+				lastUsed := "never"
+				if uploadKey.LastUsedOn != nil {
+					lastUsed = uploadKey.LastUsedOn.Format(time.DateTime)
+				}
+
+				if _, err := fmt.Fprintf(os.Stdout, "name: %s enabled: %t uploads: %d last_used: %s created: %s\n",
+					uploadKey.Name, uploadKey.Enabled, uploadKey.Uploads, lastUsed,
+					uploadKey.CreatedOn.Format(time.DateTime)); err != nil {
+					slog.Error("Failed to write output", ErrAttr(err))
+				}
+			}
+		},
+	})
+
+	keysCmd.AddCommand(logsKeyEnabledCmd("enable", "Allow uploads with the key again", true))
+	keysCmd.AddCommand(logsKeyEnabledCmd("disable", "Reject uploads with the key", false))
+
+	return keysCmd
+}
+
+func logsKeyEnabledCmd(use string, short string, enabled bool) *cobra.Command {
+	return &cobra.Command{ //nolint:exhaustruct
+		Use:   use + " <name>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			_, _, database, errSetup := createAppDeps(cmd.Context())
+			if errSetup != nil {
+				slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+				return
+			}
+
+			if errSave := database.logsTFUploadKeySetEnabled(cmd.Context(), args[0], enabled); errSave != nil {
+				slog.Error("Failed to update upload key", slog.String("name", args[0]), ErrAttr(errSave))
+
+				return
+			}
+
+			slog.Info("Updated upload key successfully", slog.String("name", args[0]), slog.Bool("enabled", enabled))
+		},
+	}
+}
+
+func demoCmd() *cobra.Command {
+	demoCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "demo",
+		Short: "Source demo commands",
+	}
+
+	var profiles bool
+
+	inspectCmd := &cobra.Command{ //nolint:exhaustruct
+		Use:   "inspect <file>",
+		Short: "Show the header and players of a demo as json",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			file, errOpen := os.Open(args[0])
+			if errOpen != nil {
+				slog.Error("Failed to open demo", ErrAttr(errOpen))
+
+				return
+			}
+
+			defer logCloser(file)
+
+			info, errDemo := parseDemo(file)
+			if errDemo != nil {
+				slog.Error("Failed to parse demo", ErrAttr(errDemo))
+
+				return
+			}
+
+			var output any = info
+
+			if profiles {
+				_, cache, database, errSetup := createAppDeps(cmd.Context())
+				if errSetup != nil {
+					slog.Error("failed to setup app dependencies", ErrAttr(errSetup))
+
+					return
+				}
+
+				inspection, errProfiles := demoProfiles(cmd.Context(), database, cache, info)
+				if errProfiles != nil {
+					slog.Error("Failed to load profiles", ErrAttr(errProfiles))
+
+					return
+				}
+
+				output = inspection
+			}
+
+			if err := encodeJSONIndent(os.Stdout, output); err != nil {
+				slog.Error("Failed to write output", ErrAttr(err))
+			}
+		},
+	}
+
+	inspectCmd.Flags().BoolVar(&profiles, "profiles", false, "Include the profiles of the players, requires the database")
+
+	demoCmd.AddCommand(inspectCmd)
+
+	return demoCmd
+}
+
+// confirmPurge asks the user to type the site name before purging it.
+func confirmPurge(reader io.Reader, writer io.Writer, name string) bool {
+	if _, err := fmt.Fprintf(writer, "This deletes all bans collected from %s. Type the site name to confirm: ", name); err != nil {
+		return false
+	}
+
+	answer, errRead := bufio.NewReader(reader).ReadString('\n')
+	if errRead != nil && !errors.Is(errRead, io.EOF) {
+		return false
+	}
+
+	return strings.TrimSpace(answer) == name
+}
+
+func writeScrapeResults(writer io.Writer, scraper *sbScraper, asJSON bool) error {
+	results := make([]sbRecord, len(scraper.results))
+	for idx, record := range scraper.results {
+		record.DemoURL = scraper.demoURL(record.DemoURL)
+		results[idx] = record
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+
+		if errEncode := encoder.Encode(results); errEncode != nil {
+			return errors.Join(errEncode, errResponseJSON)
+		}
+
+		return nil
+	}
+
+	for _, record := range results {
+		if _, err := fmt.Fprintf(writer, "sid: %s name: %s created: %s length: %s perm: %t admin: %s reason: %s\n",
+			record.SteamID.String(), record.Name, record.CreatedOn.Format(time.DateTime), record.Length,
+			record.Permanent, record.Admin, record.Reason); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadProbeDocument fetches the page, or reads it from disk when a file is given. The url is still
+// required in that case to derive the base url.
+func loadProbeDocument(ctx context.Context, pageURL string, file string) (*goquery.Document, error) {
+	if file == "" {
+		return fetchProbeDocument(ctx, pageURL)
+	}
+
+	body, errOpen := os.Open(file)
+	if errOpen != nil {
+		return nil, errors.Join(errOpen, errProbeDocument)
+	}
+
+	defer logCloser(body)
+
+	doc, errDoc := goquery.NewDocumentFromReader(body)
+	if errDoc != nil {
+		return nil, errors.Join(errDoc, errProbeDocument)
+	}
+
+	return doc, nil
+}
+
 func runCmd() *cobra.Command {
 	return &cobra.Command{ //nolint:exhaustruct
 		Use: "run",
@@ -163,6 +856,10 @@ func execute() {
 
 	root.AddCommand(runCmd())
 	root.AddCommand(bdListCmd())
+	root.AddCommand(sourcebansCmd())
+	root.AddCommand(logstfCmd())
+	root.AddCommand(logsCmd())
+	root.AddCommand(demoCmd())
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	if err := root.ExecuteContext(ctx); err != nil {
@@ -31,6 +31,9 @@ func loadProfiles(ctx context.Context, database *pgStore, cache cache, steamIDs
 		servemeBans []*domain.ServeMeRecord
 		sourceBans  BanRecordMap
 		rglHist     []domain.RGLPlayerTeamHistory
+		etf2lHist   []domain.ETF2LPlayerTeamHistory
+		ugcHist     []domain.UGCPlayerTeamHistory
+		ozfHist     []domain.OzfortressHistory
 		leagueBans  domain.LeagueBanMap
 	)
 
@@ -59,7 +62,7 @@ func loadProfiles(ctx context.Context, database *pgStore, cache cache, steamIDs
 	go func() {
 		defer waitGroup.Done()
 
-		sbRecords, errSB := database.sourcebansRecordBySID(localCtx, steamIDs)
+		sbRecords, errSB := database.sourcebansRecordBySID(localCtx, steamIDs, nil)
 		if errSB != nil {
 			slog.Error("Failed to load sourcebans records", ErrAttr(errSB))
 		}
@@ -98,21 +101,35 @@ func loadProfiles(ctx context.Context, database *pgStore, cache cache, steamIDs
 	go func() {
 		defer waitGroup.Done()
 
-		etf2lBans, errETF2L := database.etf2lBansQuery(ctx, steamIDs)
+		etf2lBans, errETF2L := database.etf2lBansQuery(ctx, steamIDs, nil)
 		if errETF2L != nil && !errors.Is(errETF2L, errDatabaseNoResults) {
 			slog.Error("Could not load etf2l bans", ErrAttr(errETF2L))
 
 			return
 		}
 
-		rglBans, errRGL := database.rglBansQuery(ctx, steamIDs)
+		rglBans, errRGL := database.rglBansQuery(ctx, steamIDs, nil)
 		if errRGL != nil && !errors.Is(errRGL, errDatabaseNoResults) {
 			slog.Error("Could not load rgl bans", ErrAttr(errETF2L))
 
 			return
 		}
 
-		leagueBans = assembleLeagueBans(steamIDs, etf2lBans, rglBans)
+		ugcBans, errUGC := database.ugcBansQuery(ctx, steamIDs, nil)
+		if errUGC != nil && !errors.Is(errUGC, errDatabaseNoResults) {
+			slog.Error("Could not load ugc bans", ErrAttr(errUGC))
+
+			return
+		}
+
+		ozfortressBans, errOzfortress := database.ozfortressBansQuery(ctx, steamIDs, nil)
+		if errOzfortress != nil && !errors.Is(errOzfortress, errDatabaseNoResults) {
+			slog.Error("Could not load ozfortress bans", ErrAttr(errOzfortress))
+
+			return
+		}
+
+		leagueBans = assembleLeagueBans(steamIDs, etf2lBans, rglBans, ugcBans, ozfortressBans)
 	}()
 
 	waitGroup.Add(1)
@@ -155,6 +172,51 @@ func loadProfiles(ctx context.Context, database *pgStore, cache cache, steamIDs
 
 	waitGroup.Add(1)
 
+	go func() {
+		defer waitGroup.Done()
+
+		etf2lTeamHist, errs := database.etf2lPlayerTeamHistory(localCtx, steamIDs)
+		if errs != nil && !errors.Is(errs, errDatabaseNoResults) {
+			slog.Error("Failed to get etf2l history records", ErrAttr(errs))
+
+			return
+		}
+
+		etf2lHist = etf2lTeamHist
+	}()
+
+	waitGroup.Add(1)
+
+	go func() {
+		defer waitGroup.Done()
+
+		ugcTeamHist, errs := database.ugcPlayerTeamHistory(localCtx, steamIDs)
+		if errs != nil && !errors.Is(errs, errDatabaseNoResults) {
+			slog.Error("Failed to get ugc history records", ErrAttr(errs))
+
+			return
+		}
+
+		ugcHist = ugcTeamHist
+	}()
+
+	waitGroup.Add(1)
+
+	go func() {
+		defer waitGroup.Done()
+
+		ozfortressHist, errs := database.ozfortressPlayerHistory(localCtx, steamIDs)
+		if errs != nil && !errors.Is(errs, errDatabaseNoResults) {
+			slog.Error("Failed to get ozfortress history records", ErrAttr(errs))
+
+			return
+		}
+
+		ozfHist = ozfortressHist
+	}()
+
+	waitGroup.Add(1)
+
 	go func() {
 		defer waitGroup.Done()
 
@@ -181,6 +243,9 @@ func loadProfiles(ctx context.Context, database *pgStore, cache cache, steamIDs
 			LogsCount:   0,
 			BotDetector: make([]domain.BDSearchResult, 0),
 			RGL:         make([]domain.RGLPlayerTeamHistory, 0),
+			ETF2L:       make([]domain.ETF2LPlayerTeamHistory, 0),
+			UGC:         make([]domain.UGCPlayerTeamHistory, 0),
+			Ozfortress:  make([]domain.OzfortressHistory, 0),
 			Friends:     make([]steamweb.Friend, 0),
 		}
 
@@ -228,6 +293,24 @@ func loadProfiles(ctx context.Context, database *pgStore, cache cache, steamIDs
 			}
 		}
 
+		for _, hist := range etf2lHist {
+			if hist.SteamID == sid {
+				profile.ETF2L = append(profile.ETF2L, hist)
+			}
+		}
+
+		for _, hist := range ugcHist {
+			if hist.SteamID == sid {
+				profile.UGC = append(profile.UGC, hist)
+			}
+		}
+
+		for _, hist := range ozfHist {
+			if hist.SteamID == sid {
+				profile.Ozfortress = append(profile.Ozfortress, hist)
+			}
+		}
+
 		if lBans, found := leagueBans[sid]; found {
 			profile.LeagueBans = lBans
 		}
@@ -295,21 +378,33 @@ func loadStats(ctx context.Context, database *pgStore) (siteStats, error) {
 	return stats, nil
 }
 
-func assembleLeagueBans(steamIDs steamid.Collection, etf2lBans []domain.ETF2LBan, rglBans []domain.RGLBan) domain.LeagueBanMap {
+func assembleLeagueBans(steamIDs steamid.Collection, etf2lBans []domain.ETF2LBan, rglBans []domain.RGLBan,
+	ugcBans []domain.UGCBan, ozfortressBans []domain.OzfortressBan,
+) domain.LeagueBanMap {
 	resMap := domain.LeagueBanMap{}
 	for _, sid := range steamIDs {
 		resMap[sid] = map[domain.League][]any{
-			"rgl":   make([]any, 0),
-			"etf2l": make([]any, 0),
+			domain.RGL:        make([]any, 0),
+			domain.ETF2L:      make([]any, 0),
+			domain.UGCLeague:  make([]any, 0),
+			domain.Ozfortress: make([]any, 0),
 		}
 	}
 
 	for _, ban := range etf2lBans {
-		resMap[ban.SteamID]["etf2l"] = append(resMap[ban.SteamID]["etf2l"], ban)
+		resMap[ban.SteamID][domain.ETF2L] = append(resMap[ban.SteamID][domain.ETF2L], ban)
 	}
 
 	for _, ban := range rglBans {
-		resMap[ban.SteamID]["rgl"] = append(resMap[ban.SteamID]["rgl"], ban)
+		resMap[ban.SteamID][domain.RGL] = append(resMap[ban.SteamID][domain.RGL], ban)
+	}
+
+	for _, ban := range ugcBans {
+		resMap[ban.SteamID][domain.UGCLeague] = append(resMap[ban.SteamID][domain.UGCLeague], ban)
+	}
+
+	for _, ban := range ozfortressBans {
+		resMap[ban.SteamID][domain.Ozfortress] = append(resMap[ban.SteamID][domain.Ozfortress], ban)
 	}
 
 	return resMap
@@ -18,6 +18,7 @@
 	errConfigDecode          = errors.New("invalid config file format")
 	errConfigSteamKey        = errors.New("failed to set steamid key")
 	errConfigSteamKeyInvalid = errors.New("invalid steam api key [empty]")
+	errConfigOzfortressKey   = errors.New("ozfortress scraper enabled without an ozfortress api key")
 )
 
 type proxyContext struct {
@@ -39,9 +40,14 @@ type appConfig struct {
 	LogFileEnabled           bool            `mapstructure:"log_file_enabled"`
 	LogFilePath              string          `mapstructure:"log_file_path"`
 	LogstfScraperEnabled     bool            `mapstructure:"logstf_scraper_enabled"`
+	LogsUploadKey            string          `mapstructure:"logs_upload_key"`
 	SourcebansScraperEnabled bool            `mapstructure:"sourcebans_scraper_enabled"`
+	SourcebansMaxSites       int             `mapstructure:"sourcebans_max_sites"`
 	RGLScraperEnabled        bool            `mapstructure:"rgl_scraper_enabled"`
 	ETF2LScraperEnabled      bool            `mapstructure:"etf2l_scraper_enabled"`
+	UGCScraperEnabled        bool            `mapstructure:"ugc_scraper_enabled"`
+	OzfortressScraperEnabled bool            `mapstructure:"ozfortress_scraper_enabled"`
+	OzfortressAPIKey         string          `mapstructure:"ozfortress_api_key"`
 	ProxiesEnabled           bool            `mapstructure:"proxies_enabled"`
 	Proxies                  []*proxyContext `mapstructure:"proxies"`
 	ScrapeDelay              int             `mapstructure:"scrape_delay"`
@@ -49,6 +55,8 @@ type appConfig struct {
 	PrivateKeyPassword       string          `mapstructure:"private_key_password"`
 	EnableCache              bool            `mapstructure:"enable_cache"`
 	CacheDir                 string          `mapstructure:"cache_dir"`
+	BrowserShowWindow        bool            `mapstructure:"browser_show_window"`
+	BrowserMaxPages          int             `mapstructure:"browser_max_pages"`
 }
 
 func makeSigner(keyPath string, password string) (ssh.Signer, error) { //nolint:ireturn
@@ -111,6 +119,10 @@ func readConfig(config *appConfig) error {
 		return errConfigSteamKeyInvalid
 	}
 
+	if config.OzfortressScraperEnabled && config.OzfortressAPIKey == "" {
+		return errConfigOzfortressKey
+	}
+
 	if errSteam := steamid.SetKey(config.SteamAPIKey); errSteam != nil {
 		return fmt.Errorf("%w: %w", errConfigSteamKey, errSteam)
 	}
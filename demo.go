@@ -0,0 +1,632 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+)
+
+var (
+	errDemoRead    = errors.New("failed to read demo")
+	errDemoHeader  = errors.New("invalid demo header")
+	errDemoFrame   = errors.New("invalid demo frame")
+	errDemoMessage = errors.New("unknown demo net message")
+)
+
+const (
+	demoMagic = "HL2DEMO\x00"
+	// demoMaxSize is the largest demo accepted, full length matches on busy servers are usually under 200MB.
+	demoMaxSize = 512 << 20
+	// demoMaxFrameSize limits the size of a single frame so a corrupt length cannot allocate unbounded memory.
+	demoMaxFrameSize = 16 << 20
+	// demoPathSize is the fixed size of each of the string fields in the header.
+	demoPathSize = 260
+	// demoCmdInfoSize is the size of the split screen view info sent before each packet.
+	demoCmdInfoSize = 76
+	// demoMaxTables is the maximum number of string tables, table ids are sent with log2 of it bits.
+	demoMaxTables = 32
+	// demoUserInfoTable is the name of the string table holding a player_info_t for each player slot.
+	demoUserInfoTable = "userinfo"
+	// demoPlayerInfoSize is the size of player_info_t, names are truncated to 32 bytes.
+	demoPlayerInfoSize = 132
+)
+
+type demoCommand uint8
+
+const (
+	demSignon       demoCommand = 1
+	demPacket       demoCommand = 2
+	demSyncTick     demoCommand = 3
+	demConsoleCmd   demoCommand = 4
+	demUserCmd      demoCommand = 5
+	demDataTables   demoCommand = 6
+	demStop         demoCommand = 7
+	demStringTables demoCommand = 8
+)
+
+type demoStringTable struct {
+	name         string
+	maxEntries   int
+	fixedSize    bool
+	userDataBits int
+}
+
+type demoParser struct {
+	reader  *bufio.Reader
+	tables  []demoStringTable
+	players map[steamid.SteamID]domain.DemoPlayer
+}
+
+// parseDemo reads the header of a source engine demo and walks its frames, collecting every player that
+// appears in the userinfo string table. Only the network messages that need to be skipped over to reach the
+// string table updates are understood, a packet containing anything else is ignored from that point on.
+func parseDemo(reader io.Reader) (*domain.DemoInfo, error) {
+	parser := demoParser{
+		reader:  bufio.NewReader(reader),
+		tables:  nil,
+		players: map[steamid.SteamID]domain.DemoPlayer{},
+	}
+
+	header, errHeader := parser.header()
+	if errHeader != nil {
+		return nil, errHeader
+	}
+
+	if errFrames := parser.frames(); errFrames != nil {
+		return nil, errFrames
+	}
+
+	players := make([]domain.DemoPlayer, 0, len(parser.players))
+	for _, player := range parser.players {
+		players = append(players, player)
+	}
+
+	slices.SortFunc(players, func(a, b domain.DemoPlayer) int {
+		if a.UserID != b.UserID {
+			return a.UserID - b.UserID
+		}
+
+		return int(a.SteamID.Int64() - b.SteamID.Int64())
+	})
+
+	return &domain.DemoInfo{Header: header, Players: players}, nil
+}
+
+// demoProfiles loads the profiles of the players in a demo. Only the first maxResults players are looked up,
+// which is only ever reached by long running public server demos.
+func demoProfiles(ctx context.Context, database *pgStore, cache cache, info *domain.DemoInfo) (domain.DemoInspection, error) {
+	inspection := domain.DemoInspection{DemoInfo: *info, Profiles: []domain.Profile{}}
+
+	var steamIDs steamid.Collection
+	for _, player := range info.Players {
+		if len(steamIDs) == maxResults {
+			break
+		}
+
+		steamIDs = append(steamIDs, player.SteamID)
+	}
+
+	if len(steamIDs) == 0 {
+		return inspection, nil
+	}
+
+	profiles, errProfiles := loadProfiles(ctx, database, cache, steamIDs)
+	if errProfiles != nil {
+		return inspection, errProfiles
+	}
+
+	inspection.Profiles = profiles
+
+	return inspection, nil
+}
+
+func (p *demoParser) header() (domain.DemoHeader, error) {
+	var raw struct {
+		Magic           [8]byte
+		DemoProtocol    int32
+		NetworkProtocol int32
+		Server          [demoPathSize]byte
+		Client          [demoPathSize]byte
+		Map             [demoPathSize]byte
+		GameDir         [demoPathSize]byte
+		Duration        float32
+		Ticks           int32
+		Frames          int32
+		SignonLength    int32
+	}
+
+	if err := binary.Read(p.reader, binary.LittleEndian, &raw); err != nil {
+		return domain.DemoHeader{}, errors.Join(err, errDemoHeader)
+	}
+
+	if string(raw.Magic[:]) != demoMagic {
+		return domain.DemoHeader{}, errDemoHeader
+	}
+
+	duration := float64(raw.Duration)
+	if math.IsNaN(duration) || math.IsInf(duration, 0) || duration < 0 {
+		duration = 0
+	}
+
+	return domain.DemoHeader{
+		DemoProtocol:    int(raw.DemoProtocol),
+		NetworkProtocol: int(raw.NetworkProtocol),
+		Server:          cString(raw.Server[:]),
+		Client:          cString(raw.Client[:]),
+		Map:             cString(raw.Map[:]),
+		GameDir:         cString(raw.GameDir[:]),
+		Duration:        domain.JSONDuration{Duration: time.Duration(duration * float64(time.Second))},
+		Ticks:           int(raw.Ticks),
+		Frames:          int(raw.Frames),
+	}, nil
+}
+
+// frames reads every frame until the stop command. Demos from servers that crashed, or that are still being
+// recorded, end without one so running out of data is not an error.
+func (p *demoParser) frames() error {
+	for {
+		cmd, errCmd := p.reader.ReadByte()
+		if errCmd != nil {
+			if errors.Is(errCmd, io.EOF) {
+				return nil
+			}
+
+			return errors.Join(errCmd, errDemoRead)
+		}
+
+		var tick int32
+		if err := p.read(&tick); err != nil {
+			return err
+		}
+
+		switch demoCommand(cmd) {
+		case demStop:
+			return nil
+		case demSyncTick:
+			continue
+		case demSignon, demPacket:
+			if err := p.discard(demoCmdInfoSize + 8); err != nil {
+				return err
+			}
+
+			data, errData := p.frameData()
+			if errData != nil {
+				return errData
+			}
+
+			if errPacket := p.packet(data); errPacket != nil {
+				slog.Debug("Skipped remainder of demo packet", slog.Int("tick", int(tick)), ErrAttr(errPacket))
+			}
+		case demUserCmd:
+			if err := p.discard(4); err != nil {
+				return err
+			}
+
+			if _, err := p.frameData(); err != nil {
+				return err
+			}
+		case demConsoleCmd, demDataTables:
+			if _, err := p.frameData(); err != nil {
+				return err
+			}
+		case demStringTables:
+			data, errData := p.frameData()
+			if errData != nil {
+				return errData
+			}
+
+			if errTables := p.stringTables(newBitReader(data)); errTables != nil {
+				slog.Debug("Failed to read demo string tables", slog.Int("tick", int(tick)), ErrAttr(errTables))
+			}
+		default:
+			return fmt.Errorf("%w: command %d", errDemoFrame, cmd)
+		}
+	}
+}
+
+// read reads a fixed size value. Running out of data part way through a frame means the demo was truncated, which
+// is treated the same as reaching the end.
+func (p *demoParser) read(value any) error {
+	if err := binary.Read(p.reader, binary.LittleEndian, value); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		return errors.Join(err, errDemoRead)
+	}
+
+	return nil
+}
+
+func (p *demoParser) discard(count int) error {
+	if _, err := p.reader.Discard(count); err != nil && !errors.Is(err, io.EOF) {
+		return errors.Join(err, errDemoRead)
+	}
+
+	return nil
+}
+
+func (p *demoParser) frameData() ([]byte, error) {
+	var length int32
+	if err := binary.Read(p.reader, binary.LittleEndian, &length); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, nil
+		}
+
+		return nil, errors.Join(err, errDemoRead)
+	}
+
+	if length < 0 || length > demoMaxFrameSize {
+		return nil, fmt.Errorf("%w: length %d", errDemoFrame, length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(p.reader, data); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, nil
+		}
+
+		return nil, errors.Join(err, errDemoRead)
+	}
+
+	return data, nil
+}
+
+// stringTables reads the snapshot of all string tables that is written when recording starts part way into a map.
+func (p *demoParser) stringTables(reader *bitReader) error {
+	numTables := reader.int(8)
+
+	for range numTables {
+		name := reader.string()
+		numEntries := reader.int(16)
+
+		for range numEntries {
+			reader.string()
+
+			if reader.bool() {
+				userData := reader.bytes(reader.int(16))
+				if name == demoUserInfoTable {
+					p.addPlayer(userData)
+				}
+			}
+		}
+
+		if reader.bool() {
+			numClientEntries := reader.int(16)
+
+			for range numClientEntries {
+				reader.string()
+
+				if reader.bool() {
+					reader.skip(reader.int(16) * 8)
+				}
+			}
+		}
+
+		if reader.err != nil {
+			return reader.err
+		}
+	}
+
+	return reader.err
+}
+
+// packet reads the network messages sent in a packet, skipping everything except the string table messages.
+//
+//nolint:cyclop,funlen,gocyclo,maintidx
+func (p *demoParser) packet(data []byte) error {
+	const messageTypeBits = 6
+
+	reader := newBitReader(data)
+
+	for reader.remaining() >= messageTypeBits && reader.err == nil {
+		switch msgType := reader.int(messageTypeBits); msgType {
+		case 0: // net_NOP
+		case 1, 4, 7: // net_Disconnect, net_StringCmd, svc_Print
+			reader.string()
+		case 2: // net_File
+			reader.skip(32)
+			reader.string()
+			reader.skip(1)
+		case 3: // net_Tick
+			reader.skip(32 + 16 + 16)
+		case 5: // net_SetConVar
+			for range reader.int(8) {
+				reader.string()
+				reader.string()
+			}
+		case 6: // net_SignonState
+			reader.skip(8 + 32)
+		case 8: // svc_ServerInfo
+			protocol := reader.int(16)
+			reader.skip(32 + 1 + 1 + 32 + 16)
+
+			if protocol > 17 {
+				reader.skip(128)
+			} else {
+				reader.skip(32)
+			}
+
+			reader.skip(8 + 8 + 32 + 8)
+
+			for range 4 {
+				reader.string()
+			}
+
+			if protocol > 15 {
+				reader.skip(1)
+			}
+		case 9: // svc_SendTable
+			reader.skip(1)
+			reader.skip(reader.int(16))
+		case 10: // svc_ClassInfo
+			count := reader.int(16)
+			if !reader.bool() {
+				classBits := log2(count) + 1
+
+				for range count {
+					reader.skip(classBits)
+					reader.string()
+					reader.string()
+				}
+			}
+		case 11: // svc_SetPause
+			reader.skip(1)
+		case 12: // svc_CreateStringTable
+			p.createStringTable(reader)
+		case 13: // svc_UpdateStringTable
+			p.updateStringTable(reader)
+		case 14: // svc_VoiceInit
+			reader.string()
+
+			if reader.int(8) == 255 {
+				reader.skip(16)
+			}
+		case 15: // svc_VoiceData
+			reader.skip(8 + 8)
+			reader.skip(reader.int(16))
+		case 17: // svc_Sounds
+			if reader.bool() {
+				reader.skip(reader.int(8))
+			} else {
+				reader.skip(8)
+				reader.skip(reader.int(16))
+			}
+		case 18: // svc_SetView
+			reader.skip(11)
+		case 19: // svc_FixAngle
+			reader.skip(1 + 16*3)
+		case 20: // svc_CrosshairAngle
+			reader.skip(16 * 3)
+		case 21: // svc_BSPDecal
+			hasX, hasY, hasZ := reader.bool(), reader.bool(), reader.bool()
+			for _, has := range []bool{hasX, hasY, hasZ} {
+				if has {
+					reader.bitCoord()
+				}
+			}
+
+			reader.skip(9)
+
+			if reader.bool() {
+				reader.skip(11 + 12)
+			}
+
+			reader.skip(1)
+		case 23: // svc_UserMessage
+			reader.skip(8)
+			reader.skip(reader.int(11))
+		case 24: // svc_EntityMessage
+			reader.skip(11 + 9)
+			reader.skip(reader.int(11))
+		case 25: // svc_GameEvent
+			reader.skip(reader.int(11))
+		case 26: // svc_PacketEntities
+			reader.skip(11)
+
+			if reader.bool() {
+				reader.skip(32)
+			}
+
+			reader.skip(1 + 11)
+			length := reader.int(20)
+			reader.skip(1)
+			reader.skip(length)
+		case 27: // svc_TempEntities
+			reader.skip(8)
+			reader.skip(reader.varint32())
+		case 28: // svc_Prefetch
+			reader.skip(14)
+		case 29: // svc_Menu
+			reader.skip(16)
+			reader.skip(reader.int(16) * 8)
+		case 30: // svc_GameEventList
+			reader.skip(9)
+			reader.skip(reader.int(20))
+		case 31: // svc_GetCvarValue
+			reader.skip(32)
+			reader.string()
+		case 32: // svc_CmdKeyValues
+			reader.skip(reader.int(32) * 8)
+		default:
+			return fmt.Errorf("%w: %d", errDemoMessage, msgType)
+		}
+	}
+
+	return reader.err
+}
+
+func (p *demoParser) createStringTable(reader *bitReader) {
+	table := demoStringTable{name: reader.string(), maxEntries: reader.int(16), fixedSize: false, userDataBits: 0}
+	numEntries := reader.int(log2(table.maxEntries) + 1)
+	length := reader.varint32()
+
+	if reader.bool() {
+		table.fixedSize = true
+		reader.skip(12)
+		table.userDataBits = reader.int(4)
+	}
+
+	compressed := reader.bool()
+	data := reader.sub(length)
+
+	if reader.err != nil {
+		return
+	}
+
+	p.tables = append(p.tables, table)
+
+	if table.name != demoUserInfoTable {
+		return
+	}
+
+	if compressed {
+		data.skip(32) // decompressed size, repeated in the lzss header
+
+		decompressed, errDecompress := decompressLZSS(data.bytes(data.int(32)))
+		if errDecompress != nil {
+			slog.Debug("Failed to decompress demo string table", ErrAttr(errDecompress))
+
+			return
+		}
+
+		data = newBitReader(decompressed)
+	}
+
+	p.tableEntries(data, table, numEntries)
+}
+
+func (p *demoParser) updateStringTable(reader *bitReader) {
+	tableID := reader.int(log2(demoMaxTables))
+
+	numChanged := 1
+	if reader.bool() {
+		numChanged = reader.int(16)
+	}
+
+	data := reader.sub(reader.int(20))
+
+	if reader.err != nil || tableID >= len(p.tables) || p.tables[tableID].name != demoUserInfoTable {
+		return
+	}
+
+	p.tableEntries(data, p.tables[tableID], numChanged)
+}
+
+// tableEntries reads string table entries. Each entry either follows the previous index or sends its own, and
+// its string can reuse a prefix of one of the last 32 strings.
+func (p *demoParser) tableEntries(reader *bitReader, table demoStringTable, numEntries int) {
+	const (
+		historySize   = 32
+		substringBits = 5
+		userDataBits  = 14
+	)
+
+	var (
+		entryBits = log2(table.maxEntries)
+		lastEntry = -1
+		history   []string
+	)
+
+	for range numEntries {
+		entry := lastEntry + 1
+		if !reader.bool() {
+			entry = reader.int(entryBits)
+		}
+
+		lastEntry = entry
+
+		var value string
+
+		if reader.bool() {
+			if reader.bool() {
+				index := reader.int(substringBits)
+				count := reader.int(substringBits)
+				suffix := reader.string()
+
+				if index < len(history) && count <= len(history[index]) {
+					value = history[index][:count]
+				}
+
+				value += suffix
+			} else {
+				value = reader.string()
+			}
+		}
+
+		if reader.bool() {
+			if table.fixedSize {
+				reader.skip(table.userDataBits)
+			} else {
+				p.addPlayer(reader.bytes(reader.int(userDataBits)))
+			}
+		}
+
+		if reader.err != nil {
+			return
+		}
+
+		history = append(history, value)
+		if len(history) > historySize {
+			history = history[1:]
+		}
+	}
+}
+
+// addPlayer adds the player described by a player_info_t, ignoring bots and SourceTV.
+func (p *demoParser) addPlayer(data []byte) {
+	player, found := parseDemoPlayerInfo(data)
+	if !found {
+		return
+	}
+
+	p.players[player.SteamID] = player
+}
+
+func parseDemoPlayerInfo(data []byte) (domain.DemoPlayer, bool) {
+	if len(data) < demoPlayerInfoSize {
+		return domain.DemoPlayer{}, false
+	}
+
+	fakePlayer, isHLTV := data[108] != 0, data[109] != 0
+	if fakePlayer || isHLTV {
+		return domain.DemoPlayer{}, false
+	}
+
+	sid := steamid.New(cString(data[36:69]))
+	if !sid.Valid() {
+		if friendsID := binary.LittleEndian.Uint32(data[72:76]); friendsID != 0 {
+			sid = steamid.New(fmt.Sprintf("[U:1:%d]", friendsID))
+		}
+	}
+
+	if !sid.Valid() {
+		return domain.DemoPlayer{}, false
+	}
+
+	return domain.DemoPlayer{
+		SteamID: sid,
+		Name:    cString(data[0:32]),
+		UserID:  int(int32(binary.LittleEndian.Uint32(data[32:36]))),
+	}, true
+}
+
+// cString returns the string up to the first null byte.
+func cString(data []byte) string {
+	if idx := bytes.IndexByte(data, 0); idx >= 0 {
+		return string(data[:idx])
+	}
+
+	return string(data)
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	errDemoBitsOverflow = errors.New("read past the end of the demo data")
+	errDemoLZSS         = errors.New("invalid lzss data")
+)
+
+// bitReader reads the little endian, least significant bit first, bit streams used by source engine network
+// messages. Errors are sticky, once a read fails every following read returns zero values and err is set.
+type bitReader struct {
+	data []byte
+	pos  int
+	end  int
+	err  error
+}
+
+func newBitReader(data []byte) *bitReader {
+	return &bitReader{data: data, pos: 0, end: len(data) * 8, err: nil}
+}
+
+func (r *bitReader) remaining() int {
+	return r.end - r.pos
+}
+
+func (r *bitReader) bits(count int) uint64 {
+	if r.err != nil || count > r.remaining() {
+		r.err = errDemoBitsOverflow
+
+		return 0
+	}
+
+	var value uint64
+
+	for idx := 0; idx < count; idx++ {
+		if r.data[r.pos>>3]&(1<<(r.pos&7)) != 0 {
+			value |= 1 << idx
+		}
+
+		r.pos++
+	}
+
+	return value
+}
+
+func (r *bitReader) int(count int) int {
+	return int(r.bits(count))
+}
+
+func (r *bitReader) bool() bool {
+	return r.bits(1) == 1
+}
+
+func (r *bitReader) skip(count int) {
+	if r.err != nil || count < 0 || count > r.remaining() {
+		r.err = errDemoBitsOverflow
+
+		return
+	}
+
+	r.pos += count
+}
+
+func (r *bitReader) bytes(count int) []byte {
+	if r.err != nil || count < 0 || count*8 > r.remaining() {
+		r.err = errDemoBitsOverflow
+
+		return nil
+	}
+
+	value := make([]byte, count)
+	for idx := range value {
+		value[idx] = byte(r.bits(8))
+	}
+
+	return value
+}
+
+// string reads a null terminated string.
+func (r *bitReader) string() string {
+	var value []byte
+
+	for r.err == nil {
+		char := byte(r.bits(8))
+		if char == 0 {
+			break
+		}
+
+		value = append(value, char)
+	}
+
+	return string(value)
+}
+
+// varint32 reads a protobuf style variable length integer.
+func (r *bitReader) varint32() int {
+	var value uint32
+
+	for shift := 0; shift < 35 && r.err == nil; shift += 7 {
+		part := r.bits(8)
+		value |= uint32(part&0x7f) << shift
+
+		if part&0x80 == 0 {
+			break
+		}
+	}
+
+	return int(value)
+}
+
+// sub returns a reader for the next count bits, skipping past them.
+func (r *bitReader) sub(count int) *bitReader {
+	start := r.pos
+	r.skip(count)
+
+	if r.err != nil {
+		return &bitReader{data: nil, pos: 0, end: 0, err: r.err}
+	}
+
+	return &bitReader{data: r.data, pos: start, end: start + count, err: nil}
+}
+
+// bitCoord skips a world coordinate.
+func (r *bitReader) bitCoord() {
+	const (
+		coordIntegerBits    = 14
+		coordFractionalBits = 5
+	)
+
+	hasInt, hasFract := r.bool(), r.bool()
+	if !hasInt && !hasFract {
+		return
+	}
+
+	r.skip(1) // sign
+
+	if hasInt {
+		r.skip(coordIntegerBits)
+	}
+
+	if hasFract {
+		r.skip(coordFractionalBits)
+	}
+}
+
+// log2 matches Q_log2, the position of the highest set bit.
+func log2(value int) int {
+	result := 0
+	for value > 1 {
+		value >>= 1
+		result++
+	}
+
+	return result
+}
+
+// decompressLZSS decompresses data compressed with the engines LZSS implementation.
+func decompressLZSS(data []byte) ([]byte, error) {
+	const headerSize = 8
+
+	if len(data) < headerSize || string(data[:4]) != "LZSS" {
+		return nil, errDemoLZSS
+	}
+
+	size := int(binary.LittleEndian.Uint32(data[4:8]))
+	input := data[headerSize:]
+	output := make([]byte, 0, size)
+
+	var (
+		cmdByte byte
+		cmdBits int
+	)
+
+	for pos := 0; ; {
+		if cmdBits == 0 {
+			if pos >= len(input) {
+				return nil, errDemoLZSS
+			}
+
+			cmdByte = input[pos]
+			pos++
+		}
+
+		cmdBits = (cmdBits + 1) & 0x07
+
+		if cmdByte&0x01 == 0 {
+			if pos >= len(input) {
+				return nil, errDemoLZSS
+			}
+
+			output = append(output, input[pos])
+			pos++
+		} else {
+			if pos+1 >= len(input) {
+				return nil, errDemoLZSS
+			}
+
+			position := int(input[pos])<<4 | int(input[pos+1])>>4
+			count := int(input[pos+1]&0x0f) + 1
+			pos += 2
+
+			if count == 1 {
+				break
+			}
+
+			source := len(output) - position - 1
+			if source < 0 {
+				return nil, errDemoLZSS
+			}
+
+			for idx := 0; idx < count; idx++ {
+				output = append(output, output[source+idx])
+			}
+		}
+
+		cmdByte >>= 1
+	}
+
+	if len(output) != size {
+		return nil, errDemoLZSS
+	}
+
+	return output, nil
+}
@@ -0,0 +1,544 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+// bitWriter writes the bit streams read by bitReader, used to build the demo fixtures.
+type bitWriter struct {
+	data []byte
+	pos  int
+}
+
+func (w *bitWriter) bits(value uint64, count int) {
+	for idx := 0; idx < count; idx++ {
+		if w.pos>>3 == len(w.data) {
+			w.data = append(w.data, 0)
+		}
+
+		if value&(1<<idx) != 0 {
+			w.data[w.pos>>3] |= 1 << (w.pos & 7)
+		}
+
+		w.pos++
+	}
+}
+
+func (w *bitWriter) bool(value bool) {
+	if value {
+		w.bits(1, 1)
+	} else {
+		w.bits(0, 1)
+	}
+}
+
+func (w *bitWriter) bytes(value []byte) {
+	for _, char := range value {
+		w.bits(uint64(char), 8)
+	}
+}
+
+func (w *bitWriter) string(value string) {
+	w.bytes(append([]byte(value), 0))
+}
+
+func (w *bitWriter) varint32(value int) {
+	for value >= 0x80 {
+		w.bits(uint64(value&0x7f|0x80), 8)
+		value >>= 7
+	}
+
+	w.bits(uint64(value), 8)
+}
+
+func (w *bitWriter) write(other *bitWriter) {
+	reader := newBitReader(other.data)
+	for idx := 0; idx < other.pos; idx++ {
+		w.bits(reader.bits(1), 1)
+	}
+}
+
+// compressLZSS is the greedy counterpart of decompressLZSS.
+func compressLZSS(data []byte) []byte {
+	output := []byte("LZSS")
+	output = binary.LittleEndian.AppendUint32(output, uint32(len(data)))
+
+	cmdPos, cmdBits := 0, 8
+
+	token := func(isRef bool, values ...byte) {
+		if cmdBits == 8 {
+			output = append(output, 0)
+			cmdPos, cmdBits = len(output)-1, 0
+		}
+
+		if isRef {
+			output[cmdPos] |= 1 << cmdBits
+		}
+
+		cmdBits++
+
+		output = append(output, values...)
+	}
+
+	for pos := 0; pos < len(data); {
+		bestLen, bestPos := 0, 0
+
+		for start := max(0, pos-4095); start < pos; start++ {
+			length := 0
+			for length < 16 && pos+length < len(data) && data[start+length] == data[pos+length] {
+				length++
+			}
+
+			if length > bestLen {
+				bestLen, bestPos = length, pos-start-1
+			}
+		}
+
+		if bestLen >= 2 {
+			token(true, byte(bestPos>>4), byte(bestPos&0x0f)<<4|byte(bestLen-1))
+			pos += bestLen
+		} else {
+			token(false, data[pos])
+			pos++
+		}
+	}
+
+	token(true, 0, 0)
+
+	return output
+}
+
+type demoFixturePlayer struct {
+	name      string
+	userID    int
+	guid      string
+	friendsID uint32
+	fake      bool
+	hltv      bool
+}
+
+func (p demoFixturePlayer) info() []byte {
+	info := make([]byte, demoPlayerInfoSize)
+	copy(info[0:31], p.name)
+	binary.LittleEndian.PutUint32(info[32:36], uint32(p.userID))
+	copy(info[36:68], p.guid)
+	binary.LittleEndian.PutUint32(info[72:76], p.friendsID)
+	copy(info[76:107], p.name)
+
+	if p.fake {
+		info[108] = 1
+	}
+
+	if p.hltv {
+		info[109] = 1
+	}
+
+	return info
+}
+
+type demoFixtureEntry struct {
+	index     int
+	value     string
+	substring [2]int
+	player    *demoFixturePlayer
+}
+
+// entries writes string table entries, using the history substring encoding when substring is set.
+func (w *bitWriter) entries(maxEntries int, entries []demoFixtureEntry) {
+	last := -1
+
+	for _, entry := range entries {
+		w.bool(entry.index == last+1)
+
+		if entry.index != last+1 {
+			w.bits(uint64(entry.index), log2(maxEntries))
+		}
+
+		last = entry.index
+
+		w.bool(true)
+		w.bool(entry.substring[1] > 0)
+
+		if entry.substring[1] > 0 {
+			w.bits(uint64(entry.substring[0]), 5)
+			w.bits(uint64(entry.substring[1]), 5)
+		}
+
+		w.string(entry.value)
+		w.bool(entry.player != nil)
+
+		if entry.player != nil {
+			w.bits(demoPlayerInfoSize, 14)
+			w.bytes(entry.player.info())
+		}
+	}
+}
+
+func (w *bitWriter) createStringTable(name string, maxEntries int, compressed bool, entries []demoFixtureEntry) {
+	var data bitWriter
+
+	data.entries(maxEntries, entries)
+
+	if compressed {
+		raw := data.data
+
+		data = bitWriter{}
+		lzss := compressLZSS(raw)
+		data.bits(uint64(len(raw)), 32)
+		data.bits(uint64(len(lzss)), 32)
+		data.bytes(lzss)
+	}
+
+	w.bits(12, 6)
+	w.string(name)
+	w.bits(uint64(maxEntries), 16)
+	w.bits(uint64(len(entries)), log2(maxEntries)+1)
+	w.varint32(data.pos)
+	w.bool(false)
+	w.bool(compressed)
+	w.write(&data)
+}
+
+func (w *bitWriter) updateStringTable(tableID int, maxEntries int, entries []demoFixtureEntry) {
+	var data bitWriter
+
+	data.entries(maxEntries, entries)
+
+	w.bits(13, 6)
+	w.bits(uint64(tableID), 5)
+	w.bool(true)
+	w.bits(uint64(len(entries)), 16)
+	w.bits(uint64(data.pos), 20)
+	w.write(&data)
+}
+
+type demoWriter struct {
+	bytes.Buffer
+}
+
+func newDemoWriter(server string, mapName string, duration float32, ticks int) *demoWriter {
+	writer := &demoWriter{}
+	writer.WriteString(demoMagic)
+
+	path := func(value string) {
+		field := make([]byte, demoPathSize)
+		copy(field, value)
+		writer.Write(field)
+	}
+
+	_ = binary.Write(writer, binary.LittleEndian, []int32{3, 24})
+	path(server)
+	path("SourceTV Demo")
+	path(mapName)
+	path("tf")
+	_ = binary.Write(writer, binary.LittleEndian, duration)
+	_ = binary.Write(writer, binary.LittleEndian, []int32{int32(ticks), 0, 0})
+
+	return writer
+}
+
+func (w *demoWriter) frame(cmd demoCommand, tick int, payload ...[]byte) {
+	w.WriteByte(byte(cmd))
+	_ = binary.Write(w, binary.LittleEndian, int32(tick))
+
+	for _, data := range payload {
+		_ = binary.Write(w, binary.LittleEndian, int32(len(data)))
+		w.Write(data)
+	}
+}
+
+func (w *demoWriter) packet(cmd demoCommand, tick int, messages *bitWriter) {
+	w.WriteByte(byte(cmd))
+	_ = binary.Write(w, binary.LittleEndian, int32(tick))
+	w.Write(make([]byte, demoCmdInfoSize+8))
+	_ = binary.Write(w, binary.LittleEndian, int32(len(messages.data)))
+	w.Write(messages.data)
+}
+
+//nolint:gochecknoglobals
+var (
+	demoPlayerA  = demoFixturePlayer{name: "scout main", userID: 2, guid: "[U:1:1000001]", friendsID: 1000001}
+	demoPlayerB  = demoFixturePlayer{name: "pocket", userID: 3, guid: "", friendsID: 1000002}
+	demoPlayerC  = demoFixturePlayer{name: "late joiner", userID: 9, guid: "[U:1:1000003]", friendsID: 1000003}
+	demoPlayerD  = demoFixturePlayer{name: "compressed", userID: 4, guid: "[U:1:1000004]", friendsID: 1000004}
+	demoSourceTV = demoFixturePlayer{name: "SourceTV", userID: 1, guid: "BOT", hltv: true}
+	demoBot      = demoFixturePlayer{name: "Bot01", userID: 5, guid: "BOT", fake: true}
+)
+
+// demoFixtureSignon builds a demo recorded from the start of a map, with the players in the userinfo table
+// created during signon and later updated.
+func demoFixtureSignon() []byte {
+	demo := newDemoWriter("Ultiduo #1", "koth_ultiduo_r_b7", 95.5, 6368)
+
+	var signon bitWriter
+
+	signon.bits(3, 6) // net_Tick
+	signon.bits(1, 32)
+	signon.bits(15, 16)
+	signon.bits(0, 16)
+	signon.bits(8, 6) // svc_ServerInfo
+	signon.bits(24, 16)
+	signon.bits(1, 32)
+	signon.bool(false)
+	signon.bool(true)
+	signon.bits(0, 32)
+	signon.bits(300, 16)
+	signon.bytes(make([]byte, 16))
+	signon.bits(0, 8)
+	signon.bits(24, 8)
+	signon.bits(0, 32)
+	signon.bits('l', 8)
+	signon.string("tf")
+	signon.string("koth_ultiduo_r_b7")
+	signon.string("sky_tf2_04")
+	signon.string("Ultiduo #1")
+	signon.bool(false)
+	signon.bits(5, 6) // net_SetConVar
+	signon.bits(1, 8)
+	signon.string("mp_tournament")
+	signon.string("1")
+	signon.createStringTable("downloadables", 8192, false, nil)
+	signon.createStringTable(demoUserInfoTable, 256, false, []demoFixtureEntry{
+		{index: 0, value: "0", player: &demoSourceTV},
+		{index: 1, value: "1", player: &demoPlayerA},
+		{index: 2, value: "2", player: &demoBot},
+	})
+	signon.bits(6, 6) // net_SignonState
+	signon.bits(6, 8)
+	signon.bits(1, 32)
+
+	demo.packet(demSignon, 0, &signon)
+	demo.frame(demDataTables, 0, []byte{1, 2, 3, 4})
+	demo.frame(demSyncTick, 0)
+	demo.frame(demConsoleCmd, 10, []byte("tv_transmitall 1\x00"))
+
+	var update bitWriter
+
+	update.bits(7, 6) // svc_Print
+	update.string("pocket connected\n")
+	update.updateStringTable(1, 256, []demoFixtureEntry{
+		{index: 5, value: "5", player: &demoPlayerB},
+		{index: 6, value: "6", player: nil},
+	})
+
+	demo.packet(demPacket, 200, &update)
+
+	renamed := demoPlayerA
+	renamed.name = "scout main (renamed)"
+
+	var rename bitWriter
+
+	rename.updateStringTable(1, 256, []demoFixtureEntry{
+		{index: 1, value: "1", substring: [2]int{0, 1}, player: &renamed},
+	})
+	rename.bits(22, 6) // svc_TerrainMod is not sent by the engine, everything after it is ignored
+	rename.updateStringTable(1, 256, []demoFixtureEntry{{index: 7, value: "7", player: &demoPlayerC}})
+
+	demo.packet(demPacket, 400, &rename)
+	demo.WriteByte(byte(demUserCmd))
+	_ = binary.Write(demo, binary.LittleEndian, []int32{401, 1, 2})
+	demo.Write([]byte{0, 0})
+	demo.frame(demStop, 6368)
+
+	return demo.Bytes()
+}
+
+// demoFixtureStringTables builds a demo recorded part way into a map, which starts with a snapshot of the string
+// tables, uses a compressed userinfo table and ends without a stop command.
+func demoFixtureStringTables() []byte {
+	demo := newDemoWriter("Mid Map", "cp_process_final", 0, 0)
+
+	var tables bitWriter
+
+	tables.bits(2, 8)
+	tables.string("downloadables")
+	tables.bits(1, 16)
+	tables.string("maps/cp_process_final.bsp")
+	tables.bool(false)
+	tables.bool(false)
+	tables.string(demoUserInfoTable)
+	tables.bits(2, 16)
+	tables.string("0")
+	tables.bool(true)
+	tables.bits(demoPlayerInfoSize, 16)
+	tables.bytes(demoPlayerC.info())
+	tables.string("1")
+	tables.bool(true)
+	tables.bits(demoPlayerInfoSize, 16)
+	tables.bytes(demoSourceTV.info())
+	tables.bool(true)
+	tables.bits(1, 16)
+	tables.string("client")
+	tables.bool(true)
+	tables.bits(2, 16)
+	tables.bytes([]byte{1, 2})
+
+	demo.frame(demStringTables, 0, tables.data)
+
+	var signon bitWriter
+
+	signon.createStringTable(demoUserInfoTable, 256, true, []demoFixtureEntry{
+		{index: 0, value: "0", player: &demoPlayerC},
+		{index: 3, value: "3", player: &demoPlayerD},
+	})
+
+	demo.packet(demSignon, 0, &signon)
+
+	// Truncated part way through a packet.
+	demo.WriteByte(byte(demPacket))
+	_ = binary.Write(demo, binary.LittleEndian, int32(300))
+	demo.Write(make([]byte, 20))
+
+	return demo.Bytes()
+}
+
+// updateDemoFixtures rewrites the generated demo fixtures in testdata.
+var updateDemoFixtures = flag.Bool("update-demos", false, "Regenerate the demo fixtures") //nolint:gochecknoglobals
+
+func TestDemoFixtures(t *testing.T) {
+	t.Parallel()
+
+	for name, fixture := range map[string][]byte{
+		"demo_signon.dem":       demoFixtureSignon(),
+		"demo_stringtables.dem": demoFixtureStringTables(),
+	} {
+		path := filepath.Join("testdata", name)
+
+		if *updateDemoFixtures {
+			require.NoError(t, os.WriteFile(path, fixture, 0o600))
+
+			continue
+		}
+
+		expected, errRead := os.ReadFile(path)
+		require.NoErrorf(t, errRead, "Missing fixture, run with -update-demos to create it: %s", path)
+		require.Equal(t, expected, fixture)
+	}
+}
+
+func readDemoFixture(t *testing.T, name string) *domain.DemoInfo {
+	t.Helper()
+
+	file, errOpen := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, errOpen)
+
+	defer logCloser(file)
+
+	info, errDemo := parseDemo(file)
+	require.NoError(t, errDemo)
+
+	return info
+}
+
+func TestParseDemo(t *testing.T) {
+	t.Parallel()
+
+	info := readDemoFixture(t, "demo_signon.dem")
+
+	require.Equal(t, domain.DemoHeader{
+		DemoProtocol:    3,
+		NetworkProtocol: 24,
+		Server:          "Ultiduo #1",
+		Client:          "SourceTV Demo",
+		Map:             "koth_ultiduo_r_b7",
+		GameDir:         "tf",
+		Duration:        domain.JSONDuration{Duration: 95500 * time.Millisecond},
+		Ticks:           6368,
+		Frames:          0,
+	}, info.Header)
+
+	// Player C is only sent after a message that can't be parsed, so is never seen.
+	require.Equal(t, []domain.DemoPlayer{
+		{SteamID: steamid.New(76561197961265729), Name: "scout main (renamed)", UserID: 2},
+		{SteamID: steamid.New(76561197961265730), Name: "pocket", UserID: 3},
+	}, info.Players)
+}
+
+func TestParseDemoStringTables(t *testing.T) {
+	t.Parallel()
+
+	info := readDemoFixture(t, "demo_stringtables.dem")
+
+	require.Equal(t, "cp_process_final", info.Header.Map)
+	require.Equal(t, []domain.DemoPlayer{
+		{SteamID: steamid.New(76561197961265732), Name: "compressed", UserID: 4},
+		{SteamID: steamid.New(76561197961265731), Name: "late joiner", UserID: 9},
+	}, info.Players)
+}
+
+func TestParseDemoInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, errShort := parseDemo(bytes.NewReader([]byte(demoMagic)))
+	require.ErrorIs(t, errShort, errDemoHeader)
+
+	notDemo := bytes.Repeat([]byte{'L'}, 2000)
+	_, errMagic := parseDemo(bytes.NewReader(notDemo))
+	require.ErrorIs(t, errMagic, errDemoHeader)
+
+	demo := newDemoWriter("server", "map", 1, 1)
+	demo.frame(42, 0)
+
+	_, errFrame := parseDemo(bytes.NewReader(demo.Bytes()))
+	require.ErrorIs(t, errFrame, errDemoFrame)
+}
+
+func TestDecompressLZSS(t *testing.T) {
+	t.Parallel()
+
+	data := bytes.Repeat([]byte("userinfo [U:1:1000001] "), 20)
+
+	compressed := compressLZSS(data)
+	require.Less(t, len(compressed), len(data))
+
+	decompressed, errDecompress := decompressLZSS(compressed)
+	require.NoError(t, errDecompress)
+	require.Equal(t, data, decompressed)
+
+	_, errTruncated := decompressLZSS(compressed[:len(compressed)-4])
+	require.ErrorIs(t, errTruncated, errDemoLZSS)
+
+	_, errMagic := decompressLZSS([]byte("SNAP\x00\x00\x00\x00"))
+	require.ErrorIs(t, errMagic, errDemoLZSS)
+}
+
+func TestReadDemo(t *testing.T) {
+	t.Parallel()
+
+	var body bytes.Buffer
+
+	form := multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("title", "ignored"))
+
+	part, errPart := form.CreateFormFile("demo", "match.dem")
+	require.NoError(t, errPart)
+
+	_, errWrite := part.Write(demoFixtureSignon())
+	require.NoError(t, errWrite)
+	require.NoError(t, form.Close())
+
+	request := httptest.NewRequest(http.MethodPost, "/demo", &body)
+	request.Header.Set("Content-Type", form.FormDataContentType())
+
+	info, errDemo := readDemo(request)
+	require.NoError(t, errDemo)
+	require.Equal(t, "koth_ultiduo_r_b7", info.Header.Map)
+	require.Len(t, info.Players, 2)
+
+	missing := httptest.NewRequest(http.MethodPost, "/demo", bytes.NewReader(demoFixtureSignon()))
+	_, errMissing := readDemo(missing)
+	require.ErrorIs(t, errMissing, errInvalidDemo)
+}
@@ -164,35 +164,69 @@ type Player struct {
 	TimeStamped
 }
 
+// BanCategory is a normalized classification of a free text ban reason.
+type BanCategory string
+
+const (
+	CategoryCheating   BanCategory = "cheating"
+	CategoryExploiting BanCategory = "exploiting"
+	CategoryToxicity   BanCategory = "toxicity"
+	CategorySpam       BanCategory = "spam"
+	CategoryBanEvasion BanCategory = "ban_evasion"
+	CategoryBot        BanCategory = "bot"
+	CategoryOther      BanCategory = "other"
+)
+
+func BanCategories() []BanCategory {
+	return []BanCategory{
+		CategoryCheating, CategoryExploiting, CategoryToxicity, CategorySpam,
+		CategoryBanEvasion, CategoryBot, CategoryOther,
+	}
+}
+
 type SbBanRecord struct {
-	BanID       int             `json:"ban_id"`
-	SiteName    Site            `json:"site_name"`
-	SiteID      int             `json:"site_id"`
-	PersonaName string          `json:"persona_name"`
-	SteamID     steamid.SteamID `json:"steam_id"`
-	Reason      string          `json:"reason"`
-	Duration    time.Duration   `json:"duration"`
-	Permanent   bool            `json:"permanent"`
+	BanID            int             `json:"ban_id"`
+	SiteName         Site            `json:"site_name"`
+	SiteID           int             `json:"site_id"`
+	PersonaName      string          `json:"persona_name"`
+	SteamID          steamid.SteamID `json:"steam_id"`
+	Reason           string          `json:"reason"`
+	ReasonCategory   BanCategory     `json:"reason_category"`
+	Duration         time.Duration   `json:"duration"`
+	Permanent        bool            `json:"permanent"`
+	DurationMismatch bool            `json:"duration_mismatch"`
+	AdminName        string          `json:"admin_name"`
+	Server           string          `json:"server"`
+	TotalBans        int             `json:"total_bans"`
+	DemoURL          string          `json:"demo_url"`
 	TimeStamped
 }
 
 type SbSite struct {
 	SiteID int  `json:"site_id"`
 	Name   Site `json:"name"`
+	// Enabled controls if the site is included when scraping
+	Enabled       bool       `json:"enabled"`
+	LastScrapedOn *time.Time `json:"last_scraped_on"`
+	// Timezone is the IANA timezone the site renders its ban times in, empty when unknown
+	Timezone string `json:"timezone"`
 	TimeStamped
 }
 
 // Profile is a high level meta profile of several services.
 type Profile struct {
-	Summary     steamweb.PlayerSummary `json:"summary"`
-	BanState    PlayerBanState         `json:"ban_state"`
-	SourceBans  []SbBanRecord          `json:"source_bans"`
-	ServeMe     *ServeMeRecord         `json:"serve_me"`
-	LeagueBans  map[League][]any       `json:"league_bans"`
-	LogsCount   int                    `json:"logs_count"`
-	BotDetector []BDSearchResult       `json:"bot_detector"`
-	RGL         []RGLPlayerTeamHistory `json:"rgl"`
-	Friends     []steamweb.Friend      `json:"friends"`
+	Summary     steamweb.PlayerSummary   `json:"summary"`
+	BanState    PlayerBanState           `json:"ban_state"`
+	SourceBans  []SbBanRecord            `json:"source_bans"`
+	ServeMe     *ServeMeRecord           `json:"serve_me"`
+	LeagueBans  map[League][]any         `json:"league_bans"`
+	LogsCount   int                      `json:"logs_count"`
+	BotDetector []BDSearchResult         `json:"bot_detector"`
+	RGL         []RGLPlayerTeamHistory   `json:"rgl"`
+	ETF2L       []ETF2LPlayerTeamHistory `json:"etf2l"`
+	UGC         []UGCPlayerTeamHistory   `json:"ugc"`
+	Ozfortress  []OzfortressHistory      `json:"ozfortress"`
+	Friends     []steamweb.Friend        `json:"friends"`
 }
 
 type PlayerBanState struct {
@@ -228,25 +262,57 @@ func (d JSONFloat32) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.Value) //nolint:wrapcheck
 }
 
+// LogsTFFormat is the competitive format of a match, derived from the size of the teams.
+type LogsTFFormat string
+
+const (
+	FormatUnknown    LogsTFFormat = ""
+	FormatUltiduo    LogsTFFormat = "ultiduo"
+	Format4v4        LogsTFFormat = "4v4"
+	Format6v6        LogsTFFormat = "6v6"
+	FormatProlander  LogsTFFormat = "prolander"
+	FormatHighlander LogsTFFormat = "highlander"
+)
+
+func LogsTFFormats() []LogsTFFormat {
+	return []LogsTFFormat{FormatUltiduo, Format4v4, Format6v6, FormatProlander, FormatHighlander}
+}
+
+// LogsTFSource is where a match was imported from.
+type LogsTFSource string
+
+const (
+	// SourceLogsTF matches are scraped from logs.tf and use the logs.tf log id.
+	SourceLogsTF LogsTFSource = "logstf"
+	// SourceLocal matches are parsed from server logs that were imported directly. They use negative log ids so
+	// they never collide with logs.tf ids.
+	SourceLocal LogsTFSource = "local"
+)
+
 type LogsTFMatchInfo struct {
 	LogID        int          `json:"log_id"`
+	Source       LogsTFSource `json:"source"`
 	Title        string       `json:"title"`
 	Map          string       `json:"map"`
-	Format       string       `json:"format"`
+	Format       LogsTFFormat `json:"format"`
 	Views        int          `json:"-"`
 	Duration     JSONDuration `json:"duration"`
 	ScoreRED     int          `json:"score_red"`
 	ScoreBLU     int          `json:"score_blu"`
 	CreatedOn    time.Time    `json:"created_on"`
 	LogFormatOld bool         `json:"-"`
+	// Checksum of the imported server log, used to prevent importing the same log twice
+	Checksum string `json:"-"`
 }
 
 type LogsTFMatch struct {
 	LogsTFMatchInfo
 
-	Rounds  []LogsTFRound  `json:"rounds"`
-	Players []LogsTFPlayer `json:"players"`
-	Medics  []LogsTFMedic  `json:"medics"`
+	Rounds      []LogsTFRound      `json:"rounds"`
+	Players     []LogsTFPlayer     `json:"players"`
+	Medics      []LogsTFMedic      `json:"medics"`
+	Chat        []LogsTFChat       `json:"chat"`
+	Killstreaks []LogsTFKillstreak `json:"killstreaks"`
 }
 
 type PlayerClass int
@@ -324,12 +390,29 @@ type LogsTFPlayerClass struct {
 type LogsTFPlayerClassWeapon struct {
 	LogID    int             `json:"-"`
 	SteamID  steamid.SteamID `json:"steam_id"`
+	Class    PlayerClass     `json:"-"`
 	Weapon   string          `json:"weapon,omitempty"`
 	Kills    int             `json:"kills,omitempty"`
 	Damage   int             `json:"damage,omitempty"`
 	Accuracy int             `json:"accuracy,omitempty"`
 }
 
+// LogsTFChat is a single chat message. Messages from the console have an invalid steam id.
+type LogsTFChat struct {
+	LogID   int             `json:"-"`
+	SteamID steamid.SteamID `json:"steam_id"`
+	Name    string          `json:"name"`
+	Message string          `json:"message"`
+}
+
+type LogsTFKillstreak struct {
+	LogID   int             `json:"-"`
+	SteamID steamid.SteamID `json:"steam_id"`
+	Streak  int             `json:"streak"`
+	// Time is the offset from the start of the match
+	Time JSONDuration `json:"time"`
+}
+
 type LogsTFMedic struct {
 	LogID            int             `json:"-"`
 	SteamID          steamid.SteamID `json:"steam_id"`
@@ -381,17 +464,131 @@ type LogsTFPlayerAverages struct {
 	HealingTakenAvg JSONFloat32 `json:"healing_taken_avg"`
 }
 
+// LogsTFPlayerClassStats is a summary of every game a player has played as a single class.
+type LogsTFPlayerClassStats struct {
+	Class       PlayerClass  `json:"class"`
+	Games       int          `json:"games"`
+	Played      JSONDuration `json:"played"`
+	KillsSum    int          `json:"kills_sum"`
+	AssistsSum  int          `json:"assists_sum"`
+	DeathsSum   int          `json:"deaths_sum"`
+	DamageSum   int64        `json:"damage_sum"`
+	KillsAvg    JSONFloat32  `json:"kills_avg"`
+	AssistsAvg  JSONFloat32  `json:"assists_avg"`
+	DeathsAvg   JSONFloat32  `json:"deaths_avg"`
+	DamageAvg   JSONFloat32  `json:"damage_avg"`
+	KD          JSONFloat32  `json:"kd"`
+	KAD         JSONFloat32  `json:"kad"`
+	DPM         JSONFloat32  `json:"dpm"`
+	KillsPerMin JSONFloat32  `json:"kills_per_min"`
+	// Medic only
+	HealingSum    int64        `json:"healing_sum"`
+	HealingPerMin JSONFloat32  `json:"healing_per_min"`
+	ChargesSum    int          `json:"charges_sum"`
+	AvgUberLen    JSONDuration `json:"avg_uber_len"`
+}
+
 type LogsTFPlayerSummary struct {
 	Logs int `json:"logs"`
 	LogsTFPlayerAverages
 	LogsTFPlayerSums
+	// Percentiles is nil for players with too few matches to be ranked.
+	Percentiles *LogsTFPercentiles `json:"percentiles"`
+}
+
+// LogsTFPercentiles are the percentage of ranked players that a player's averages are better than.
+type LogsTFPercentiles struct {
+	DPM       float32 `json:"dpm"`
+	KD        float32 `json:"kd"`
+	KAD       float32 `json:"kad"`
+	Kills     float32 `json:"kills"`
+	Damage    float32 `json:"damage"`
+	DTM       float32 `json:"dtm"`
+	Airshots  float32 `json:"airshots"`
+	Headshots float32 `json:"headshots"`
+}
+
+// LogsTFShared is the history of a group of players that played in the same logs.
+type LogsTFShared struct {
+	SteamIDs steamid.Collection `json:"steam_ids"`
+	// SameTeam are the logs where every player was on the same team
+	SameTeam LogsTFSharedRecord `json:"same_team"`
+	// OpposingTeams are the logs where the players were split across both teams. Wins and losses are from the
+	// perspective of the first player in SteamIDs, which are sorted lowest first rather than in the order requested.
+	OpposingTeams LogsTFSharedRecord `json:"opposing_teams"`
+}
+
+type LogsTFSharedRecord struct {
+	Games   int                       `json:"games"`
+	Wins    int                       `json:"wins"`
+	Losses  int                       `json:"losses"`
+	Ties    int                       `json:"ties"`
+	Players []LogsTFSharedPlayerStats `json:"players"`
+	Logs    []LogsTFMatchInfo         `json:"logs"`
+}
+
+type LogsTFSharedPlayerStats struct {
+	SteamID    steamid.SteamID `json:"steam_id"`
+	KillsSum   int             `json:"kills_sum"`
+	AssistsSum int             `json:"assists_sum"`
+	DeathsSum  int             `json:"deaths_sum"`
+	DamageSum  int64           `json:"damage_sum"`
+	DPMAvg     JSONFloat32     `json:"dpm_avg"`
+	KD         JSONFloat32     `json:"kd"`
 }
 
-type ServeMeRecord struct {
+// LogsTFTeammate is a player that has played in the same logs as another player.
+type LogsTFTeammate struct {
 	SteamID steamid.SteamID `json:"steam_id"`
 	Name    string          `json:"name"`
-	Reason  string          `json:"reason"`
-	Deleted bool            `json:"deleted"`
+	// Games is the number of logs played on the same team
+	Games  int `json:"games"`
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	// Against is the number of logs played on the opposing team
+	Against    int       `json:"against"`
+	LastPlayed time.Time `json:"last_played"`
+}
+
+// LogsTFMapStats are the aggregate results of every match played on a map.
+type LogsTFMapStats struct {
+	Map         string       `json:"map"`
+	Games       int          `json:"games"`
+	DurationAvg JSONDuration `json:"duration_avg"`
+	RedWins     int          `json:"red_wins"`
+	BluWins     int          `json:"blu_wins"`
+	Ties        int          `json:"ties"`
+	RedWinRate  JSONFloat32  `json:"red_win_rate"`
+	BluWinRate  JSONFloat32  `json:"blu_win_rate"`
+	// RoundsAvg only includes matches with round data
+	RoundsSum int         `json:"rounds_sum"`
+	RoundsAvg JSONFloat32 `json:"rounds_avg"`
+}
+
+// LogsTFFormatTrend is the number of matches played in a format during a period.
+type LogsTFFormatTrend struct {
+	Period      time.Time    `json:"period"`
+	Format      LogsTFFormat `json:"format"`
+	Games       int          `json:"games"`
+	DurationAvg JSONDuration `json:"duration_avg"`
+	// Share is the percentage of all the matches played during the period
+	Share JSONFloat32 `json:"share"`
+}
+
+type LogsTFLeaderboardEntry struct {
+	Rank    int             `json:"rank"`
+	SteamID steamid.SteamID `json:"steam_id"`
+	Name    string          `json:"name"`
+	Games   int             `json:"games"`
+	Value   JSONFloat32     `json:"value"`
+}
+
+type ServeMeRecord struct {
+	SteamID        steamid.SteamID `json:"steam_id"`
+	Name           string          `json:"name"`
+	Reason         string          `json:"reason"`
+	ReasonCategory BanCategory     `json:"reason_category"`
+	Deleted        bool            `json:"deleted"`
 	TimeStamped
 }
 
@@ -476,6 +673,7 @@ type RGLTeam struct {
 	Tag          string          `json:"tag,omitempty"`
 	TeamName     string          `json:"team_name,omitempty"`
 	FinalRank    int             `json:"final_rank,omitempty"`
+	ScrapedOn    *time.Time      `json:"-"`
 	// TeamStatus   string
 	// TeamReady    bool
 }
@@ -519,26 +717,206 @@ type RGLMatch struct {
 	PointsB      float32   `json:"points_b"`
 }
 
+// RGLTeamStanding is a teams record within its season, calculated from the stored matches.
+type RGLTeamStanding struct {
+	RGLTeam
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Points float32 `json:"points"`
+}
+
+type RGLSeasonDetail struct {
+	RGLSeason
+	Teams []RGLTeamStanding `json:"teams"`
+}
+
+type RGLTeamDetail struct {
+	RGLTeam
+	Roster  []RGLTeamMember `json:"roster"`
+	Matches []RGLMatch      `json:"matches"`
+}
+
+type RGLMatchDetail struct {
+	RGLMatch
+	TeamA RGLTeam `json:"team_a"`
+	TeamB RGLTeam `json:"team_b"`
+}
+
 type RGLBan struct {
-	SteamID   steamid.SteamID `json:"steam_id"`
-	Alias     string          `json:"alias"`
-	ExpiresAt time.Time       `json:"expires_at"`
-	CreatedAt time.Time       `json:"created_at"`
-	Reason    string          `json:"reason"`
+	SteamID        steamid.SteamID `json:"steam_id"`
+	Alias          string          `json:"alias"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Reason         string          `json:"reason"`
+	ReasonCategory BanCategory     `json:"reason_category"`
 }
 
 // ETF2LBan aliases the RGLBan model which is already good, just make it more obvious what it is.
 type ETF2LBan RGLBan
 
+// ETF2LTeam is an etf2l team. Teams first seen in a transfer only have their name and type set until their own
+// details are fetched.
+type ETF2LTeam struct {
+	TeamID    int        `json:"team_id"`
+	TeamName  string     `json:"team_name"`
+	Tag       string     `json:"tag"`
+	TeamType  string     `json:"team_type"`
+	Country   string     `json:"country"`
+	ScrapedOn *time.Time `json:"-"`
+	CreatedOn time.Time  `json:"created_on"`
+	UpdatedOn time.Time  `json:"updated_on"`
+}
+
+// ETF2LTeamMember is a single stint on a team, built from the players join and leave transfers.
+type ETF2LTeamMember struct {
+	TeamID   int             `json:"team_id"`
+	SteamID  steamid.SteamID `json:"steam_id"`
+	Name     string          `json:"name"`
+	JoinedAt time.Time       `json:"joined_at"`
+	LeftAt   *time.Time      `json:"left_at"`
+}
+
+// ETF2LTransfer is a player joining, or leaving, a team.
+type ETF2LTransfer struct {
+	TeamID   int             `json:"team_id"`
+	TeamName string          `json:"team_name"`
+	TeamType string          `json:"team_type"`
+	SteamID  steamid.SteamID `json:"steam_id"`
+	Name     string          `json:"name"`
+	Joined   bool            `json:"joined"`
+	Time     time.Time       `json:"time"`
+}
+
+// ETF2LMatch is a match result. Scores are the number of rounds won by each team.
+type ETF2LMatch struct {
+	MatchID         int       `json:"match_id"`
+	CompetitionID   int       `json:"competition_id"`
+	CompetitionName string    `json:"competition_name"`
+	DivisionName    string    `json:"division_name"`
+	RoundName       string    `json:"round_name"`
+	Team1ID         int       `json:"team1_id"`
+	Team2ID         int       `json:"team2_id"`
+	Team1Score      int       `json:"team1_score"`
+	Team2Score      int       `json:"team2_score"`
+	DefaultWin      bool      `json:"default_win"`
+	Maps            []string  `json:"maps"`
+	PlayedAt        time.Time `json:"played_at"`
+}
+
+type ETF2LPlayerTeamHistory struct {
+	TeamID   int             `json:"team_id"`
+	TeamName string          `json:"team_name"`
+	Tag      string          `json:"tag"`
+	TeamType string          `json:"team_type"`
+	Name     string          `json:"name"`
+	JoinedAt time.Time       `json:"joined_at"`
+	LeftAt   *time.Time      `json:"left_at"`
+	SteamID  steamid.SteamID `json:"-"`
+}
+
 type LeagueBanMap map[steamid.SteamID]map[League][]any
 
 type League string
 
 const (
 	RGL   League = "rgl"
-	ETF2L League = "rgl"
+	ETF2L League = "etf2l"
+	// UGCLeague is named to avoid the ugc-gaming sourcebans Site.
+	UGCLeague  League = "ugc"
+	Ozfortress League = "ozfortress"
 )
 
+// UGCBan aliases the RGLBan model, UGC bans have the same shape.
+type UGCBan RGLBan
+
+// UGCTeam is a team scraped from ugcleague.com. Teams first seen in a players team history only have their name,
+// format and division set until their own page is scraped.
+type UGCTeam struct {
+	TeamID       int        `json:"team_id"`
+	TeamName     string     `json:"team_name"`
+	Tag          string     `json:"tag"`
+	FormatName   string     `json:"format_name"`
+	DivisionName string     `json:"division_name"`
+	RegionName   string     `json:"region_name"`
+	ScrapedOn    *time.Time `json:"scraped_on"`
+	CreatedOn    time.Time  `json:"created_on"`
+	UpdatedOn    time.Time  `json:"updated_on"`
+}
+
+// UGCTeamMember is a single stint of a player on a UGC team roster. Players that rejoin a team get another entry.
+type UGCTeamMember struct {
+	TeamID       int             `json:"team_id"`
+	SteamID      steamid.SteamID `json:"steam_id"`
+	Name         string          `json:"name"`
+	IsTeamLeader bool            `json:"is_team_leader"`
+	JoinedAt     time.Time       `json:"joined_at"`
+	LeftAt       *time.Time      `json:"left_at"`
+}
+
+// OzfortressBan aliases the RGLBan model, ozfortress bans have the same shape.
+type OzfortressBan RGLBan
+
+// OzfortressRoster is a teams roster for a single ozfortress league.
+type OzfortressRoster struct {
+	RosterID     int       `json:"roster_id"`
+	TeamID       int       `json:"team_id"`
+	TeamName     string    `json:"team_name"`
+	RosterName   string    `json:"roster_name"`
+	DivisionName string    `json:"division_name"`
+	LeagueID     int       `json:"league_id"`
+	LeagueName   string    `json:"league_name"`
+	CreatedOn    time.Time `json:"created_on"`
+	UpdatedOn    time.Time `json:"updated_on"`
+}
+
+type OzfortressRosterMember struct {
+	RosterID int             `json:"roster_id"`
+	SteamID  steamid.SteamID `json:"steam_id"`
+	Name     string          `json:"name"`
+}
+
+// OzfortressMatch is a match result. AwayRosterID is nil for byes. Scores are the number of rounds won.
+type OzfortressMatch struct {
+	MatchID      int       `json:"match_id"`
+	LeagueID     int       `json:"league_id"`
+	RoundName    string    `json:"round_name"`
+	Status       string    `json:"status"`
+	HomeRosterID int       `json:"home_roster_id"`
+	AwayRosterID *int      `json:"away_roster_id"`
+	HomeScore    int       `json:"home_score"`
+	AwayScore    int       `json:"away_score"`
+	ForfeitBy    string    `json:"forfeit_by"`
+	Maps         []string  `json:"maps"`
+	CreatedOn    time.Time `json:"created_on"`
+}
+
+// OzfortressHistory is a roster a player was on, along with the rosters match record.
+type OzfortressHistory struct {
+	RosterID     int             `json:"roster_id"`
+	TeamID       int             `json:"team_id"`
+	TeamName     string          `json:"team_name"`
+	DivisionName string          `json:"division_name"`
+	LeagueName   string          `json:"league_name"`
+	Name         string          `json:"name"`
+	Wins         int             `json:"wins"`
+	Losses       int             `json:"losses"`
+	Draws        int             `json:"draws"`
+	SteamID      steamid.SteamID `json:"-"`
+}
+
+type UGCPlayerTeamHistory struct {
+	TeamID       int             `json:"team_id"`
+	TeamName     string          `json:"team_name"`
+	Tag          string          `json:"tag"`
+	FormatName   string          `json:"format_name"`
+	DivisionName string          `json:"division_name"`
+	Name         string          `json:"name"`
+	IsTeamLeader bool            `json:"is_team_leader"`
+	JoinedAt     time.Time       `json:"joined_at"`
+	LeftAt       *time.Time      `json:"left_at"`
+	SteamID      steamid.SteamID `json:"-"`
+}
+
 type SteamGame struct {
 	AppID      steamid.AppID `json:"app_id"`
 	Name       string        `json:"name"`
@@ -607,3 +985,35 @@ type SteamServerCounts struct {
 	Vac       int
 	SDR       int
 }
+
+// DemoHeader is the header at the start of a source engine demo.
+type DemoHeader struct {
+	DemoProtocol    int          `json:"demo_protocol"`
+	NetworkProtocol int          `json:"network_protocol"`
+	Server          string       `json:"server"`
+	Client          string       `json:"client"`
+	Map             string       `json:"map"`
+	GameDir         string       `json:"game_dir"`
+	Duration        JSONDuration `json:"duration"`
+	Ticks           int          `json:"ticks"`
+	Frames          int          `json:"frames"`
+}
+
+// DemoPlayer is a player found in the userinfo string table of a demo.
+type DemoPlayer struct {
+	SteamID steamid.SteamID `json:"steam_id"`
+	Name    string          `json:"name"`
+	UserID  int             `json:"user_id"`
+}
+
+// DemoInfo is the header and every real player that was connected at some point during the demo.
+type DemoInfo struct {
+	Header  DemoHeader   `json:"header"`
+	Players []DemoPlayer `json:"players"`
+}
+
+// DemoInspection is the result of inspecting an uploaded demo, including the profiles of its players.
+type DemoInspection struct {
+	DemoInfo
+	Profiles []Profile `json:"profiles"`
+}
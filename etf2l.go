@@ -2,16 +2,36 @@
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/leighmacdonald/bd-api/domain"
 	"github.com/leighmacdonald/etf2l"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"golang.org/x/time/rate"
 )
 
-var errETF2LFetchBans = errors.New("failed to fetch etf2l bans")
+var (
+	errETF2LFetchBans = errors.New("failed to fetch etf2l bans")
+	errETF2LStatus    = errors.New("unexpected etf2l response status")
+	errETF2LNotFound  = errors.New("etf2l resource not found")
+)
+
+const (
+	etf2lBaseURL = "https://api-v2.etf2l.org"
+	// etf2lRefreshInterval is how long fetched teams and players are considered fresh.
+	etf2lRefreshInterval = 7 * 24 * time.Hour
+	// etf2lMaxPages caps how many pages of a paginated list a single job will fetch.
+	etf2lMaxPages   = 50
+	etf2lRefillRate = 1
+	etf2lBucketSize = 2
+)
 
 func updateETF2LBans(ctx context.Context, database *pgStore, client *etf2l.Client, httpClient *http.Client) error {
 	bans, errBans := client.Bans(ctx, httpClient, etf2l.BanOpts{
@@ -30,11 +50,12 @@ func updateETF2LBans(ctx context.Context, database *pgStore, client *etf2l.Clien
 		}
 
 		eBans = append(eBans, domain.ETF2LBan{
-			SteamID:   ban.Steamid64,
-			Alias:     ban.Name,
-			ExpiresAt: time.Unix(int64(ban.End), 0).Truncate(time.Second),
-			CreatedAt: time.Unix(int64(ban.Start), 0).Truncate(time.Second),
-			Reason:    ban.Reason,
+			SteamID:        ban.Steamid64,
+			Alias:          ban.Name,
+			ExpiresAt:      time.Unix(int64(ban.End), 0).Truncate(time.Second),
+			CreatedAt:      time.Unix(int64(ban.Start), 0).Truncate(time.Second),
+			Reason:         ban.Reason,
+			ReasonCategory: classifyReason(ban.Reason),
 		})
 	}
 
@@ -46,3 +67,279 @@ func updateETF2LBans(ctx context.Context, database *pgStore, client *etf2l.Clien
 
 	return nil
 }
+
+func NewETF2LLimiter() *LimiterCustom {
+	return &LimiterCustom{Limiter: rate.NewLimiter(etf2lRefillRate, etf2lBucketSize)}
+}
+
+// etf2lAPIClient queries the etf2l v2 api for players, teams, transfers and results.
+type etf2lAPIClient struct {
+	httpClient *http.Client
+	limiter    *LimiterCustom
+	baseURL    string
+}
+
+// newETF2LAPIClient creates a client sharing the limiter used by the etf2l library client, as both query the same
+// api.
+func newETF2LAPIClient(limiter *LimiterCustom) *etf2lAPIClient {
+	return &etf2lAPIClient{httpClient: NewHTTPClient(), limiter: limiter, baseURL: etf2lBaseURL}
+}
+
+// newETF2LHTTPClient returns the http client used by the etf2l library, which waits on the limiter before each
+// request.
+func newETF2LHTTPClient(limiter *LimiterCustom) *http.Client {
+	client := NewHTTPClient()
+	client.Transport = &limitedTransport{limiter: limiter, next: http.DefaultTransport}
+
+	return client
+}
+
+type etf2lSteam struct {
+	ID64 string `json:"id64"`
+}
+
+type etf2lPlayer struct {
+	ID    int        `json:"id"`
+	Name  string     `json:"name"`
+	Steam etf2lSteam `json:"steam"`
+}
+
+type etf2lTeam struct {
+	ID      int           `json:"id"`
+	Name    string        `json:"name"`
+	Tag     string        `json:"tag"`
+	Type    string        `json:"type"`
+	Country string        `json:"country"`
+	Players []etf2lPlayer `json:"players"`
+}
+
+type etf2lTransfer struct {
+	Type string      `json:"type"`
+	Time int64       `json:"time"`
+	Who  etf2lPlayer `json:"who"`
+	Team struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"team"`
+}
+
+type etf2lClan struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type etf2lResult struct {
+	ID          int       `json:"id"`
+	Clan1       etf2lClan `json:"clan1"`
+	Clan2       etf2lClan `json:"clan2"`
+	Competition struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"competition"`
+	Division struct {
+		Name string `json:"name"`
+	} `json:"division"`
+	Round      string   `json:"round"`
+	R1         int      `json:"r1"`
+	R2         int      `json:"r2"`
+	DefaultWin bool     `json:"defaultwin"`
+	Maps       []string `json:"maps"`
+	Time       int64    `json:"time"`
+}
+
+// etf2lPage is the envelope used by the paginated list endpoints.
+type etf2lPage[T any] struct {
+	Data        []T `json:"data"`
+	CurrentPage int `json:"current_page"`
+	LastPage    int `json:"last_page"`
+}
+
+func (c *etf2lAPIClient) get(ctx context.Context, path string, out any) error {
+	c.limiter.Wait(ctx)
+
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if errReq != nil {
+		return errors.Join(errReq, errRequestCreate)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, errResp := c.httpClient.Do(req)
+	if errResp != nil {
+		return errors.Join(errResp, errRequestPerform)
+	}
+
+	defer logCloser(resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return errETF2LNotFound
+	default:
+		return fmt.Errorf("%w: %d", errETF2LStatus, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.Join(err, errResponseDecode)
+	}
+
+	return nil
+}
+
+// etf2lPages fetches each page of a list endpoint, up to maxPages. The list is found under key in the response.
+func etf2lPages[T any](ctx context.Context, client *etf2lAPIClient, path string, key string, maxPages int) ([]T, error) {
+	var results []T
+
+	for pageNum := 1; pageNum <= maxPages; pageNum++ {
+		var resp map[string]etf2lPage[T]
+
+		if err := client.get(ctx, path+"?page="+strconv.Itoa(pageNum), &resp); err != nil {
+			return nil, err
+		}
+
+		page := resp[key]
+		results = append(results, page.Data...)
+
+		if page.CurrentPage >= page.LastPage {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+func (c *etf2lAPIClient) player(ctx context.Context, steamID steamid.SteamID) (etf2lPlayer, error) {
+	var resp struct {
+		Player etf2lPlayer `json:"player"`
+	}
+
+	if err := c.get(ctx, "/player/"+steamID.String(), &resp); err != nil {
+		return etf2lPlayer{}, err
+	}
+
+	return resp.Player, nil
+}
+
+func (c *etf2lAPIClient) playerTransfers(ctx context.Context, playerID int) ([]domain.ETF2LTransfer, error) {
+	transfers, err := etf2lPages[etf2lTransfer](ctx, c, "/player/"+strconv.Itoa(playerID)+"/transfers",
+		"transfers", etf2lMaxPages)
+	if err != nil {
+		return nil, err
+	}
+
+	return etf2lTransfers(transfers), nil
+}
+
+func (c *etf2lAPIClient) team(ctx context.Context, teamID int) (etf2lTeam, error) {
+	var resp struct {
+		Team etf2lTeam `json:"team"`
+	}
+
+	if err := c.get(ctx, "/team/"+strconv.Itoa(teamID), &resp); err != nil {
+		return etf2lTeam{}, err
+	}
+
+	return resp.Team, nil
+}
+
+func (c *etf2lAPIClient) teamTransfers(ctx context.Context, teamID int) ([]domain.ETF2LTransfer, error) {
+	transfers, err := etf2lPages[etf2lTransfer](ctx, c, "/team/"+strconv.Itoa(teamID)+"/transfers",
+		"transfers", etf2lMaxPages)
+	if err != nil {
+		return nil, err
+	}
+
+	return etf2lTransfers(transfers), nil
+}
+
+func (c *etf2lAPIClient) teamResults(ctx context.Context, teamID int) ([]domain.ETF2LMatch, error) {
+	results, err := etf2lPages[etf2lResult](ctx, c, "/team/"+strconv.Itoa(teamID)+"/results",
+		"results", etf2lMaxPages)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]domain.ETF2LMatch, len(results))
+	for idx, result := range results {
+		matches[idx] = result.toDomain()
+	}
+
+	return matches, nil
+}
+
+// recentTransfers fetches the most recent transfers across every team, newest first.
+func (c *etf2lAPIClient) recentTransfers(ctx context.Context, maxPages int) ([]domain.ETF2LTransfer, error) {
+	transfers, err := etf2lPages[etf2lTransfer](ctx, c, "/transfers", "transfers", maxPages)
+	if err != nil {
+		return nil, err
+	}
+
+	return etf2lTransfers(transfers), nil
+}
+
+// etf2lTransfers converts the transfers into oldest first order, so they can be applied in sequence. Transfers
+// without a valid steam id, and types other than joined and left, are skipped.
+func etf2lTransfers(transfers []etf2lTransfer) []domain.ETF2LTransfer {
+	converted := make([]domain.ETF2LTransfer, 0, len(transfers))
+
+	for _, transfer := range transfers {
+		sid := steamid.New(transfer.Who.Steam.ID64)
+		if !sid.Valid() || (transfer.Type != "joined" && transfer.Type != "left") {
+			continue
+		}
+
+		converted = append(converted, domain.ETF2LTransfer{
+			TeamID:   transfer.Team.ID,
+			TeamName: transfer.Team.Name,
+			TeamType: transfer.Team.Type,
+			SteamID:  sid,
+			Name:     transfer.Who.Name,
+			Joined:   transfer.Type == "joined",
+			Time:     time.Unix(transfer.Time, 0),
+		})
+	}
+
+	slices.SortStableFunc(converted, func(a, b domain.ETF2LTransfer) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return converted
+}
+
+func (t etf2lTeam) toDomain() domain.ETF2LTeam {
+	now := time.Now()
+
+	return domain.ETF2LTeam{
+		TeamID:    t.ID,
+		TeamName:  t.Name,
+		Tag:       t.Tag,
+		TeamType:  t.Type,
+		Country:   t.Country,
+		ScrapedOn: &now,
+		CreatedOn: now,
+		UpdatedOn: now,
+	}
+}
+
+func (r etf2lResult) toDomain() domain.ETF2LMatch {
+	maps := r.Maps
+	if maps == nil {
+		maps = []string{}
+	}
+
+	return domain.ETF2LMatch{
+		MatchID:         r.ID,
+		CompetitionID:   r.Competition.ID,
+		CompetitionName: r.Competition.Name,
+		DivisionName:    r.Division.Name,
+		RoundName:       r.Round,
+		Team1ID:         r.Clan1.ID,
+		Team2ID:         r.Clan2.ID,
+		Team1Score:      r.R1,
+		Team2Score:      r.R2,
+		DefaultWin:      r.DefaultWin,
+		Maps:            maps,
+		PlayedAt:        time.Unix(r.Time, 0),
+	}
+}
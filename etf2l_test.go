@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// newTestETF2LClient returns a client backed by a local stand in for the api, serving the json fixtures.
+func newTestETF2LClient(t *testing.T) *etf2lAPIClient {
+	t.Helper()
+
+	server := newFixtureServer(t, map[string]string{
+		"/player/76561198084134025":      "testdata/etf2l_player.json",
+		"/player/21341/transfers?page=1": "testdata/etf2l_player_transfers_1.json",
+		"/player/21341/transfers?page=2": "testdata/etf2l_player_transfers_2.json",
+		"/team/3311":                     "testdata/etf2l_team.json",
+		"/team/3311/transfers?page=1":    "testdata/etf2l_team_transfers.json",
+		"/team/3311/results?page=1":      "testdata/etf2l_team_results.json",
+		"/transfers?page=1":              "testdata/etf2l_player_transfers_1.json",
+		"/transfers?page=2":              "testdata/etf2l_player_transfers_2.json",
+	}, nil)
+
+	client := newETF2LAPIClient(&LimiterCustom{Limiter: rate.NewLimiter(rate.Inf, 1)})
+	client.baseURL = server.URL
+
+	return client
+}
+
+func TestETF2LClient(t *testing.T) {
+	t.Parallel()
+
+	client := newTestETF2LClient(t)
+	ctx := context.Background()
+	sid := steamid.New(76561198084134025)
+
+	player, errPlayer := client.player(ctx, sid)
+	require.NoError(t, errPlayer)
+	require.Equal(t, 21341, player.ID)
+	require.Equal(t, "habib", player.Name)
+
+	_, errMissing := client.player(ctx, steamid.New(76561197960265729))
+	require.ErrorIs(t, errMissing, errETF2LNotFound)
+
+	// Both pages are fetched, the unknown transfer type is dropped and the rest are sorted oldest first.
+	transfers, errTransfers := client.playerTransfers(ctx, player.ID)
+	require.NoError(t, errTransfers)
+	require.Len(t, transfers, 3)
+	require.True(t, transfers[0].Joined)
+	require.Equal(t, 2042, transfers[0].TeamID)
+	require.Equal(t, time.Unix(1672531200, 0), transfers[0].Time)
+	require.False(t, transfers[1].Joined)
+	require.Equal(t, 3311, transfers[2].TeamID)
+	require.Equal(t, sid, transfers[2].SteamID)
+
+	recent, errRecent := client.recentTransfers(ctx, 1)
+	require.NoError(t, errRecent)
+	require.Len(t, recent, 2)
+
+	team, errTeam := client.team(ctx, 3311)
+	require.NoError(t, errTeam)
+	require.Equal(t, "SG", team.toDomain().Tag)
+	require.Len(t, team.Players, 2)
+
+	matches, errMatches := client.teamResults(ctx, 3311)
+	require.NoError(t, errMatches)
+	require.Len(t, matches, 2)
+	require.Equal(t, 88421, matches[0].MatchID)
+	require.Equal(t, "ETF2L 6v6 Season 47", matches[0].CompetitionName)
+	require.Equal(t, "Premiership", matches[0].DivisionName)
+	require.Equal(t, 3, matches[0].Team1Score)
+	require.Equal(t, []string{"cp_process_f12", "cp_sunshine"}, matches[0].Maps)
+	require.True(t, matches[1].DefaultWin)
+	require.Empty(t, matches[1].Maps)
+	require.NotNil(t, matches[1].Maps)
+}
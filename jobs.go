@@ -35,19 +35,32 @@
 type JobsKind string
 
 const (
-	KindRGLSeason    JobsKind = "rgl_season"
-	KindRGLTeam      JobsKind = "rgl_team"
-	KindRGLMatch     JobsKind = "rgl_match"
-	KindRGLBan       JobsKind = "rgl_ban"
-	KindETF2LBan     JobsKind = "etf2l_ban"
-	KindSteamSummary JobsKind = "steam_summary"
-	KindSteamBan     JobsKind = "steam_ban"
-	KindSteamGames   JobsKind = "steam_games"
-	KindSteamServers JobsKind = "steam_servers"
-	KindServemeBan   JobsKind = "serveme_ban"
-	KindSourcebans   JobsKind = "sourcebans"
-	KindLogsTF       JobsKind = "logstf"
-	KindBDLists      JobsKind = "bd_lists"
+	KindRGLSeason            JobsKind = "rgl_season"
+	KindRGLTeam              JobsKind = "rgl_team"
+	KindRGLMatch             JobsKind = "rgl_match"
+	KindRGLBan               JobsKind = "rgl_ban"
+	KindETF2LBan             JobsKind = "etf2l_ban"
+	KindETF2LTransfer        JobsKind = "etf2l_transfer"
+	KindETF2LTeam            JobsKind = "etf2l_team"
+	KindETF2LResults         JobsKind = "etf2l_results"
+	KindETF2LPlayer          JobsKind = "etf2l_player"
+	KindUGCBan               JobsKind = "ugc_ban"
+	KindUGCTeam              JobsKind = "ugc_team"
+	KindUGCPlayer            JobsKind = "ugc_player"
+	KindOzfortressRosterScan JobsKind = "ozfortress_roster_scan"
+	KindOzfortressMatch      JobsKind = "ozfortress_match"
+	KindOzfortressPlayer     JobsKind = "ozfortress_player"
+	KindSteamSummary         JobsKind = "steam_summary"
+	KindSteamBan             JobsKind = "steam_ban"
+	KindSteamGames           JobsKind = "steam_games"
+	KindSteamServers         JobsKind = "steam_servers"
+	KindServemeBan           JobsKind = "serveme_ban"
+	KindSourcebans           JobsKind = "sourcebans"
+	KindLogsTF               JobsKind = "logstf"
+	KindLogsTFGap            JobsKind = "logstf_gap"
+	KindLogsTFLeaderboard    JobsKind = "logstf_leaderboard"
+	KindBDLists              JobsKind = "bd_lists"
+	KindBanCategory          JobsKind = "ban_category"
 )
 
 type JobQueue string
@@ -57,6 +70,8 @@
 	QueuePriority   JobQueue = "queue_priority"
 	QueueRGL        JobQueue = "queue_rgl"
 	QueueETF2L      JobQueue = "queue_etf2l"
+	QueueUGC        JobQueue = "queue_ugc"
+	QueueOzfortress JobQueue = "queue_ozfortress"
 	QueueSteam      JobQueue = "queue_steam"
 	QueueLogsTF     JobQueue = "queue_logstf"
 	QueueSourcebans JobQueue = "queue_sourcebans"
@@ -91,6 +106,40 @@ func etf2lInsertOpts() river.InsertOpts {
 	}
 }
 
+// etf2lCrawlInsertOpts are used by the team and player crawl, which queues the same teams and players many times.
+func etf2lCrawlInsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:    string(QueueETF2L),
+		Priority: int(Slow),
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: 24 * time.Hour,
+		},
+	}
+}
+
+func ugcInsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:    string(QueueUGC),
+		Priority: int(Slow),
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: 24 * time.Hour,
+		},
+	}
+}
+
+func ozfortressInsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:    string(QueueOzfortress),
+		Priority: int(Slow),
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: 24 * time.Hour,
+		},
+	}
+}
+
 func setupQueue(ctx context.Context, dbPool *pgxpool.Pool) error {
 	migrator := rivermigrate.New[pgx.Tx](riverpgxv5.New(dbPool), nil)
 
@@ -140,6 +189,16 @@ func createJobWorkers(database *pgStore, config appConfig) *river.Workers {
 		database: database,
 	})
 
+	// Ban reason classification
+	river.AddWorker[BanCategoryArgs](workers, &BanCategoryWorker{
+		database: database,
+	})
+
+	// Logs.tf leaderboards, these are refreshed even when the scraper is disabled
+	river.AddWorker[LogsTFLeaderboardArgs](workers, &LogsTFLeaderboardWorker{
+		database: database,
+	})
+
 	// RGL
 	if config.RGLScraperEnabled {
 		rglLimiter := NewRGLLimiter()
@@ -169,10 +228,67 @@ func createJobWorkers(database *pgStore, config appConfig) *river.Workers {
 
 	// ETF2L
 	if config.ETF2LScraperEnabled {
+		etf2lLimiter := NewETF2LLimiter()
+
 		river.AddWorker[ETF2LBanArgs](workers, &ETF2LBanWorker{
 			database:   database,
 			client:     etf2l.New(),
-			httpClient: NewHTTPClient(),
+			httpClient: newETF2LHTTPClient(etf2lLimiter),
+		})
+
+		etf2lAPI := newETF2LAPIClient(etf2lLimiter)
+
+		river.AddWorker[ETF2LTransferArgs](workers, &ETF2LTransferWorker{
+			database: database,
+			client:   etf2lAPI,
+		})
+		river.AddWorker[ETF2LTeamArgs](workers, &ETF2LTeamWorker{
+			database: database,
+			client:   etf2lAPI,
+		})
+		river.AddWorker[ETF2LResultsArgs](workers, &ETF2LResultsWorker{
+			database: database,
+			client:   etf2lAPI,
+		})
+		river.AddWorker[ETF2LPlayerArgs](workers, &ETF2LPlayerWorker{
+			database: database,
+			client:   etf2lAPI,
+		})
+	}
+
+	// UGC
+	if config.UGCScraperEnabled {
+		ugc := newUGCClient()
+
+		river.AddWorker[UGCBanArgs](workers, &UGCBanWorker{
+			database: database,
+			client:   ugc,
+		})
+		river.AddWorker[UGCTeamArgs](workers, &UGCTeamWorker{
+			database: database,
+			client:   ugc,
+		})
+		river.AddWorker[UGCPlayerArgs](workers, &UGCPlayerWorker{
+			database: database,
+			client:   ugc,
+		})
+	}
+
+	// ozfortress
+	if config.OzfortressScraperEnabled {
+		ozfortress := newOzfortressClient(config.OzfortressAPIKey)
+
+		river.AddWorker[OzfortressRosterScanArgs](workers, &OzfortressRosterScanWorker{
+			database: database,
+			client:   ozfortress,
+		})
+		river.AddWorker[OzfortressMatchArgs](workers, &OzfortressMatchWorker{
+			database: database,
+			client:   ozfortress,
+		})
+		river.AddWorker[OzfortressPlayerArgs](workers, &OzfortressPlayerWorker{
+			database: database,
+			client:   ozfortress,
 		})
 	}
 
@@ -190,6 +306,10 @@ func createJobWorkers(database *pgStore, config appConfig) *river.Workers {
 			database: database,
 			config:   config,
 		})
+		river.AddWorker[LogsTFGapArgs](workers, &LogsTFGapWorker{
+			database: database,
+			config:   config,
+		})
 	}
 
 	return workers
@@ -221,6 +341,12 @@ func() (river.JobArgs, *river.InsertOpts) {
 				return BDListArgs{}, nil
 			},
 			&river.PeriodicJobOpts{RunOnStart: true}),
+		river.NewPeriodicJob(
+			river.PeriodicInterval(24*time.Hour),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return BanCategoryArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: true}),
 	}
 
 	if config.RGLScraperEnabled {
@@ -248,6 +374,32 @@ func() (river.JobArgs, *river.InsertOpts) {
 				func() (river.JobArgs, *river.InsertOpts) {
 					return ETF2LBanArgs{}, nil
 				},
+				&river.PeriodicJobOpts{RunOnStart: true}),
+			river.NewPeriodicJob(
+				river.PeriodicInterval(12*time.Hour),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return ETF2LTransferArgs{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true}))
+	}
+
+	if config.UGCScraperEnabled {
+		jobs = append(jobs,
+			river.NewPeriodicJob(
+				river.PeriodicInterval(24*time.Hour),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return UGCBanArgs{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true}))
+	}
+
+	if config.OzfortressScraperEnabled {
+		jobs = append(jobs,
+			river.NewPeriodicJob(
+				river.PeriodicInterval(24*time.Hour),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return OzfortressRosterScanArgs{}, nil
+				},
 				&river.PeriodicJobOpts{RunOnStart: true}))
 	}
 
@@ -268,9 +420,25 @@ func() (river.JobArgs, *river.InsertOpts) {
 				func() (river.JobArgs, *river.InsertOpts) {
 					return LogsTFArgs{}, nil
 				},
-				&river.PeriodicJobOpts{RunOnStart: true}))
+				&river.PeriodicJobOpts{RunOnStart: true}),
+			river.NewPeriodicJob(
+				river.PeriodicInterval(30*time.Minute),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return LogsTFGapArgs{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: false}))
 	}
 
+	// Uploaded and imported logs are included in the leaderboards, so they are refreshed even when the scraper is
+	// disabled.
+	jobs = append(jobs,
+		river.NewPeriodicJob(
+			river.PeriodicInterval(6*time.Hour),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return LogsTFLeaderboardArgs{}, nil
+			},
+			&river.PeriodicJobOpts{RunOnStart: true}))
+
 	return jobs
 }
 
@@ -284,6 +452,8 @@ func createJobClient(dbPool *pgxpool.Pool, workers *river.Workers, periodic []*r
 			string(QueueRGL):        {MaxWorkers: 1},
 			string(QueueSteam):      {MaxWorkers: 1},
 			string(QueueETF2L):      {MaxWorkers: 1},
+			string(QueueUGC):        {MaxWorkers: 1},
+			string(QueueOzfortress): {MaxWorkers: 1},
 			string(QueueLogsTF):     {MaxWorkers: 1},
 			string(QueueSourcebans): {MaxWorkers: 1},
 		},
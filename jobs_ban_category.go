@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/riverqueue/river"
+)
+
+type BanCategoryArgs struct{}
+
+func (BanCategoryArgs) Kind() string {
+	return string(KindBanCategory)
+}
+
+func (BanCategoryArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:    string(QueueDefault),
+		Priority: int(Slow),
+	}
+}
+
+// BanCategoryWorker backfills the reason_category of any ban rows which were saved before they were
+// being classified at ingest.
+type BanCategoryWorker struct {
+	river.WorkerDefaults[BanCategoryArgs]
+	database *pgStore
+}
+
+func (w *BanCategoryWorker) Work(ctx context.Context, _ *river.Job[BanCategoryArgs]) error {
+	return updateBanCategories(ctx, w.database)
+}
+
+func updateBanCategories(ctx context.Context, database *pgStore) error {
+	for _, table := range banReasonTables() {
+		reasons, errReasons := database.banReasonsUncategorized(ctx, table)
+		if errReasons != nil {
+			return errReasons
+		}
+
+		if len(reasons) == 0 {
+			continue
+		}
+
+		categories := make(map[string]domain.BanCategory, len(reasons))
+		for _, reason := range reasons {
+			categories[reason] = classifyReason(reason)
+		}
+
+		if errUpdate := database.banReasonCategoriesUpdate(ctx, table, categories); errUpdate != nil {
+			return errUpdate
+		}
+
+		slog.Info("Classified ban reasons", slog.String("table", table), slog.Int("reasons", len(reasons)))
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/riverqueue/river"
+)
+
+// etf2lTransferPages is how many pages of the recent transfers are fetched on each run.
+const etf2lTransferPages = 5
+
+// ETF2LTransferArgs applies the most recent transfers across every team. Teams involved that have not been
+// fetched recently are queued, which seeds the team and player crawl.
+type ETF2LTransferArgs struct{}
+
+func (ETF2LTransferArgs) Kind() string {
+	return string(KindETF2LTransfer)
+}
+
+func (ETF2LTransferArgs) InsertOpts() river.InsertOpts {
+	return etf2lCrawlInsertOpts()
+}
+
+type ETF2LTransferWorker struct {
+	river.WorkerDefaults[ETF2LTransferArgs]
+	database *pgStore
+	client   *etf2lAPIClient
+}
+
+func (w *ETF2LTransferWorker) Work(ctx context.Context, _ *river.Job[ETF2LTransferArgs]) error {
+	transfers, errTransfers := w.client.recentTransfers(ctx, etf2lTransferPages)
+	if errTransfers != nil {
+		return errTransfers
+	}
+
+	if err := w.database.etf2lTransfersApply(ctx, transfers); err != nil {
+		return err
+	}
+
+	slog.Info("Applied ETF2L transfers successfully", slog.Int("count", len(transfers)))
+
+	return queueETF2LStaleTeams(ctx, w.database, transferTeamIDs(transfers))
+}
+
+// ETF2LTeamArgs fetches a teams details and full transfer history. Its results and current players are queued.
+type ETF2LTeamArgs struct {
+	TeamID int `json:"team_id"`
+}
+
+func (ETF2LTeamArgs) Kind() string {
+	return string(KindETF2LTeam)
+}
+
+func (ETF2LTeamArgs) InsertOpts() river.InsertOpts {
+	return etf2lCrawlInsertOpts()
+}
+
+type ETF2LTeamWorker struct {
+	river.WorkerDefaults[ETF2LTeamArgs]
+	database *pgStore
+	client   *etf2lAPIClient
+}
+
+func (w *ETF2LTeamWorker) Work(ctx context.Context, job *river.Job[ETF2LTeamArgs]) error {
+	existing, errExisting := w.database.etf2lTeamGet(ctx, job.Args.TeamID)
+	if errExisting != nil && !errors.Is(errExisting, errDatabaseNoResults) {
+		return errExisting
+	}
+
+	if existing.ScrapedOn != nil && time.Since(*existing.ScrapedOn) < etf2lRefreshInterval {
+		return nil
+	}
+
+	team, errTeam := w.client.team(ctx, job.Args.TeamID)
+	if errTeam != nil {
+		if errors.Is(errTeam, errETF2LNotFound) {
+			slog.Warn("ETF2L team not found", slog.Int("team_id", job.Args.TeamID))
+
+			return nil
+		}
+
+		return errTeam
+	}
+
+	transfers, errTransfers := w.client.teamTransfers(ctx, team.ID)
+	if errTransfers != nil {
+		return errTransfers
+	}
+
+	if err := w.database.etf2lTransfersApply(ctx, transfers); err != nil {
+		return err
+	}
+
+	newJobs := []river.InsertManyParams{{Args: ETF2LResultsArgs{TeamID: team.ID}}}
+
+	for _, player := range team.Players {
+		if sid := steamid.New(player.Steam.ID64); sid.Valid() {
+			newJobs = append(newJobs, river.InsertManyParams{Args: ETF2LPlayerArgs{SteamID: sid}})
+		}
+	}
+
+	if err := w.database.insertJobsTx(ctx, river.ClientFromContext[pgx.Tx](ctx), newJobs); err != nil {
+		return err
+	}
+
+	// Saving the team marks it as fetched, so it's only done once everything else has succeeded. Otherwise a retry
+	// would skip the team until the refresh interval.
+	return w.database.etf2lTeamSave(ctx, team.toDomain())
+}
+
+// ETF2LResultsArgs fetches every match result of a team.
+type ETF2LResultsArgs struct {
+	TeamID int `json:"team_id"`
+}
+
+func (ETF2LResultsArgs) Kind() string {
+	return string(KindETF2LResults)
+}
+
+func (ETF2LResultsArgs) InsertOpts() river.InsertOpts {
+	return etf2lCrawlInsertOpts()
+}
+
+type ETF2LResultsWorker struct {
+	river.WorkerDefaults[ETF2LResultsArgs]
+	database *pgStore
+	client   *etf2lAPIClient
+}
+
+func (w *ETF2LResultsWorker) Work(ctx context.Context, job *river.Job[ETF2LResultsArgs]) error {
+	matches, errMatches := w.client.teamResults(ctx, job.Args.TeamID)
+	if errMatches != nil {
+		if errors.Is(errMatches, errETF2LNotFound) {
+			return nil
+		}
+
+		return errMatches
+	}
+
+	return w.database.etf2lMatchesSave(ctx, matches)
+}
+
+// ETF2LPlayerArgs fetches a players profile and full transfer history. Teams that have not been fetched recently
+// are queued.
+type ETF2LPlayerArgs struct {
+	SteamID steamid.SteamID `json:"steam_id"`
+}
+
+func (ETF2LPlayerArgs) Kind() string {
+	return string(KindETF2LPlayer)
+}
+
+func (ETF2LPlayerArgs) InsertOpts() river.InsertOpts {
+	return etf2lCrawlInsertOpts()
+}
+
+type ETF2LPlayerWorker struct {
+	river.WorkerDefaults[ETF2LPlayerArgs]
+	database *pgStore
+	client   *etf2lAPIClient
+}
+
+func (w *ETF2LPlayerWorker) Work(ctx context.Context, job *river.Job[ETF2LPlayerArgs]) error {
+	sid := job.Args.SteamID
+
+	scrapedOn, errScraped := w.database.etf2lPlayerScrapedOn(ctx, sid)
+	if errScraped != nil && !errors.Is(errScraped, errDatabaseNoResults) {
+		return errScraped
+	}
+
+	if time.Since(scrapedOn) < etf2lRefreshInterval {
+		return nil
+	}
+
+	player, errPlayer := w.client.player(ctx, sid)
+	if errPlayer != nil {
+		if !errors.Is(errPlayer, errETF2LNotFound) {
+			return errPlayer
+		}
+
+		// Players without an etf2l profile are still recorded, so they are not fetched again until the refresh
+		// interval.
+		return w.database.etf2lPlayerScraped(ctx, sid, 0, "")
+	}
+
+	transfers, errTransfers := w.client.playerTransfers(ctx, player.ID)
+	if errTransfers != nil {
+		return errTransfers
+	}
+
+	if err := w.database.etf2lTransfersApply(ctx, transfers); err != nil {
+		return err
+	}
+
+	if err := w.database.etf2lPlayerScraped(ctx, sid, player.ID, player.Name); err != nil {
+		return err
+	}
+
+	return queueETF2LStaleTeams(ctx, w.database, transferTeamIDs(transfers))
+}
+
+// queueETF2LStaleTeams queues the teams which have not been fetched within the refresh interval.
+func queueETF2LStaleTeams(ctx context.Context, database *pgStore, teamIDs []int) error {
+	staleIDs, errStale := database.etf2lTeamsStale(ctx, teamIDs, time.Now().Add(-etf2lRefreshInterval))
+	if errStale != nil {
+		return errStale
+	}
+
+	newJobs := make([]river.InsertManyParams, len(staleIDs))
+	for idx, teamID := range staleIDs {
+		newJobs[idx] = river.InsertManyParams{Args: ETF2LTeamArgs{TeamID: teamID}}
+	}
+
+	return database.insertJobsTx(ctx, river.ClientFromContext[pgx.Tx](ctx), newJobs)
+}
+
+func transferTeamIDs(transfers []domain.ETF2LTransfer) []int {
+	teamIDs := make([]int, 0, len(transfers))
+	for _, transfer := range transfers {
+		teamIDs = append(teamIDs, transfer.TeamID)
+	}
+
+	return teamIDs
+}
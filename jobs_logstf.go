@@ -52,3 +52,130 @@ func (w *LogsTFWorker) Work(ctx context.Context, _ *river.Job[LogsTFArgs]) error
 
 	return nil
 }
+
+type LogsTFGapArgs struct{}
+
+func (LogsTFGapArgs) Kind() string {
+	return string(KindLogsTFGap)
+}
+
+func (LogsTFGapArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:      string(QueueLogsTF),
+		Priority:   int(Slow),
+		UniqueOpts: river.UniqueOpts{ByPeriod: 30 * time.Minute},
+	}
+}
+
+// LogsTFGapWorker retries the logs which could not be stored by previous runs.
+type LogsTFGapWorker struct {
+	river.WorkerDefaults[LogsTFGapArgs]
+	database *pgStore
+	config   appConfig
+}
+
+func (w *LogsTFGapWorker) Timeout(_ *river.Job[LogsTFGapArgs]) time.Duration {
+	return time.Hour * 6
+}
+
+func (w *LogsTFGapWorker) Work(ctx context.Context, _ *river.Job[LogsTFGapArgs]) error {
+	gaps, errGaps := w.database.logsTFGapsDue(ctx, time.Now(), logsTFGapBatchSize)
+	if errGaps != nil {
+		return errGaps
+	}
+
+	if len(gaps) == 0 {
+		return nil
+	}
+
+	var logIDs []int
+
+	for _, gap := range gaps {
+		// Backfilled ranges can include logs that are already stored.
+		existing, errExisting := w.database.logsTFExistingIDs(ctx, gap.StartID, gap.EndID)
+		if errExisting != nil {
+			return errExisting
+		}
+
+		stored := make(map[int]bool, len(existing))
+		for _, logID := range existing {
+			stored[logID] = true
+		}
+
+		for _, logID := range gap.ids() {
+			if !stored[logID] {
+				logIDs = append(logIDs, logID)
+			}
+		}
+	}
+
+	scraper, errScraper := NewLogsTFScraper(w.database, w.config)
+	if errScraper != nil {
+		return errScraper
+	}
+
+	if w.config.ProxiesEnabled {
+		if errProxies := attachCollectorProxies(scraper.Collector, &w.config); errProxies != nil {
+			return errProxies
+		}
+	}
+
+	failures, errScrape := scrapeLogsTFIDs(ctx, scraper, logIDs)
+	if errScrape != nil {
+		slog.Error("Failed to retry logs.tf gaps", ErrAttr(errScrape))
+
+		return errScrape
+	}
+
+	now := time.Now()
+
+	var retries []logsTFGap
+	for _, gap := range gaps {
+		retries = append(retries, gap.retry(failures, now)...)
+	}
+
+	if errReplace := w.database.logsTFGapsReplace(ctx, gaps, retries); errReplace != nil {
+		return errReplace
+	}
+
+	slog.Info("Retried logs.tf gaps", slog.Int("gaps", len(gaps)), slog.Int("logs", len(logIDs)),
+		slog.Int("failed", failures.len()))
+
+	return nil
+}
+
+type LogsTFLeaderboardArgs struct{}
+
+func (LogsTFLeaderboardArgs) Kind() string {
+	return string(KindLogsTFLeaderboard)
+}
+
+func (LogsTFLeaderboardArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:      string(QueueDefault),
+		Priority:   int(Slow),
+		UniqueOpts: river.UniqueOpts{ByPeriod: 6 * time.Hour},
+	}
+}
+
+// LogsTFLeaderboardWorker refreshes the materialized views used for leaderboards and percentile ranks.
+type LogsTFLeaderboardWorker struct {
+	river.WorkerDefaults[LogsTFLeaderboardArgs]
+	database *pgStore
+}
+
+func (w *LogsTFLeaderboardWorker) Timeout(_ *river.Job[LogsTFLeaderboardArgs]) time.Duration {
+	return time.Hour
+}
+
+func (w *LogsTFLeaderboardWorker) Work(ctx context.Context, _ *river.Job[LogsTFLeaderboardArgs]) error {
+	startTime := time.Now()
+
+	if err := w.database.logsTFLeaderboardsRefresh(ctx); err != nil {
+		return err
+	}
+
+	slog.Info("Refreshed logs.tf leaderboards", slog.Duration("duration", time.Since(startTime)))
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/riverqueue/river"
+)
+
+const (
+	// ozfortressRosterBatch is how many roster ids a single scan job fetches before queueing the next batch.
+	ozfortressRosterBatch = 100
+	// ozfortressRosterRescan is how many of the most recent rosters are fetched again on each scan, so that
+	// active rosters pick up new players and matches.
+	ozfortressRosterRescan = 200
+	// ozfortressRosterMaxMisses is how many consecutive missing roster ids end the scan.
+	ozfortressRosterMaxMisses = 25
+)
+
+// OzfortressRosterScanArgs walks the roster ids, as there is no listing endpoint. A StartRosterID of 0 resumes
+// from the most recent known rosters. Each roster queues its unconfirmed matches and its players.
+type OzfortressRosterScanArgs struct {
+	StartRosterID int `json:"start_roster_id"`
+}
+
+func (OzfortressRosterScanArgs) Kind() string {
+	return string(KindOzfortressRosterScan)
+}
+
+func (OzfortressRosterScanArgs) InsertOpts() river.InsertOpts {
+	return ozfortressInsertOpts()
+}
+
+type OzfortressRosterScanWorker struct {
+	river.WorkerDefaults[OzfortressRosterScanArgs]
+	database *pgStore
+	client   *ozfortressClient
+}
+
+func (w *OzfortressRosterScanWorker) Work(ctx context.Context, job *river.Job[OzfortressRosterScanArgs]) error {
+	start := job.Args.StartRosterID
+	if start == 0 {
+		maxID, errMax := w.database.ozfortressRosterMaxID(ctx)
+		if errMax != nil {
+			return errMax
+		}
+
+		start = max(1, maxID-ozfortressRosterRescan)
+	}
+
+	var (
+		misses  int
+		newJobs []river.InsertManyParams
+	)
+
+	for rosterID := start; rosterID < start+ozfortressRosterBatch; rosterID++ {
+		roster, errRoster := w.client.roster(ctx, rosterID)
+		if errRoster != nil {
+			if !errors.Is(errRoster, errOzfortressNotFound) {
+				return errRoster
+			}
+
+			misses++
+			if misses >= ozfortressRosterMaxMisses {
+				slog.Info("Completed ozfortress roster scan", slog.Int("last_roster_id", rosterID))
+
+				return w.database.insertJobsTx(ctx, river.ClientFromContext[pgx.Tx](ctx), newJobs)
+			}
+
+			continue
+		}
+
+		misses = 0
+
+		rosterJobs, errSave := w.saveRoster(ctx, roster)
+		if errSave != nil {
+			return errSave
+		}
+
+		newJobs = append(newJobs, rosterJobs...)
+	}
+
+	newJobs = append(newJobs, river.InsertManyParams{
+		Args: OzfortressRosterScanArgs{StartRosterID: start + ozfortressRosterBatch},
+	})
+
+	return w.database.insertJobsTx(ctx, river.ClientFromContext[pgx.Tx](ctx), newJobs)
+}
+
+func (w *OzfortressRosterScanWorker) saveRoster(ctx context.Context, apiRoster ozfortressRoster) ([]river.InsertManyParams, error) {
+	roster, members := apiRoster.toDomain()
+
+	if err := w.database.ozfortressRosterSave(ctx, roster, members); err != nil {
+		return nil, err
+	}
+
+	matchIDs := make([]int, len(apiRoster.Matches))
+	for idx, match := range apiRoster.Matches {
+		matchIDs[idx] = match.ID
+	}
+
+	pendingIDs, errPending := w.database.ozfortressMatchesPending(ctx, matchIDs)
+	if errPending != nil {
+		return nil, errPending
+	}
+
+	newJobs := make([]river.InsertManyParams, 0, len(pendingIDs)+len(members))
+
+	for _, matchID := range pendingIDs {
+		newJobs = append(newJobs, river.InsertManyParams{Args: OzfortressMatchArgs{MatchID: matchID}})
+	}
+
+	for _, member := range members {
+		newJobs = append(newJobs, river.InsertManyParams{Args: OzfortressPlayerArgs{SteamID: member.SteamID}})
+	}
+
+	return newJobs, nil
+}
+
+// OzfortressMatchArgs fetches a single match result.
+type OzfortressMatchArgs struct {
+	MatchID int `json:"match_id"`
+}
+
+func (OzfortressMatchArgs) Kind() string {
+	return string(KindOzfortressMatch)
+}
+
+func (OzfortressMatchArgs) InsertOpts() river.InsertOpts {
+	return ozfortressInsertOpts()
+}
+
+type OzfortressMatchWorker struct {
+	river.WorkerDefaults[OzfortressMatchArgs]
+	database *pgStore
+	client   *ozfortressClient
+}
+
+func (w *OzfortressMatchWorker) Work(ctx context.Context, job *river.Job[OzfortressMatchArgs]) error {
+	match, errMatch := w.client.match(ctx, job.Args.MatchID)
+	if errMatch != nil {
+		if errors.Is(errMatch, errOzfortressNotFound) {
+			slog.Warn("Ozfortress match not found", slog.Int("match_id", job.Args.MatchID))
+
+			return nil
+		}
+
+		return errMatch
+	}
+
+	return w.database.ozfortressMatchSave(ctx, match.toDomain())
+}
+
+// OzfortressPlayerArgs fetches a players user profile, replacing their bans.
+type OzfortressPlayerArgs struct {
+	SteamID steamid.SteamID `json:"steam_id"`
+}
+
+func (OzfortressPlayerArgs) Kind() string {
+	return string(KindOzfortressPlayer)
+}
+
+func (OzfortressPlayerArgs) InsertOpts() river.InsertOpts {
+	return ozfortressInsertOpts()
+}
+
+type OzfortressPlayerWorker struct {
+	river.WorkerDefaults[OzfortressPlayerArgs]
+	database *pgStore
+	client   *ozfortressClient
+}
+
+func (w *OzfortressPlayerWorker) Work(ctx context.Context, job *river.Job[OzfortressPlayerArgs]) error {
+	sid := job.Args.SteamID
+
+	scrapedOn, errScraped := w.database.ozfortressPlayerScrapedOn(ctx, sid)
+	if errScraped != nil && !errors.Is(errScraped, errDatabaseNoResults) {
+		return errScraped
+	}
+
+	if time.Since(scrapedOn) < ozfortressRefreshInterval {
+		return nil
+	}
+
+	user, errUser := w.client.user(ctx, sid)
+	if errUser != nil && !errors.Is(errUser, errOzfortressNotFound) {
+		return errUser
+	}
+
+	if err := w.database.ozfortressBansReplace(ctx, sid, user.toBans(sid)); err != nil {
+		return err
+	}
+
+	// Players without an ozfortress account are still recorded, so they are not fetched again until the refresh
+	// interval.
+	return w.database.ozfortressPlayerScraped(ctx, sid, user.ID, user.Name)
+}
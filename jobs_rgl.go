@@ -18,6 +18,8 @@
 const (
 	rglRefillRate = 0.5
 	rglBucketSize = 5
+	// rglRefreshInterval is how long a fetched team roster is considered fresh.
+	rglRefreshInterval = 7 * 24 * time.Hour
 )
 
 func NewRGLLimiter() *LimiterCustom {
@@ -177,11 +179,12 @@ func (w *RGLBansWorker) Work(ctx context.Context, _ *river.Job[RGLBanArgs]) erro
 			}
 
 			bans = append(bans, domain.RGLBan{
-				SteamID:   sid,
-				Alias:     ban.Alias,
-				ExpiresAt: ban.ExpiresAt,
-				CreatedAt: ban.CreatedAt,
-				Reason:    ban.Reason,
+				SteamID:        sid,
+				Alias:          ban.Alias,
+				ExpiresAt:      ban.ExpiresAt,
+				CreatedAt:      ban.CreatedAt,
+				Reason:         ban.Reason,
+				ReasonCategory: classifyReason(ban.Reason),
 			})
 		}
 
@@ -283,52 +286,63 @@ func (w *RGLTeamWorker) Work(ctx context.Context, job *river.Job[RGLTeamArgs]) e
 		return errTeam
 	}
 
-	if team.TeamID == 0 { //nolint:nestif
-		w.limiter.Wait(ctx)
+	// Rosters change during a season, so known teams are fetched again once they are stale.
+	if team.ScrapedOn != nil && time.Since(*team.ScrapedOn) < rglRefreshInterval {
+		return nil
+	}
 
-		fetched, errFetch := rgl.Team(ctx, NewHTTPClient(), int64(job.Args.TeamID))
-		if errFetch != nil {
-			return errors.Join(errFetch, errFetchTeam)
-		}
+	w.limiter.Wait(ctx)
 
-		team.TeamID = fetched.TeamID
-		team.SeasonID = fetched.SeasonID
-		team.DivisionID = fetched.DivisionID
-		team.DivisionName = fetched.DivisionName
-		team.TeamLeader = steamid.New(fetched.TeamLeader)
-		team.Tag = fetched.Tag
-		team.TeamName = fetched.Name
-		team.FinalRank = fetched.FinalRank
-		team.CreatedAt = fetched.CreatedAt
-		team.UpdatedAt = fetched.UpdatedAt
-
-		record := newPlayerRecord(team.TeamLeader)
-		if err := w.database.playerGetOrCreate(ctx, team.TeamLeader, &record); err != nil {
-			return err
-		}
+	fetched, errFetch := rgl.Team(ctx, NewHTTPClient(), int64(job.Args.TeamID))
+	if errFetch != nil {
+		return errors.Join(errFetch, errFetchTeam)
+	}
+
+	now := time.Now()
+
+	team.TeamID = fetched.TeamID
+	team.SeasonID = fetched.SeasonID
+	team.DivisionID = fetched.DivisionID
+	team.DivisionName = fetched.DivisionName
+	team.TeamLeader = steamid.New(fetched.TeamLeader)
+	team.Tag = fetched.Tag
+	team.TeamName = fetched.Name
+	team.FinalRank = fetched.FinalRank
+	team.CreatedAt = fetched.CreatedAt
+	team.UpdatedAt = fetched.UpdatedAt
+	team.ScrapedOn = &now
+
+	record := newPlayerRecord(team.TeamLeader)
+	if err := w.database.playerGetOrCreate(ctx, team.TeamLeader, &record); err != nil {
+		return err
+	}
+
+	if err := w.database.rglTeamSave(ctx, team); err != nil {
+		return err
+	}
+
+	current := make(steamid.Collection, 0, len(fetched.Players))
 
-		if err := w.database.rglTeamInsert(ctx, team); err != nil {
+	for _, player := range fetched.Players {
+		memberRecord := newPlayerRecord(player.SteamID)
+		if err := w.database.playerGetOrCreate(ctx, player.SteamID, &memberRecord); err != nil {
 			return err
 		}
 
-		for _, player := range fetched.Players {
-			memberRecord := newPlayerRecord(player.SteamID)
-			if err := w.database.playerGetOrCreate(ctx, player.SteamID, &memberRecord); err != nil {
-				return err
-			}
-
-			if err := w.database.rglTeamMemberInsert(ctx, domain.RGLTeamMember{
-				TeamID:       team.TeamID,
-				Name:         player.Name,
-				IsTeamLeader: player.IsLeader,
-				SteamID:      player.SteamID,
-				JoinedAt:     player.JoinedAt,
-				LeftAt:       &player.UpdatedOn,
-			}); err != nil {
-				slog.Error("Failed to ensure team member", ErrAttr(err))
-			}
+		// The roster only lists current players, anyone missing from it is marked as left below.
+		current = append(current, player.SteamID)
+
+		if err := w.database.rglTeamMemberInsert(ctx, domain.RGLTeamMember{
+			TeamID:       team.TeamID,
+			Name:         player.Name,
+			IsTeamLeader: player.IsLeader,
+			SteamID:      player.SteamID,
+			JoinedAt:     player.JoinedAt,
+			LeftAt:       nil,
+		}); err != nil {
+			slog.Error("Failed to ensure team member", ErrAttr(err))
 		}
 	}
 
-	return nil
+	return w.database.rglTeamMembersLeft(ctx, team.TeamID, current, now)
 }
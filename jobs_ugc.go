@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/riverqueue/river"
+)
+
+// UGCBanArgs updates the ban list. Banned players are queued to seed the team and player crawl.
+type UGCBanArgs struct{}
+
+func (UGCBanArgs) Kind() string {
+	return string(KindUGCBan)
+}
+
+func (UGCBanArgs) InsertOpts() river.InsertOpts {
+	return ugcInsertOpts()
+}
+
+type UGCBanWorker struct {
+	river.WorkerDefaults[UGCBanArgs]
+	database *pgStore
+	client   *ugcClient
+}
+
+func (w *UGCBanWorker) Work(ctx context.Context, _ *river.Job[UGCBanArgs]) error {
+	bans, errBans := w.client.bans(ctx)
+	if errBans != nil {
+		return errBans
+	}
+
+	if err := w.database.ugcBansReplace(ctx, bans); err != nil {
+		return err
+	}
+
+	slog.Info("Updated UGC bans successfully", slog.Int("count", len(bans)))
+
+	newJobs := make([]river.InsertManyParams, len(bans))
+	for idx, ban := range bans {
+		newJobs[idx] = river.InsertManyParams{Args: UGCPlayerArgs{SteamID: ban.SteamID}}
+	}
+
+	return w.database.insertJobsTx(ctx, river.ClientFromContext[pgx.Tx](ctx), newJobs)
+}
+
+// UGCTeamArgs scrapes a team page, updating the team and its current roster. Members that have not been scraped
+// recently are queued so their full team history is known.
+type UGCTeamArgs struct {
+	TeamID int `json:"team_id"`
+}
+
+func (UGCTeamArgs) Kind() string {
+	return string(KindUGCTeam)
+}
+
+func (UGCTeamArgs) InsertOpts() river.InsertOpts {
+	return ugcInsertOpts()
+}
+
+type UGCTeamWorker struct {
+	river.WorkerDefaults[UGCTeamArgs]
+	database *pgStore
+	client   *ugcClient
+}
+
+func (w *UGCTeamWorker) Work(ctx context.Context, job *river.Job[UGCTeamArgs]) error {
+	existing, errExisting := w.database.ugcTeamGet(ctx, job.Args.TeamID)
+	if errExisting != nil && !errors.Is(errExisting, errDatabaseNoResults) {
+		return errExisting
+	}
+
+	if existing.ScrapedOn != nil && time.Since(*existing.ScrapedOn) < ugcRefreshInterval {
+		return nil
+	}
+
+	team, members, errTeam := w.client.team(ctx, job.Args.TeamID)
+	if errTeam != nil {
+		if errors.Is(errTeam, errUGCNotFound) || errors.Is(errTeam, errUGCTeam) {
+			slog.Warn("UGC team not found", slog.Int("team_id", job.Args.TeamID))
+
+			return nil
+		}
+
+		return errTeam
+	}
+
+	if err := w.database.ugcTeamSave(ctx, team); err != nil {
+		return err
+	}
+
+	newJobs := make([]river.InsertManyParams, len(members))
+
+	for idx, member := range members {
+		if err := w.database.ugcTeamMemberSave(ctx, member); err != nil {
+			return err
+		}
+
+		newJobs[idx] = river.InsertManyParams{Args: UGCPlayerArgs{SteamID: member.SteamID}}
+	}
+
+	return w.database.insertJobsTx(ctx, river.ClientFromContext[pgx.Tx](ctx), newJobs)
+}
+
+// UGCPlayerArgs scrapes a players page for their full team history. Teams that have not been scraped recently
+// are queued.
+type UGCPlayerArgs struct {
+	SteamID steamid.SteamID `json:"steam_id"`
+}
+
+func (UGCPlayerArgs) Kind() string {
+	return string(KindUGCPlayer)
+}
+
+func (UGCPlayerArgs) InsertOpts() river.InsertOpts {
+	return ugcInsertOpts()
+}
+
+type UGCPlayerWorker struct {
+	river.WorkerDefaults[UGCPlayerArgs]
+	database *pgStore
+	client   *ugcClient
+}
+
+func (w *UGCPlayerWorker) Work(ctx context.Context, job *river.Job[UGCPlayerArgs]) error {
+	sid := job.Args.SteamID
+
+	scrapedOn, errScraped := w.database.ugcPlayerScrapedOn(ctx, sid)
+	if errScraped != nil && !errors.Is(errScraped, errDatabaseNoResults) {
+		return errScraped
+	}
+
+	if time.Since(scrapedOn) < ugcRefreshInterval {
+		return nil
+	}
+
+	name, teams, errPlayer := w.client.player(ctx, sid)
+	if errPlayer != nil && !errors.Is(errPlayer, errUGCNotFound) {
+		return errPlayer
+	}
+
+	var teamIDs []int
+
+	for _, playerTeam := range teams {
+		if err := w.database.ugcTeamEnsure(ctx, playerTeam.team); err != nil {
+			return err
+		}
+
+		if err := w.database.ugcTeamMemberSave(ctx, playerTeam.member); err != nil {
+			return err
+		}
+
+		teamIDs = append(teamIDs, playerTeam.team.TeamID)
+	}
+
+	// Players without a ugc profile are still recorded, so they are not fetched again until the refresh interval.
+	if err := w.database.ugcPlayerScraped(ctx, sid, name); err != nil {
+		return err
+	}
+
+	staleIDs, errStale := w.database.ugcTeamsStale(ctx, teamIDs, time.Now().Add(-ugcRefreshInterval))
+	if errStale != nil {
+		return errStale
+	}
+
+	newJobs := make([]river.InsertManyParams, len(staleIDs))
+	for idx, teamID := range staleIDs {
+		newJobs[idx] = river.InsertManyParams{Args: UGCTeamArgs{TeamID: teamID}}
+	}
+
+	return w.database.insertJobsTx(ctx, river.ClientFromContext[pgx.Tx](ctx), newJobs)
+}
@@ -3,6 +3,7 @@
 import (
 	"context"
 	"log/slog"
+	"net/http"
 
 	"golang.org/x/time/rate"
 )
@@ -46,3 +47,16 @@ func (l *LimiterCustom) Wait(ctx context.Context) {
 		slog.Error("Limiter wait failed", ErrAttr(err))
 	}
 }
+
+// limitedTransport waits on the limiter before each request. It lets third party api clients, which make their own
+// requests, share a limiter with our own clients of the same api.
+type limitedTransport struct {
+	limiter *LimiterCustom
+	next    http.RoundTripper
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.limiter.Wait(req.Context())
+
+	return t.next.RoundTrip(req) //nolint:wrapcheck
+}
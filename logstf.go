@@ -5,11 +5,12 @@
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"regexp"
-	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -37,9 +38,6 @@ func NewLogsTFScraper(database *pgStore, config appConfig) (*SiteScraper, error)
 		return nil, errScraper
 	}
 
-	// Keep track of which log ids
-	var retries []int
-
 	scraper.OnError(func(response *colly.Response, err error) {
 		if response.StatusCode != http.StatusTooManyRequests {
 			scraper.log.Error("Request error", slog.String("url", response.Request.URL.String()), ErrAttr(err))
@@ -56,44 +54,19 @@ func NewLogsTFScraper(database *pgStore, config appConfig) (*SiteScraper, error)
 			DomainGlob: "*" + domainName,
 			Delay:      scraper.delay,
 		}); errLimit != nil {
-			panic(errScrapeLimit)
-		}
-
-		idStr := strings.TrimPrefix(response.Request.URL.Path, "/")
-		logID, errID := strconv.Atoi(idStr)
-		if errID != nil {
-			panic(errID)
-		}
-		if slices.Contains(retries, logID) {
-			scraper.log.Error("Failed retry", slog.String("url", response.Request.URL.String()), ErrAttr(err))
-
-			return
-		}
-
-		retries = append(retries, logID)
-
-		if errRetry := response.Request.Retry(); errRetry != nil {
-			scraper.log.Error("Retry error", slog.String("url", response.Request.URL.String()), ErrAttr(err))
+			scraper.log.Error("Failed to update limit", ErrAttr(errLimit))
 		}
 	})
 
 	return scraper, nil
 }
 
+// scrapeLogsTF fetches every log newer than the newest stored log. Logs that could not be stored are
+// recorded as gaps so that they can be retried later.
 func scrapeLogsTF(ctx context.Context, scraper *SiteScraper) error {
 	scraper.log.Info("Starting scrape job")
 
-	var (
-		startTime    = time.Now()
-		start        = true
-		totalCount   = 0
-		curCount     = 0
-		successCount = 0
-		errorCount   = 0
-		maxID        = 3680000
-		skipCount    = 0
-		lastCount    = time.Now()
-	)
+	startTime := time.Now()
 
 	minID, errID := scraper.db.logsTFNewestID(ctx)
 	if errID != nil {
@@ -104,79 +77,258 @@ func scrapeLogsTF(ctx context.Context, scraper *SiteScraper) error {
 		minID = 1
 	}
 
-	scraper.OnHTML("html", func(element *colly.HTMLElement) {
-		if start {
-			start = false
-			// Setup the queue
-			maxIDValue, errMaxID := getLogsTFMaxID(element.DOM)
-			if errMaxID != nil {
-				scraper.log.Error("No log id parsed, using default", ErrAttr(errMaxID))
+	crawl := newLogsTFCrawl(ctx, scraper)
+	crawl.onIndex = func(doc *goquery.Selection) {
+		// Setup the queue
+		maxIDValue, errMaxID := getLogsTFMaxID(doc)
+		if errMaxID != nil {
+			scraper.log.Error("No log id parsed, using default", ErrAttr(errMaxID))
 
-				return
+			return
+		}
+
+		crawl.maxID = maxIDValue
+
+		for i := range crawl.maxID {
+			if i <= minID {
+				continue
 			}
 
-			maxID = maxIDValue
+			crawl.queue(i)
+		}
+	}
+
+	// The index is checked first so that we can get the max ID
+	if errAdd := scraper.queue.AddURL("https://logs.tf"); errAdd != nil {
+		return errors.Join(errAdd, errAddQueue)
+	}
 
-			for i := range maxID {
-				if i <= minID {
-					continue
-				}
-				if errNext := scraper.queue.AddURL(fmt.Sprintf("https://logs.tf/%d", i)); errNext != nil {
-					scraper.log.Error("failed to add url to queue", ErrAttr(errNext))
-				}
+	errRun := scraper.queue.Run(scraper.Collector)
+
+	// Gaps are still saved when the job is cancelled, so that failures up until that point are not lost.
+	gaps := crawl.failures.gaps(time.Now(), nil)
+	if errGaps := scraper.db.logsTFGapsReplace(context.WithoutCancel(ctx), nil, gaps); errGaps != nil {
+		scraper.log.Error("Failed to save logs.tf gaps", ErrAttr(errGaps))
+	}
+
+	if errRun != nil {
+		return errors.Join(errRun, errRunQueue)
+	}
+
+	scraper.log.Debug("Completed scrape job",
+		slog.Duration("duration", time.Since(startTime)), slog.Int("gaps", len(gaps)))
+
+	return nil
+}
+
+// scrapeLogsTFIDs fetches the given logs, returning the ids which still could not be stored.
+func scrapeLogsTFIDs(ctx context.Context, scraper *SiteScraper, logIDs []int) (*logsTFFailures, error) {
+	crawl := newLogsTFCrawl(ctx, scraper)
+
+	for _, logID := range logIDs {
+		crawl.maxID = max(crawl.maxID, logID)
+		crawl.queue(logID)
+	}
+
+	if errRun := scraper.queue.Run(scraper.Collector); errRun != nil {
+		return crawl.failures, errors.Join(errRun, errRunQueue)
+	}
+
+	return crawl.failures, nil
+}
+
+// logsTFCrawl registers the handlers used to fetch and store logs, keeping track of the logs that failed.
+type logsTFCrawl struct {
+	ctx      context.Context //nolint:containedctx
+	scraper  *SiteScraper
+	failures *logsTFFailures
+	// onIndex is called with the logs.tf front page
+	onIndex func(doc *goquery.Selection)
+	maxID   int
+
+	mu           sync.Mutex
+	retried      map[int]bool
+	totalCount   int
+	curCount     int
+	successCount int
+	errorCount   int
+	skipCount    int
+	lastCount    time.Time
+}
+
+func newLogsTFCrawl(ctx context.Context, scraper *SiteScraper) *logsTFCrawl {
+	crawl := &logsTFCrawl{ //nolint:exhaustruct
+		ctx:       ctx,
+		scraper:   scraper,
+		failures:  newLogsTFFailures(),
+		maxID:     3680000,
+		retried:   map[int]bool{},
+		lastCount: time.Now(),
+	}
+
+	scraper.OnHTML("html", func(element *colly.HTMLElement) {
+		logID, errID := logsTFPathID(element.Request.URL.Path)
+		if errID != nil {
+			if crawl.onIndex != nil {
+				crawl.onIndex(element.DOM)
 			}
 
 			return
 		}
 
-		totalCount++
-		curCount++
-
 		match, errMatch := parseMatchFromDoc(element.DOM)
-		if errMatch != nil {
-			if errors.Is(errMatch, errLogID) || errors.Is(errMatch, errMissingExtended) {
-				skipCount++
+		crawl.saveMatch(logID, match, errMatch)
+	})
 
-				return
-			}
+	// The json api is preferred, the html page is only used when the json document can't be used.
+	scraper.OnResponse(func(response *colly.Response) {
+		if !strings.HasPrefix(response.Request.URL.Path, logsTFJSONPath) {
+			return
+		}
 
-			errorCount++
-			slog.Error("failed to parse document", slog.String("log", fmt.Sprintf("https://logs.tf/%d", match.LogID)), ErrAttr(errMatch))
+		logID, errID := logsTFPathID(response.Request.URL.Path)
+		if errID != nil {
+			scraper.log.Error("Failed to parse log id", slog.String("url", response.Request.URL.String()), ErrAttr(errID))
 
 			return
 		}
 
-		if err := scraper.db.logsTFMatchCreate(ctx, match); err != nil {
-			slog.Error("Failed to insert match", ErrAttr(err))
+		match, errMatch := parseMatchFromJSON(logID, response.Body)
+		if errMatch != nil {
+			slog.Warn("Failed to parse json, falling back to html", slog.Int("log_id", logID), ErrAttr(errMatch))
+			crawl.fallbackHTML(logID)
+
+			return
 		}
 
-		successCount++
+		crawl.saveMatch(logID, match, nil)
+	})
 
-		if time.Since(lastCount) > time.Minute {
-			slog.Info("Scrape stats",
-				slog.Int("total", totalCount), slog.Int("per_min", curCount),
-				slog.Int("success", successCount), slog.Int("error", errorCount), slog.Int("skip", skipCount),
-				slog.String("current", fmt.Sprintf("%d/%d", match.LogID, maxID)))
-			curCount = 0
-			lastCount = time.Now()
+	scraper.OnError(func(response *colly.Response, _ error) {
+		logID, errID := logsTFPathID(response.Request.URL.Path)
+		if errID != nil {
+			return
 		}
 
-		slog.Debug("Parsed page", slog.Int("log_id", match.LogID))
+		// Error responses are cached too, which would prevent both the retry and later attempts from working.
+		uncacheResponse(scraper.CacheDir, response.Request.URL.String())
+
+		switch {
+		case response.StatusCode == http.StatusTooManyRequests:
+			if crawl.retry(response.Request, logID) {
+				return
+			}
+
+			crawl.failures.add(logID, gapReasonRateLimited)
+		case response.StatusCode == http.StatusNotFound:
+			// Missing logs can't be found by html either.
+			crawl.failures.add(logID, gapReasonNotFound)
+		case strings.HasPrefix(response.Request.URL.Path, logsTFJSONPath):
+			crawl.fallbackHTML(logID)
+		default:
+			crawl.failures.add(logID, gapReasonRequest)
+		}
 	})
 
-	// The index is checked first so that we can get the max ID
-	if errAdd := scraper.queue.AddURL("https://logs.tf"); errAdd != nil {
-		return errors.Join(errAdd, errAddQueue)
+	return crawl
+}
+
+func (c *logsTFCrawl) queue(logID int) {
+	if errNext := c.scraper.queue.AddURL(fmt.Sprintf("https://logs.tf%s%d", logsTFJSONPath, logID)); errNext != nil {
+		c.scraper.log.Error("failed to add url to queue", ErrAttr(errNext))
 	}
+}
 
-	if errRun := scraper.queue.Run(scraper.Collector); errRun != nil {
-		return errors.Join(errRun, errRunQueue)
+func (c *logsTFCrawl) fallbackHTML(logID int) {
+	if errNext := c.scraper.queue.AddURL(fmt.Sprintf("https://logs.tf/%d", logID)); errNext != nil {
+		c.scraper.log.Error("failed to add url to queue", ErrAttr(errNext))
+		c.failures.add(logID, gapReasonRequest)
 	}
+}
 
-	scraper.log.Debug("Completed scrape job",
-		slog.Duration("duration", time.Since(startTime)))
+// retry retries a rate limited request once. Returns false if the request was already retried.
+func (c *logsTFCrawl) retry(request *colly.Request, logID int) bool {
+	c.mu.Lock()
+	if c.retried[logID] {
+		c.mu.Unlock()
+		c.scraper.log.Error("Failed retry", slog.String("url", request.URL.String()))
 
-	return nil
+		return false
+	}
+
+	c.retried[logID] = true
+	c.mu.Unlock()
+
+	if errRetry := request.Retry(); errRetry != nil {
+		c.scraper.log.Error("Retry error", slog.String("url", request.URL.String()), ErrAttr(errRetry))
+
+		return false
+	}
+
+	return true
+}
+
+func (c *logsTFCrawl) saveMatch(logID int, match *domain.LogsTFMatch, errMatch error) {
+	if errMatch == nil {
+		if err := c.scraper.db.logsTFMatchCreate(c.ctx, match); err != nil {
+			slog.Error("Failed to insert match", ErrAttr(err))
+			c.failures.add(logID, gapReasonInsert)
+
+			return
+		}
+
+		c.failures.remove(logID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.totalCount++
+	c.curCount++
+
+	if errMatch != nil {
+		switch {
+		case errors.Is(errMatch, errLogID):
+			c.skipCount++
+			c.failures.add(logID, gapReasonNotFound)
+		case errors.Is(errMatch, errMissingExtended):
+			c.skipCount++
+			c.failures.add(logID, gapReasonMissingExtended)
+		default:
+			c.errorCount++
+			c.failures.add(logID, gapReasonParse)
+
+			slog.Error("failed to parse document", slog.String("log", fmt.Sprintf("https://logs.tf/%d", logID)), ErrAttr(errMatch))
+		}
+
+		return
+	}
+
+	c.successCount++
+
+	if time.Since(c.lastCount) > time.Minute {
+		slog.Info("Scrape stats",
+			slog.Int("total", c.totalCount), slog.Int("per_min", c.curCount),
+			slog.Int("success", c.successCount), slog.Int("error", c.errorCount), slog.Int("skip", c.skipCount),
+			slog.Int("failures", c.failures.len()),
+			slog.String("current", fmt.Sprintf("%d/%d", match.LogID, c.maxID)))
+		c.curCount = 0
+		c.lastCount = time.Now()
+	}
+
+	slog.Debug("Parsed log", slog.Int("log_id", match.LogID))
+}
+
+const logsTFJSONPath = "/json/"
+
+// logsTFPathID parses the log id from either a /{id} or /json/{id} url path.
+func logsTFPathID(path string) (int, error) {
+	logID, errID := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(path, logsTFJSONPath), "/"))
+	if errID != nil {
+		return 0, errors.Join(errID, errLogID)
+	}
+
+	return logID, nil
 }
 
 func getLogsTFMaxID(doc *goquery.Selection) (int, error) {
@@ -224,9 +376,86 @@ func parseMatchFromDoc(doc *goquery.Selection) (*domain.LogsTFMatch, error) {
 
 	parseMedics(doc, logger, &match)
 
+	match.Source = domain.SourceLogsTF
+	match.Format = logsTFFormat(&match)
+
 	return &match, nil
 }
 
+// logsTFFormat guesses the format of a match from the size of the largest team. The team size is the total
+// time played by the team divided by the match length, so that substitutes are not counted twice. Logs without
+// class play times fall back to counting players.
+func logsTFFormat(match *domain.LogsTFMatch) domain.LogsTFFormat {
+	players := map[domain.Team]int{}
+	played := map[domain.Team]time.Duration{}
+
+	for _, player := range match.Players {
+		players[player.Team]++
+
+		for _, class := range player.Classes {
+			played[player.Team] += class.Played.Duration
+		}
+	}
+
+	teamSize := 0
+
+	for team, count := range players {
+		size := count
+		if played[team] > 0 && match.Duration.Duration > 0 {
+			size = int(math.Round(float64(played[team]) / float64(match.Duration.Duration)))
+		}
+
+		teamSize = max(teamSize, size)
+	}
+
+	switch teamSize {
+	case 2:
+		return domain.FormatUltiduo
+	case 4:
+		return domain.Format4v4
+	case 6:
+		return domain.Format6v6
+	case 7:
+		return domain.FormatProlander
+	case 9:
+		return domain.FormatHighlander
+	default:
+		return domain.FormatUnknown
+	}
+}
+
+const (
+	// logsTFSharedMaxPlayers limits how many players can be looked up together, a full highlander match.
+	logsTFSharedMaxPlayers = 18
+	// logsTFTeammatesMaxLimit is the max number of teammates returned.
+	logsTFTeammatesMaxLimit = 100
+)
+
+type logsTFMatchResult int
+
+const (
+	resultTie logsTFMatchResult = iota
+	resultWin
+	resultLoss
+)
+
+// logsTFResult returns the result of the match from the perspective of the team.
+func logsTFResult(match domain.LogsTFMatchInfo, team domain.Team) logsTFMatchResult {
+	own, other := match.ScoreRED, match.ScoreBLU
+	if team == domain.BLU {
+		own, other = other, own
+	}
+
+	switch {
+	case own > other:
+		return resultWin
+	case own < other:
+		return resultLoss
+	default:
+		return resultTie
+	}
+}
+
 func parseLogID(doc *goquery.Selection, match *domain.LogsTFMatch) error {
 	attr, ok := doc.Find("meta[property='og:url']").Attr("content")
 	if !ok {
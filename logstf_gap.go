@@ -0,0 +1,198 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// logsTFGapMaxAttempts is how many times a gap is retried before being marked as failed.
+	logsTFGapMaxAttempts = 5
+	logsTFGapBaseDelay   = time.Hour
+	logsTFGapMaxDelay    = 7 * 24 * time.Hour
+	// logsTFGapMaxSize limits the size of backfilled ranges so that a single retry job stays short.
+	logsTFGapMaxSize = 1000
+	// logsTFGapBatchSize is how many gaps are retried per job.
+	logsTFGapBatchSize = 10
+)
+
+type logsTFGapStatus string
+
+const (
+	gapPending logsTFGapStatus = "pending"
+	gapMissing logsTFGapStatus = "missing"
+	gapFailed  logsTFGapStatus = "failed"
+)
+
+type logsTFGapReason string
+
+const (
+	gapReasonNotFound        logsTFGapReason = "not_found"
+	gapReasonMissingExtended logsTFGapReason = "missing_extended"
+	gapReasonParse           logsTFGapReason = "parse"
+	gapReasonRateLimited     logsTFGapReason = "rate_limited"
+	gapReasonRequest         logsTFGapReason = "request"
+	gapReasonInsert          logsTFGapReason = "insert"
+	gapReasonBackfill        logsTFGapReason = "backfill"
+)
+
+// logsTFGap is an inclusive range of log ids which could not be stored.
+type logsTFGap struct {
+	GapID       int64
+	StartID     int
+	EndID       int
+	Status      logsTFGapStatus
+	Reason      logsTFGapReason
+	Attempts    int
+	NextAttempt time.Time
+	CreatedOn   time.Time
+	UpdatedOn   time.Time
+}
+
+func (g logsTFGap) contains(logID int) bool {
+	return logID >= g.StartID && logID <= g.EndID
+}
+
+// ids returns every log id in the range.
+func (g logsTFGap) ids() []int {
+	ids := make([]int, 0, g.EndID-g.StartID+1)
+	for logID := g.StartID; logID <= g.EndID; logID++ {
+		ids = append(ids, logID)
+	}
+
+	return ids
+}
+
+// retry builds the gaps for the ids in the range that failed again. Logs which don't exist are not retried
+// automatically and gaps that have used all their attempts are marked as failed.
+func (g logsTFGap) retry(failures *logsTFFailures, now time.Time) []logsTFGap {
+	attempts := g.Attempts + 1
+
+	gaps := failures.gaps(now, func(logID int) bool { return g.contains(logID) })
+	for idx := range gaps {
+		gaps[idx].Attempts = attempts
+		gaps[idx].CreatedOn = g.CreatedOn
+		gaps[idx].NextAttempt = now.Add(logsTFGapDelay(attempts))
+
+		if attempts >= logsTFGapMaxAttempts {
+			gaps[idx].Status = gapFailed
+		}
+	}
+
+	return gaps
+}
+
+// logsTFGapDelay doubles the delay between attempts, up to logsTFGapMaxDelay.
+func logsTFGapDelay(attempts int) time.Duration {
+	delay := logsTFGapMaxDelay
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	if attempts <= 10 {
+		delay = min(logsTFGapBaseDelay<<(attempts-1), logsTFGapMaxDelay)
+	}
+
+	return delay
+}
+
+// newLogsTFBackfill splits the inclusive range into pending gaps of at most logsTFGapMaxSize ids.
+func newLogsTFBackfill(fromID int, toID int, now time.Time) []logsTFGap {
+	var gaps []logsTFGap
+
+	for startID := fromID; startID <= toID; startID += logsTFGapMaxSize {
+		gaps = append(gaps, logsTFGap{ //nolint:exhaustruct
+			StartID:     startID,
+			EndID:       min(startID+logsTFGapMaxSize-1, toID),
+			Status:      gapPending,
+			Reason:      gapReasonBackfill,
+			NextAttempt: now,
+			CreatedOn:   now,
+			UpdatedOn:   now,
+		})
+	}
+
+	return gaps
+}
+
+// logsTFFailures tracks the log ids that could not be stored during a crawl. Ids are removed again when a
+// later attempt, such as the html fallback or a rate limit retry, succeeds.
+type logsTFFailures struct {
+	mu  sync.Mutex
+	ids map[int]logsTFGapReason
+}
+
+func newLogsTFFailures() *logsTFFailures {
+	return &logsTFFailures{ids: map[int]logsTFGapReason{}} //nolint:exhaustruct
+}
+
+func (f *logsTFFailures) add(logID int, reason logsTFGapReason) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.ids[logID] = reason
+}
+
+func (f *logsTFFailures) remove(logID int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.ids, logID)
+}
+
+func (f *logsTFFailures) len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.ids)
+}
+
+// gaps merges consecutive ids with the same reason into ranges. When filter is set, only the ids it
+// accepts are included.
+func (f *logsTFFailures) gaps(now time.Time, filter func(logID int) bool) []logsTFGap {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	logIDs := make([]int, 0, len(f.ids))
+
+	for logID := range f.ids {
+		if filter == nil || filter(logID) {
+			logIDs = append(logIDs, logID)
+		}
+	}
+
+	slices.Sort(logIDs)
+
+	var gaps []logsTFGap
+
+	for _, logID := range logIDs {
+		reason := f.ids[logID]
+
+		if len(gaps) > 0 {
+			last := &gaps[len(gaps)-1]
+			if last.EndID == logID-1 && last.Reason == reason {
+				last.EndID = logID
+
+				continue
+			}
+		}
+
+		status := gapPending
+		if reason == gapReasonNotFound {
+			status = gapMissing
+		}
+
+		gaps = append(gaps, logsTFGap{ //nolint:exhaustruct
+			StartID:     logID,
+			EndID:       logID,
+			Status:      status,
+			Reason:      reason,
+			NextAttempt: now.Add(logsTFGapBaseDelay),
+			CreatedOn:   now,
+			UpdatedOn:   now,
+		})
+	}
+
+	return gaps
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogsTFFailuresGaps(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	failures := newLogsTFFailures()
+	for _, logID := range []int{10, 11, 12, 14, 15} {
+		failures.add(logID, gapReasonParse)
+	}
+
+	failures.add(13, gapReasonNotFound)
+	failures.add(20, gapReasonRateLimited)
+	failures.add(21, gapReasonRateLimited)
+	// Succeeded on a later attempt
+	failures.remove(21)
+
+	gaps := failures.gaps(now, nil)
+	require.Len(t, gaps, 4)
+	require.Equal(t, logsTFGap{ //nolint:exhaustruct
+		StartID:     10,
+		EndID:       12,
+		Status:      gapPending,
+		Reason:      gapReasonParse,
+		NextAttempt: now.Add(logsTFGapBaseDelay),
+		CreatedOn:   now,
+		UpdatedOn:   now,
+	}, gaps[0])
+	require.Equal(t, gapMissing, gaps[1].Status)
+	require.Equal(t, 13, gaps[1].StartID)
+	require.Equal(t, [2]int{14, 15}, [2]int{gaps[2].StartID, gaps[2].EndID})
+	require.Equal(t, [2]int{20, 20}, [2]int{gaps[3].StartID, gaps[3].EndID})
+}
+
+func TestLogsTFGapRetry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-24 * time.Hour)
+
+	gap := logsTFGap{GapID: 1, StartID: 100, EndID: 104, Status: gapPending, Attempts: 1, CreatedOn: created} //nolint:exhaustruct
+
+	failures := newLogsTFFailures()
+	failures.add(101, gapReasonRequest)
+	failures.add(102, gapReasonRequest)
+	// Outside the gap
+	failures.add(200, gapReasonRequest)
+
+	retries := gap.retry(failures, now)
+	require.Len(t, retries, 1)
+	require.Equal(t, 101, retries[0].StartID)
+	require.Equal(t, 102, retries[0].EndID)
+	require.Equal(t, 2, retries[0].Attempts)
+	require.Equal(t, gapPending, retries[0].Status)
+	require.Equal(t, now.Add(2*time.Hour), retries[0].NextAttempt)
+	require.Equal(t, created, retries[0].CreatedOn)
+
+	gap.Attempts = logsTFGapMaxAttempts - 1
+	require.Equal(t, gapFailed, gap.retry(failures, now)[0].Status)
+
+	require.Empty(t, gap.retry(newLogsTFFailures(), now))
+}
+
+func TestLogsTFGapDelay(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, time.Hour, logsTFGapDelay(1))
+	require.Equal(t, 4*time.Hour, logsTFGapDelay(3))
+	require.Equal(t, logsTFGapMaxDelay, logsTFGapDelay(9))
+	require.Equal(t, logsTFGapMaxDelay, logsTFGapDelay(100))
+}
+
+func TestLogsTFBackfill(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	gaps := newLogsTFBackfill(1, 2500, now)
+	require.Len(t, gaps, 3)
+	require.Equal(t, [2]int{1, 1000}, [2]int{gaps[0].StartID, gaps[0].EndID})
+	require.Equal(t, [2]int{2001, 2500}, [2]int{gaps[2].StartID, gaps[2].EndID})
+	require.Equal(t, gapPending, gaps[0].Status)
+	require.Equal(t, gapReasonBackfill, gaps[0].Reason)
+	require.Equal(t, now, gaps[0].NextAttempt)
+
+	require.Len(t, newLogsTFBackfill(5, 5, now), 1)
+	require.Len(t, gaps[1].ids(), logsTFGapMaxSize)
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+)
+
+var (
+	errLogsTFJSONDecode  = errors.New("failed to decode logs.tf json")
+	errLogsTFJSONFailure = errors.New("logs.tf json returned unsuccessful response")
+)
+
+// logsTFJSON is the document returned by https://logs.tf/json/{id}. Only the fields we store are decoded.
+type logsTFJSON struct {
+	Success *bool                       `json:"success"`
+	Error   string                      `json:"error"`
+	Length  int                         `json:"length"`
+	Teams   map[string]logsTFJSONTeam   `json:"teams"`
+	Players map[string]logsTFJSONPlayer `json:"players"`
+	Names   map[string]string           `json:"names"`
+	Rounds  []logsTFJSONRound           `json:"rounds"`
+	Info    logsTFJSONInfo              `json:"info"`
+	Chat    []logsTFJSONChat            `json:"chat"`
+	Streaks []logsTFJSONStreak          `json:"killstreaks"`
+}
+
+type logsTFJSONTeam struct {
+	Score  int `json:"score"`
+	Kills  int `json:"kills"`
+	Damage int `json:"dmg"`
+	Ubers  int `json:"ubers"`
+}
+
+type logsTFJSONInfo struct {
+	Map         string `json:"map"`
+	Title       string `json:"title"`
+	Date        int64  `json:"date"`
+	TotalLength int    `json:"total_length"`
+	HasDT       bool   `json:"hasDT"`
+	HasHSHit    bool   `json:"hasHS_hit"`
+}
+
+type logsTFJSONPlayer struct {
+	Team         string                 `json:"team"`
+	ClassStats   []logsTFJSONClassStats `json:"class_stats"`
+	Kills        int                    `json:"kills"`
+	Deaths       int                    `json:"deaths"`
+	Assists      int                    `json:"assists"`
+	KAPD         logsTFJSONFloat        `json:"kapd"`
+	KPD          logsTFJSONFloat        `json:"kpd"`
+	Damage       int64                  `json:"dmg"`
+	DamageTaken  int                    `json:"dt"`
+	HealingTaken int                    `json:"hr"`
+	Airshots     int                    `json:"as"`
+	DAPM         int                    `json:"dapm"`
+	Ubers        int                    `json:"ubers"`
+	UberTypes    map[string]int         `json:"ubertypes"`
+	Drops        int                    `json:"drops"`
+	Medkits      int                    `json:"medkits"`
+	Backstabs    int                    `json:"backstabs"`
+	Headshots    int                    `json:"headshots"`
+	HeadshotsHit int                    `json:"headshots_hit"`
+	Heal         int64                  `json:"heal"`
+	Caps         int                    `json:"cpc"`
+	MedicStats   *logsTFJSONMedicStats  `json:"medicstats"`
+}
+
+type logsTFJSONClassStats struct {
+	Type      string                      `json:"type"`
+	Kills     int                         `json:"kills"`
+	Assists   int                         `json:"assists"`
+	Deaths    int                         `json:"deaths"`
+	Damage    int                         `json:"dmg"`
+	TotalTime int                         `json:"total_time"`
+	Weapons   map[string]logsTFJSONWeapon `json:"weapon"`
+}
+
+// logsTFJSONWeapon holds the per weapon stats. Older logs only contain the kill count as a plain number.
+type logsTFJSONWeapon struct {
+	Kills  int `json:"kills"`
+	Damage int `json:"dmg"`
+	Shots  int `json:"shots"`
+	Hits   int `json:"hits"`
+}
+
+func (w *logsTFJSONWeapon) UnmarshalJSON(data []byte) error {
+	if kills, errKills := strconv.Atoi(string(data)); errKills == nil {
+		w.Kills = kills
+
+		return nil
+	}
+
+	type weapon logsTFJSONWeapon
+
+	var value weapon
+	if err := json.Unmarshal(data, &value); err != nil {
+		return errors.Join(err, errLogsTFJSONDecode)
+	}
+
+	*w = logsTFJSONWeapon(value)
+
+	return nil
+}
+
+type logsTFJSONMedicStats struct {
+	AdvantagesLost           int     `json:"advantages_lost"`
+	BiggestAdvantageLost     float64 `json:"biggest_advantage_lost"`
+	DeathsWith9599Uber       int     `json:"deaths_with_95_99_uber"`
+	DeathsWithin20sAfterUber int     `json:"deaths_within_20s_after_uber"`
+	AvgTimeToBuild           float64 `json:"avg_time_to_build"`
+	AvgTimeBeforeUsing       float64 `json:"avg_time_before_using"`
+	AvgUberLength            float64 `json:"avg_uber_length"`
+}
+
+type logsTFJSONRound struct {
+	Length   int                       `json:"length"`
+	FirstCap string                    `json:"firstcap"`
+	Team     map[string]logsTFJSONTeam `json:"team"`
+}
+
+type logsTFJSONChat struct {
+	SteamID string `json:"steamid"`
+	Name    string `json:"name"`
+	Message string `json:"msg"`
+}
+
+type logsTFJSONStreak struct {
+	SteamID string `json:"steamid"`
+	Streak  int    `json:"streak"`
+	Time    int    `json:"time"`
+}
+
+// logsTFJSONFloat handles the ratios which are sent as strings in newer logs and as numbers in older ones.
+type logsTFJSONFloat float32
+
+func (f *logsTFJSONFloat) UnmarshalJSON(data []byte) error {
+	value, errParse := strconv.ParseFloat(strings.Trim(string(data), `"`), 32)
+	if errParse != nil {
+		return errors.Join(errParse, errLogsTFJSONDecode)
+	}
+
+	*f = logsTFJSONFloat(value)
+
+	return nil
+}
+
+// parseMatchFromJSON parses the logs.tf json api document into a domain.LogsTFMatch. Unlike the html
+// version, this includes the per class weapon stats, chat and killstreaks.
+func parseMatchFromJSON(logID int, body []byte) (*domain.LogsTFMatch, error) {
+	var doc logsTFJSON
+	if errDecode := json.Unmarshal(body, &doc); errDecode != nil {
+		return nil, errors.Join(errDecode, errLogsTFJSONDecode)
+	}
+
+	if doc.Success != nil && !*doc.Success {
+		return nil, fmt.Errorf("%w: %s", errLogsTFJSONFailure, doc.Error)
+	}
+
+	length := doc.Length
+	if length == 0 {
+		length = doc.Info.TotalLength
+	}
+
+	match := domain.LogsTFMatch{
+		LogsTFMatchInfo: domain.LogsTFMatchInfo{
+			LogID:        logID,
+			Source:       domain.SourceLogsTF,
+			Title:        doc.Info.Title,
+			Map:          doc.Info.Map,
+			Format:       domain.FormatUnknown,
+			Views:        0,
+			Duration:     domain.JSONDuration{Duration: time.Duration(length) * time.Second},
+			ScoreRED:     doc.Teams["Red"].Score,
+			ScoreBLU:     doc.Teams["Blue"].Score,
+			CreatedOn:    time.Unix(doc.Info.Date, 0).UTC(),
+			LogFormatOld: !doc.Info.HasDT,
+			Checksum:     "",
+		},
+		Rounds:      parseJSONRounds(logID, doc.Rounds),
+		Players:     nil,
+		Medics:      nil,
+		Chat:        parseJSONChat(logID, doc.Chat),
+		Killstreaks: parseJSONKillstreaks(logID, doc.Streaks),
+	}
+
+	minutes := float64(length) / 60
+
+	for playerID, stats := range doc.Players {
+		sid := steamid.New(playerID)
+		if !sid.Valid() {
+			continue
+		}
+
+		player := parseJSONPlayer(logID, sid, doc.Names[playerID], stats, minutes, doc.Info.HasHSHit)
+		match.Players = append(match.Players, player)
+
+		if medic, isMedic := parseJSONMedic(logID, sid, player, stats, minutes); isMedic {
+			match.Medics = append(match.Medics, medic)
+		}
+	}
+
+	// Map ordering is random, keep the output stable.
+	slices.SortFunc(match.Players, func(a, b domain.LogsTFPlayer) int {
+		return cmp.Or(cmp.Compare(b.Team, a.Team), cmp.Compare(a.SteamID.Int64(), b.SteamID.Int64()))
+	})
+
+	slices.SortFunc(match.Medics, func(a, b domain.LogsTFMedic) int {
+		return cmp.Compare(b.Healing, a.Healing)
+	})
+
+	match.Format = logsTFFormat(&match)
+
+	return &match, nil
+}
+
+func jsonTeam(team string) domain.Team {
+	switch team {
+	case "Blue":
+		return domain.BLU
+	case "Red":
+		return domain.RED
+	default:
+		return 0
+	}
+}
+
+func perMinute(value int64, minutes float64) int {
+	if minutes <= 0 {
+		return 0
+	}
+
+	return int(float64(value) / minutes)
+}
+
+func secondsDuration(seconds float64) domain.JSONDuration {
+	return domain.JSONDuration{Duration: time.Duration(seconds * float64(time.Second))}
+}
+
+func parseJSONPlayer(logID int, sid steamid.SteamID, name string, stats logsTFJSONPlayer, minutes float64,
+	hasHSHit bool,
+) domain.LogsTFPlayer {
+	headshots := stats.Headshots
+	if hasHSHit {
+		headshots = stats.HeadshotsHit
+	}
+
+	player := domain.LogsTFPlayer{
+		LogID:        logID,
+		SteamID:      sid,
+		Team:         jsonTeam(stats.Team),
+		Name:         name,
+		Classes:      nil,
+		Kills:        stats.Kills,
+		Assists:      stats.Assists,
+		Deaths:       stats.Deaths,
+		Damage:       stats.Damage,
+		DPM:          stats.DAPM,
+		KAD:          float32(stats.KAPD),
+		KD:           float32(stats.KPD),
+		DamageTaken:  stats.DamageTaken,
+		DTM:          perMinute(int64(stats.DamageTaken), minutes),
+		HealthPacks:  stats.Medkits,
+		Backstabs:    stats.Backstabs,
+		Headshots:    headshots,
+		Airshots:     stats.Airshots,
+		Caps:         stats.Caps,
+		HealingTaken: stats.HealingTaken,
+	}
+
+	for _, classStats := range stats.ClassStats {
+		class := domain.LogsTFPlayerClass{
+			LogID:   logID,
+			SteamID: sid,
+			Class:   stringToClass(classStats.Type),
+			Played:  domain.JSONDuration{Duration: time.Duration(classStats.TotalTime) * time.Second},
+			Kills:   classStats.Kills,
+			Assists: classStats.Assists,
+			Deaths:  classStats.Deaths,
+			Damage:  classStats.Damage,
+			Weapons: nil,
+		}
+
+		for weaponName, weaponStats := range classStats.Weapons {
+			accuracy := 0
+			if weaponStats.Shots > 0 {
+				accuracy = weaponStats.Hits * 100 / weaponStats.Shots
+			}
+
+			class.Weapons = append(class.Weapons, domain.LogsTFPlayerClassWeapon{
+				LogID:    logID,
+				SteamID:  sid,
+				Class:    class.Class,
+				Weapon:   weaponName,
+				Kills:    weaponStats.Kills,
+				Damage:   weaponStats.Damage,
+				Accuracy: accuracy,
+			})
+		}
+
+		slices.SortFunc(class.Weapons, func(a, b domain.LogsTFPlayerClassWeapon) int {
+			return cmp.Compare(a.Weapon, b.Weapon)
+		})
+
+		player.Classes = append(player.Classes, class)
+	}
+
+	return player
+}
+
+// parseJSONMedic builds the medic stats for players that played medic and healed.
+func parseJSONMedic(logID int, sid steamid.SteamID, player domain.LogsTFPlayer, stats logsTFJSONPlayer,
+	minutes float64,
+) (domain.LogsTFMedic, bool) {
+	playedMedic := slices.ContainsFunc(player.Classes, func(class domain.LogsTFPlayerClass) bool {
+		return class.Class == domain.Medic
+	})
+
+	if !playedMedic || stats.Heal == 0 {
+		return domain.LogsTFMedic{}, false //nolint:exhaustruct
+	}
+
+	medic := domain.LogsTFMedic{ //nolint:exhaustruct
+		LogID:           logID,
+		SteamID:         sid,
+		Healing:         stats.Heal,
+		HealingPerMin:   perMinute(stats.Heal, minutes),
+		ChargesKritz:    stats.UberTypes["kritzkrieg"],
+		ChargesQuickfix: stats.UberTypes["quickfix"],
+		ChargesMedigun:  stats.UberTypes["medigun"],
+		ChargesVacc:     stats.UberTypes["vaccinator"],
+		Drops:           stats.Drops,
+	}
+
+	// Very old logs have no breakdown of the charge types.
+	if len(stats.UberTypes) == 0 {
+		medic.ChargesMedigun = stats.Ubers
+	}
+
+	if medicStats := stats.MedicStats; medicStats != nil {
+		medic.AvgTimeBuild = secondsDuration(medicStats.AvgTimeToBuild)
+		medic.AvgTimeUse = secondsDuration(medicStats.AvgTimeBeforeUsing)
+		medic.NearFullDeath = medicStats.DeathsWith9599Uber
+		medic.AvgUberLen = secondsDuration(medicStats.AvgUberLength)
+		medic.DeathAfterCharge = medicStats.DeathsWithin20sAfterUber
+		medic.MajorAdvLost = medicStats.AdvantagesLost
+		medic.BiggestAdvLost = secondsDuration(medicStats.BiggestAdvantageLost)
+	}
+
+	return medic, true
+}
+
+func parseJSONRounds(logID int, rounds []logsTFJSONRound) []domain.LogsTFRound {
+	var results []domain.LogsTFRound
+
+	for idx, round := range rounds {
+		blu, red := round.Team["Blue"], round.Team["Red"]
+
+		results = append(results, domain.LogsTFRound{
+			LogID:     logID,
+			Round:     idx + 1,
+			Length:    domain.JSONDuration{Duration: time.Duration(round.Length) * time.Second},
+			ScoreBLU:  blu.Score,
+			ScoreRED:  red.Score,
+			KillsBLU:  blu.Kills,
+			KillsRED:  red.Kills,
+			UbersBLU:  blu.Ubers,
+			UbersRED:  red.Ubers,
+			DamageBLU: blu.Damage,
+			DamageRED: red.Damage,
+			MidFight:  jsonTeam(round.FirstCap),
+		})
+	}
+
+	return results
+}
+
+func parseJSONChat(logID int, messages []logsTFJSONChat) []domain.LogsTFChat {
+	var chat []domain.LogsTFChat
+
+	for _, message := range messages {
+		// Console messages have no steam id, so they are left as the zero value.
+		var sid steamid.SteamID
+		if message.SteamID != "Console" {
+			sid = steamid.New(message.SteamID)
+		}
+
+		chat = append(chat, domain.LogsTFChat{
+			LogID:   logID,
+			SteamID: sid,
+			Name:    message.Name,
+			Message: message.Message,
+		})
+	}
+
+	return chat
+}
+
+func parseJSONKillstreaks(logID int, streaks []logsTFJSONStreak) []domain.LogsTFKillstreak {
+	var killstreaks []domain.LogsTFKillstreak
+
+	for _, streak := range streaks {
+		sid := steamid.New(streak.SteamID)
+		if !sid.Valid() {
+			continue
+		}
+
+		killstreaks = append(killstreaks, domain.LogsTFKillstreak{
+			LogID:   logID,
+			SteamID: sid,
+			Streak:  streak.Streak,
+			Time:    domain.JSONDuration{Duration: time.Duration(streak.Time) * time.Second},
+		})
+	}
+
+	return killstreaks
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/leighmacdonald/bd-api/domain"
+)
+
+const (
+	// leaderboardAllFormats is the logstf_leaderboard format that includes every format.
+	leaderboardAllFormats = "all"
+	leaderboardMinGames   = 20
+	leaderboardMaxLimit   = 250
+)
+
+// logsTFLeaderboardStat is a stat that players can be ranked by.
+type logsTFLeaderboardStat string
+
+const (
+	statDPM      logsTFLeaderboardStat = "dpm"
+	statKD       logsTFLeaderboardStat = "kd"
+	statAirshots logsTFLeaderboardStat = "airshots"
+	statHeals    logsTFLeaderboardStat = "heals"
+	statUbers    logsTFLeaderboardStat = "ubers"
+)
+
+// expr returns the sql expression used to calculate the stat from the logstf_leaderboard totals. Played time is
+// stored in nanoseconds.
+func (s logsTFLeaderboardStat) expr() (string, bool) {
+	switch s {
+	case statDPM:
+		return "lb.damage / (lb.played / 60000000000.0)", true
+	case statKD:
+		return "lb.kills::float / greatest(lb.deaths, 1)", true
+	case statAirshots:
+		// Per game
+		return "lb.airshots::float / lb.games", true
+	case statHeals:
+		return "lb.healing / (lb.played / 60000000000.0)", true
+	case statUbers:
+		// Per game
+		return "lb.ubers::float / lb.games", true
+	default:
+		return "", false
+	}
+}
+
+// medicOnly returns true for stats that are only tracked for medics.
+func (s logsTFLeaderboardStat) medicOnly() bool {
+	return s == statHeals || s == statUbers
+}
+
+// parsePlayerClass parses either a class number or name, eg: 3, soldier, demo.
+func parsePlayerClass(value string) (domain.PlayerClass, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if classID, errClass := strconv.Atoi(value); errClass == nil {
+		if classID < int(domain.Scout) || classID > int(domain.Spy) {
+			return domain.Spectator, false
+		}
+
+		return domain.PlayerClass(classID), true
+	}
+
+	switch value {
+	case "demo":
+		return domain.Demo, true
+	case "heavy":
+		return domain.Heavy, true
+	case "engy", "engi":
+		return domain.Engineer, true
+	}
+
+	class := stringToClass(value)
+
+	return class, class != domain.Spectator
+}
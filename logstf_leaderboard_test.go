@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlayerClass(t *testing.T) {
+	t.Parallel()
+
+	for value, expected := range map[string]domain.PlayerClass{
+		"3":            domain.Pyro,
+		"soldier":      domain.Soldier,
+		" Demo ":       domain.Demo,
+		"demoman":      domain.Demo,
+		"heavy":        domain.Heavy,
+		"heavyweapons": domain.Heavy,
+		"engy":         domain.Engineer,
+	} {
+		class, valid := parsePlayerClass(value)
+		require.True(t, valid, value)
+		require.Equal(t, expected, class, value)
+	}
+
+	for _, value := range []string{"", "0", "10", "-1", "spectator", "pyr0"} {
+		_, valid := parsePlayerClass(value)
+		require.False(t, valid, value)
+	}
+}
+
+func TestLeaderboardStat(t *testing.T) {
+	t.Parallel()
+
+	for _, stat := range []logsTFLeaderboardStat{statDPM, statKD, statAirshots, statHeals, statUbers} {
+		_, valid := stat.expr()
+		require.True(t, valid, stat)
+	}
+
+	_, valid := logsTFLeaderboardStat("lb.kills; drop table logstf").expr()
+	require.False(t, valid)
+
+	require.True(t, statHeals.medicOnly())
+	require.False(t, statDPM.medicOnly())
+}
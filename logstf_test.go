@@ -3,6 +3,7 @@
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/leighmacdonald/bd-api/domain"
@@ -166,3 +167,246 @@ func TestLogsTFDetailsOld(t *testing.T) {
 		HealingTaken: 0,
 	}, match.Players[0])
 }
+
+func TestLogsTFDetailsJSON(t *testing.T) {
+	body, errRead := os.ReadFile("testdata/logstf_detail.json")
+	require.NoError(t, errRead)
+
+	match, errDetails := parseMatchFromJSON(3124689, body)
+	require.NoError(t, errDetails)
+
+	require.Equal(t, 3124689, match.LogID)
+	require.Equal(t, "Qixalite Booking: RED vs BLU", match.Title)
+	require.Equal(t, "koth_cascade_rc2", match.Map)
+	require.Equal(t, "16m56s", match.Duration.String())
+	require.Equal(t, "2022-02-05 06:39:42 +0000 UTC", match.CreatedOn.String())
+	require.Equal(t, 0, match.ScoreBLU)
+	require.Equal(t, 3, match.ScoreRED)
+	require.False(t, match.LogFormatOld)
+	require.Len(t, match.Players, 4)
+	require.EqualValues(t, domain.LogsTFPlayer{
+		LogID:   3124689,
+		SteamID: steamid.New(76561198164892406),
+		Team:    domain.BLU,
+		Name:    "var",
+		Classes: []domain.LogsTFPlayerClass{
+			{
+				LogID:   3124689,
+				SteamID: steamid.New(76561198164892406),
+				Class:   domain.Scout,
+				Played:  domain.JSONDuration{Duration: 1016000000000},
+				Kills:   12,
+				Assists: 10,
+				Deaths:  14,
+				Damage:  5078,
+				Weapons: []domain.LogsTFPlayerClassWeapon{
+					{
+						LogID:    3124689,
+						SteamID:  steamid.New(76561198164892406),
+						Class:    domain.Scout,
+						Weapon:   "pistol_scout",
+						Kills:    2,
+						Damage:   476,
+						Accuracy: 45,
+					},
+					{
+						LogID:    3124689,
+						SteamID:  steamid.New(76561198164892406),
+						Class:    domain.Scout,
+						Weapon:   "scattergun",
+						Kills:    10,
+						Damage:   4602,
+						Accuracy: 60,
+					},
+				},
+			},
+		},
+		Kills:        12,
+		Assists:      10,
+		Deaths:       14,
+		Damage:       5078,
+		DPM:          299,
+		KAD:          1.6,
+		KD:           0.9,
+		DamageTaken:  5399,
+		DTM:          318,
+		HealthPacks:  16,
+		Backstabs:    0,
+		Headshots:    0,
+		Airshots:     0,
+		Caps:         3,
+		HealingTaken: 4210,
+	}, match.Players[1])
+
+	// Classes are kept in the order they were played, with weapons from both.
+	soldier := match.Players[2]
+	require.Equal(t, "rocket man", soldier.Name)
+	require.Equal(t, domain.RED, soldier.Team)
+	require.Len(t, soldier.Classes, 2)
+	require.Equal(t, domain.Soldier, soldier.Classes[0].Class)
+	require.Equal(t, domain.Pyro, soldier.Classes[1].Class)
+	require.Len(t, soldier.Classes[0].Weapons, 2)
+	require.Equal(t, 4, soldier.Airshots)
+
+	// Headshots that hit are used when the log has them.
+	require.Equal(t, 6, match.Players[3].Headshots)
+
+	require.Len(t, match.Medics, 1)
+	require.EqualValues(t, domain.LogsTFMedic{
+		LogID:            3124689,
+		SteamID:          steamid.New(76561198113244106),
+		Healing:          17368,
+		HealingPerMin:    1025,
+		ChargesKritz:     0,
+		ChargesQuickfix:  0,
+		ChargesMedigun:   4,
+		ChargesVacc:      0,
+		Drops:            2,
+		AvgTimeBuild:     domain.JSONDuration{Duration: 42000000000},
+		AvgTimeUse:       domain.JSONDuration{Duration: 28000000000},
+		NearFullDeath:    1,
+		AvgUberLen:       domain.JSONDuration{Duration: 7200000000},
+		DeathAfterCharge: 0,
+		MajorAdvLost:     1,
+		BiggestAdvLost:   domain.JSONDuration{Duration: 39000000000},
+	}, match.Medics[0])
+
+	require.Len(t, match.Rounds, 3)
+	require.EqualValues(t, domain.LogsTFRound{
+		LogID:     3124689,
+		Round:     3,
+		Length:    domain.JSONDuration{Duration: 325000000000},
+		ScoreBLU:  0,
+		ScoreRED:  3,
+		KillsBLU:  34,
+		KillsRED:  46,
+		UbersBLU:  1,
+		UbersRED:  1,
+		DamageBLU: 15258,
+		DamageRED: 12677,
+		MidFight:  domain.RED,
+	}, match.Rounds[2])
+	require.Equal(t, domain.BLU, match.Rounds[1].MidFight)
+
+	require.Equal(t, domain.FormatUltiduo, match.Format)
+
+	// Console messages do not have a steam id.
+	require.Len(t, match.Chat, 3)
+	require.Equal(t, domain.LogsTFChat{
+		LogID:   3124689,
+		SteamID: steamid.New("[U:1:204626678]"),
+		Name:    "var",
+		Message: "gl hf",
+	}, match.Chat[0])
+	require.False(t, match.Chat[1].SteamID.Valid())
+	require.Equal(t, "tournament started", match.Chat[1].Message)
+
+	require.Len(t, match.Killstreaks, 2)
+	require.Equal(t, domain.LogsTFKillstreak{
+		LogID:   3124689,
+		SteamID: steamid.New("[U:1:71624381]"),
+		Streak:  5,
+		Time:    domain.JSONDuration{Duration: 412 * time.Second},
+	}, match.Killstreaks[0])
+}
+
+func TestLogsTFDetailsJSONOld(t *testing.T) {
+	body, errRead := os.ReadFile("testdata/logstf_detail_v1.json")
+	require.NoError(t, errRead)
+
+	match, errDetails := parseMatchFromJSON(100, body)
+	require.NoError(t, errDetails)
+
+	require.Equal(t, "Log 100", match.Title)
+	require.Equal(t, "39m3s", match.Duration.String())
+	require.Equal(t, "2012-11-26 07:39:59 +0000 UTC", match.CreatedOn.String())
+	require.Equal(t, 4, match.ScoreBLU)
+	require.Equal(t, 2, match.ScoreRED)
+	require.True(t, match.LogFormatOld)
+	require.Nil(t, match.Rounds)
+	require.Len(t, match.Players, 2)
+
+	sniper := match.Players[0]
+	require.Equal(t, steamid.New(76561198006069420), sniper.SteamID)
+	require.Equal(t, "paradox", sniper.Name)
+	require.InDelta(t, 2.6, sniper.KAD, 0.001)
+	require.Equal(t, 41, sniper.Backstabs)
+	require.Equal(t, 5, sniper.Headshots)
+	// Old logs only have the kill count for each weapon.
+	require.Equal(t, []domain.LogsTFPlayerClassWeapon{
+		{LogID: 100, SteamID: sniper.SteamID, Class: domain.Sniper, Weapon: "smg", Kills: 5, Damage: 0, Accuracy: 0},
+		{LogID: 100, SteamID: sniper.SteamID, Class: domain.Sniper, Weapon: "sniperrifle", Kills: 52, Damage: 0, Accuracy: 0},
+	}, sniper.Classes[0].Weapons)
+
+	require.Len(t, match.Medics, 1)
+	require.Equal(t, int64(31240), match.Medics[0].Healing)
+	require.Equal(t, 9, match.Medics[0].ChargesMedigun)
+	require.Equal(t, 800, match.Medics[0].HealingPerMin)
+}
+
+func TestLogsTFDetailsJSONFailure(t *testing.T) {
+	_, errMissing := parseMatchFromJSON(1, []byte(`{"success": false, "error": "Log not found"}`))
+	require.ErrorIs(t, errMissing, errLogsTFJSONFailure)
+
+	_, errInvalid := parseMatchFromJSON(1, []byte(`<html></html>`))
+	require.ErrorIs(t, errInvalid, errLogsTFJSONDecode)
+}
+
+func TestLogsTFFormat(t *testing.T) {
+	t.Parallel()
+
+	team := func(team domain.Team, played ...time.Duration) []domain.LogsTFPlayer {
+		var players []domain.LogsTFPlayer
+		for _, duration := range played {
+			players = append(players, domain.LogsTFPlayer{ //nolint:exhaustruct
+				Team:    team,
+				Classes: []domain.LogsTFPlayerClass{{Played: domain.JSONDuration{Duration: duration}}}, //nolint:exhaustruct
+			})
+		}
+
+		return players
+	}
+
+	match := domain.LogsTFMatch{} //nolint:exhaustruct
+	match.Duration.Duration = 30 * time.Minute
+
+	// A substitute splitting the match with another player only counts once.
+	full := []time.Duration{30 * time.Minute, 30 * time.Minute, 30 * time.Minute, 30 * time.Minute, 30 * time.Minute}
+	match.Players = append(team(domain.RED, append(full, 20*time.Minute, 10*time.Minute)...),
+		team(domain.BLU, append(full, 30*time.Minute)...)...)
+	require.Equal(t, domain.Format6v6, logsTFFormat(&match))
+
+	match.Players = append(team(domain.RED, 30*time.Minute, 30*time.Minute), team(domain.BLU, 30*time.Minute, 29*time.Minute)...)
+	require.Equal(t, domain.FormatUltiduo, logsTFFormat(&match))
+
+	// Without class times, players are counted instead.
+	match.Players = append(team(domain.RED, 0, 0, 0, 0, 0, 0, 0, 0, 0), team(domain.BLU, 0, 0, 0, 0, 0, 0, 0, 0, 0)...)
+	require.Equal(t, domain.FormatHighlander, logsTFFormat(&match))
+
+	match.Players = append(team(domain.RED, 30*time.Minute, 30*time.Minute, 30*time.Minute), team(domain.BLU, 30*time.Minute)...)
+	require.Equal(t, domain.FormatUnknown, logsTFFormat(&match))
+}
+
+func TestLogsTFPathID(t *testing.T) {
+	logID, errID := logsTFPathID("/json/3124689")
+	require.NoError(t, errID)
+	require.Equal(t, 3124689, logID)
+
+	logID, errID = logsTFPathID("/100")
+	require.NoError(t, errID)
+	require.Equal(t, 100, logID)
+
+	_, errID = logsTFPathID("/json/")
+	require.ErrorIs(t, errID, errLogID)
+}
+
+func TestLogsTFResult(t *testing.T) {
+	t.Parallel()
+
+	match := domain.LogsTFMatchInfo{ScoreRED: 3, ScoreBLU: 1} //nolint:exhaustruct
+	require.Equal(t, resultWin, logsTFResult(match, domain.RED))
+	require.Equal(t, resultLoss, logsTFResult(match, domain.BLU))
+
+	match.ScoreBLU = 3
+	require.Equal(t, resultTie, logsTFResult(match, domain.RED))
+}
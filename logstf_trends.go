@@ -0,0 +1,38 @@
+package main
+
+import "strings"
+
+const (
+	logsTFMapsDefaultLimit = 100
+	logsTFMapsMaxLimit     = 500
+)
+
+// logsTFTrendInterval is the period that format trends are grouped by.
+type logsTFTrendInterval string
+
+const (
+	intervalWeek  logsTFTrendInterval = "week"
+	intervalMonth logsTFTrendInterval = "month"
+	intervalYear  logsTFTrendInterval = "year"
+)
+
+// parseTrendInterval parses the interval, defaulting to monthly when empty.
+func parseTrendInterval(value string) (logsTFTrendInterval, bool) {
+	switch interval := logsTFTrendInterval(strings.ToLower(strings.TrimSpace(value))); interval {
+	case "":
+		return intervalMonth, true
+	case intervalWeek, intervalMonth, intervalYear:
+		return interval, true
+	default:
+		return "", false
+	}
+}
+
+// winRate returns the percentage of games won.
+func winRate(wins int, games int) float32 {
+	if games == 0 {
+		return 0
+	}
+
+	return float32(wins) / float32(games) * 100
+}
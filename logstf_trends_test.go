@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrendInterval(t *testing.T) {
+	t.Parallel()
+
+	for value, expected := range map[string]logsTFTrendInterval{"": intervalMonth, "Week": intervalWeek, "year": intervalYear} {
+		interval, valid := parseTrendInterval(value)
+		require.True(t, valid)
+		require.Equal(t, expected, interval)
+	}
+
+	_, valid := parseTrendInterval("day")
+	require.False(t, valid)
+}
+
+func TestWinRate(t *testing.T) {
+	t.Parallel()
+
+	require.InDelta(t, float32(25), winRate(1, 4), 0.001)
+	require.InDelta(t, float32(0), winRate(0, 0), 0.001)
+}
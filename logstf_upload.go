@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var errUploadKeyGenerate = errors.New("failed to generate upload key")
+
+// uploadKeyLength is the number of random bytes in an upload key, hex encoded when shown to the user.
+const uploadKeyLength = 24
+
+// logsTFUploadKey allows a server, or the person running it, to upload logs. The key itself is only shown when
+// it's created.
+type logsTFUploadKey struct {
+	UploadKeyID int64
+	Name        string
+	Enabled     bool
+	Uploads     int
+	LastUsedOn  *time.Time
+	CreatedOn   time.Time
+}
+
+// logsTFUploadResponse matches the response returned by https://logs.tf/upload so existing plugins can read it.
+type logsTFUploadResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	LogID   int    `json:"log_id,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// newUploadKey generates a new random key, returning the key and the hash that is stored.
+func newUploadKey() (string, string, error) {
+	value := make([]byte, uploadKeyLength)
+	if _, errRead := rand.Read(value); errRead != nil {
+		return "", "", errors.Join(errRead, errUploadKeyGenerate)
+	}
+
+	key := hex.EncodeToString(value)
+
+	return key, hashUploadKey(key), nil
+}
+
+func hashUploadKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// checkUploadKey returns the enabled upload key matching the key. The logs_upload_key from the config is also
+// accepted, it returns a key with a 0 id since it's not stored.
+func checkUploadKey(ctx context.Context, database *pgStore, config appConfig, key string) (logsTFUploadKey, error) {
+	if key == "" {
+		return logsTFUploadKey{}, errUnauthorized //nolint:exhaustruct
+	}
+
+	if config.LogsUploadKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(config.LogsUploadKey)) == 1 {
+		return logsTFUploadKey{Name: "config", Enabled: true}, nil //nolint:exhaustruct
+	}
+
+	uploadKey, errKey := database.logsTFUploadKeyByHash(ctx, hashUploadKey(key))
+	if errKey != nil {
+		if errors.Is(errKey, errDatabaseNoResults) {
+			return uploadKey, errUnauthorized
+		}
+
+		return uploadKey, errKey
+	}
+
+	if !uploadKey.Enabled {
+		return uploadKey, errUnauthorized
+	}
+
+	return uploadKey, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUploadKey(t *testing.T) {
+	t.Parallel()
+
+	key, keyHash, errKey := newUploadKey()
+	require.NoError(t, errKey)
+	require.Len(t, key, uploadKeyLength*2)
+	require.Equal(t, hashUploadKey(key), keyHash)
+	require.NotEqual(t, key, keyHash)
+
+	other, _, errOther := newUploadKey()
+	require.NoError(t, errOther)
+	require.NotEqual(t, key, other)
+}
+
+func TestCheckUploadKeyConfig(t *testing.T) {
+	t.Parallel()
+
+	config := appConfig{LogsUploadKey: "secret"} //nolint:exhaustruct
+
+	uploadKey, errKey := checkUploadKey(context.Background(), nil, config, "secret")
+	require.NoError(t, errKey)
+	require.Equal(t, int64(0), uploadKey.UploadKeyID)
+
+	_, errEmpty := checkUploadKey(context.Background(), nil, config, "")
+	require.ErrorIs(t, errEmpty, errUnauthorized)
+}
@@ -4,6 +4,7 @@
 	"context"
 	"errors"
 	"log/slog"
+	_ "time/tzdata"
 )
 
 var version = "1.1.1"
@@ -44,6 +45,13 @@ func runSourcebansScraper(ctx context.Context, database *pgStore, config appConf
 		return errScrapers
 	}
 
+	browsers := newConfigBrowserPool(ctx, config)
+	defer browsers.Close()
+
+	for _, scraper := range scrapers {
+		scraper.setBrowserPool(browsers)
+	}
+
 	if config.ProxiesEnabled {
 		for _, scraper := range scrapers {
 			if errProxies := attachCollectorProxies(scraper.Collector, &config); errProxies != nil {
@@ -52,7 +60,12 @@ func runSourcebansScraper(ctx context.Context, database *pgStore, config appConf
 		}
 	}
 
-	runScrapers(ctx, database, scrapers)
+	runScrapers(ctx, database, scrapers, config.SourcebansMaxSites)
+
+	// Returning the error lets the job be retried, which resumes from the saved checkpoints.
+	if errCtx := ctx.Err(); errCtx != nil {
+		return errors.Join(errCtx, errScrapeCancelled)
+	}
 
 	return nil
 }
@@ -0,0 +1,5 @@
+begin;
+
+ALTER TABLE sb_ban DROP COLUMN duration_mismatch;
+
+commit;
@@ -0,0 +1,5 @@
+begin;
+
+ALTER TABLE sb_ban ADD COLUMN duration_mismatch boolean not null default false;
+
+commit;
@@ -0,0 +1,8 @@
+begin;
+
+ALTER TABLE sb_ban DROP COLUMN admin_name;
+ALTER TABLE sb_ban DROP COLUMN server;
+ALTER TABLE sb_ban DROP COLUMN total_bans;
+ALTER TABLE sb_ban DROP COLUMN demo_url;
+
+commit;
@@ -0,0 +1,8 @@
+begin;
+
+ALTER TABLE sb_ban ADD COLUMN admin_name text not null default '';
+ALTER TABLE sb_ban ADD COLUMN server text not null default '';
+ALTER TABLE sb_ban ADD COLUMN total_bans int not null default 0;
+ALTER TABLE sb_ban ADD COLUMN demo_url text not null default '';
+
+commit;
@@ -0,0 +1,10 @@
+begin;
+
+DROP INDEX IF EXISTS sb_ban_reason_category_idx;
+
+ALTER TABLE sb_ban DROP COLUMN reason_category;
+ALTER TABLE rgl_ban DROP COLUMN reason_category;
+ALTER TABLE etf2l_ban DROP COLUMN reason_category;
+ALTER TABLE serveme DROP COLUMN reason_category;
+
+commit;
@@ -0,0 +1,10 @@
+begin;
+
+ALTER TABLE sb_ban ADD COLUMN reason_category text not null default '';
+ALTER TABLE rgl_ban ADD COLUMN reason_category text not null default '';
+ALTER TABLE etf2l_ban ADD COLUMN reason_category text not null default '';
+ALTER TABLE serveme ADD COLUMN reason_category text not null default '';
+
+create index if not exists sb_ban_reason_category_idx ON sb_ban (reason_category);
+
+commit;
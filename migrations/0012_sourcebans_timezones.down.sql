@@ -0,0 +1,30 @@
+begin;
+
+-- Bans that were deleted by the up migration, because they collided with another ban after being converted, are
+-- not restored.
+
+-- Shifting the rows one by one can temporarily collide with rows that have not been shifted yet.
+drop index if exists sb_ban_uidx;
+
+-- Revert back to the sites local wall clock.
+update sb_ban b
+set created_on = (b.created_on at time zone 'UTC') at time zone s.timezone
+from sb_site s
+where s.sb_site_id = b.sb_site_id
+  and s.timezone != '';
+
+-- Times that fall within a DST transition can map onto an existing ban, keep the oldest one.
+delete
+from sb_ban
+where sb_ban_id in (select sb_ban_id
+                    from (select sb_ban_id,
+                                 row_number() over (partition by sb_site_id, steam_id, created_on order by sb_ban_id) as num
+                          from sb_ban) dupes
+                    where dupes.num > 1);
+
+create unique index if not exists sb_ban_uidx ON sb_ban (sb_site_id, steam_id, created_on);
+
+alter table sb_site
+    drop column if exists timezone;
+
+commit;
@@ -0,0 +1,64 @@
+begin;
+
+-- The timezone each site renders its ban times in. Empty when unknown, in which case UTC is assumed.
+alter table sb_site
+    add column if not exists timezone text not null default '';
+
+-- Sites that have not been scraped yet are created here, so that new installs start out with their timezone.
+insert into sb_site (name, timezone)
+values
+       ('7mau', 'Europe/Berlin'),
+       ('acekill', 'Europe/Warsaw'),
+       ('amsgaming', 'Asia/Kolkata'),
+       ('astramania', 'Europe/Bucharest'),
+       ('bachuruservas', 'Europe/Vilnius'),
+       ('bierwiese', 'Europe/Berlin'),
+       ('casualfun', 'Europe/Berlin'),
+       ('cuteproject', 'Europe/Moscow'),
+       ('defusero', 'Europe/Bucharest'),
+       ('dreamfire', 'Europe/Paris'),
+       ('epiczone', 'Europe/Bratislava'),
+       ('g44', 'Europe/Moscow'),
+       ('gamesites', 'Europe/Prague'),
+       ('getsome', 'Pacific/Auckland'),
+       ('gunserver', 'Europe/Moscow'),
+       ('hellclan', 'Europe/London'),
+       ('lunario', 'Europe/Bucharest'),
+       ('magyarhns', 'Europe/Budapest'),
+       ('oreon', 'Europe/Paris'),
+       ('phoenixsource', 'Europe/Moscow'),
+       ('playesro', 'Europe/Bucharest'),
+       ('progameszet', 'Europe/Moscow'),
+       ('prwh', 'Europe/Berlin'),
+       ('servilivecl', 'America/Santiago'),
+       ('slavonserver', 'Europe/Moscow'),
+       ('sneaks', 'America/Chicago'),
+       ('tf2ro', 'Europe/Bucharest'),
+       ('vortex', 'Europe/Istanbul'),
+       ('zmbrasil', 'America/Sao_Paulo'),
+       ('zubat', 'Europe/Moscow')
+on conflict (name) do update set timezone = excluded.timezone;
+
+-- Shifting the rows one by one can temporarily collide with rows that have not been shifted yet.
+drop index if exists sb_ban_uidx;
+
+-- Existing times were saved using the sites local wall clock, convert them to UTC.
+update sb_ban b
+set created_on = (b.created_on at time zone s.timezone) at time zone 'UTC'
+from sb_site s
+where s.sb_site_id = b.sb_site_id
+  and s.timezone != '';
+
+-- Times that fall within a DST transition can map onto an existing ban, keep the oldest one. The newer rows are
+-- deleted for good, the down migration can not restore them.
+delete
+from sb_ban
+where sb_ban_id in (select sb_ban_id
+                    from (select sb_ban_id,
+                                 row_number() over (partition by sb_site_id, steam_id, created_on order by sb_ban_id) as num
+                          from sb_ban) dupes
+                    where dupes.num > 1);
+
+create unique index if not exists sb_ban_uidx ON sb_ban (sb_site_id, steam_id, created_on);
+
+commit;
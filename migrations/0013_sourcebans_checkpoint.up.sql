@@ -0,0 +1,13 @@
+begin;
+
+create table if not exists sb_site_checkpoint
+(
+    sb_site_id int                     not null
+        primary key
+        references sb_site (sb_site_id) on delete cascade,
+    page       int                     not null,
+    url        text                    not null,
+    updated_on timestamp default now() not null
+);
+
+commit;
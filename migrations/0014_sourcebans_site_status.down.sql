@@ -0,0 +1,6 @@
+begin;
+
+ALTER TABLE sb_site DROP COLUMN last_scraped_on;
+ALTER TABLE sb_site DROP COLUMN enabled;
+
+commit;
@@ -0,0 +1,6 @@
+begin;
+
+ALTER TABLE sb_site ADD COLUMN enabled bool not null default true;
+ALTER TABLE sb_site ADD COLUMN last_scraped_on timestamp;
+
+commit;
@@ -0,0 +1,10 @@
+begin;
+
+drop index if exists logstf_player_class_weapon_uidx;
+-- Weapons used by multiple classes collapse back into a single row
+delete from logstf_player_class_weapon a using logstf_player_class_weapon b
+where a.log_id = b.log_id and a.steam_id = b.steam_id and a.weapon = b.weapon and a.player_class > b.player_class;
+ALTER TABLE logstf_player_class_weapon DROP COLUMN player_class;
+create unique index logstf_player_class_weapon_uidx ON logstf_player_class_weapon (log_id, steam_id, weapon);
+
+commit;
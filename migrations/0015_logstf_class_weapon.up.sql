@@ -0,0 +1,9 @@
+begin;
+
+-- The same weapon can show up under multiple classes, eg: the shotgun.
+ALTER TABLE logstf_player_class_weapon ADD COLUMN player_class int not null default 0;
+
+drop index if exists logstf_player_class_weapon_uidx;
+create unique index logstf_player_class_weapon_uidx ON logstf_player_class_weapon (log_id, steam_id, player_class, weapon);
+
+commit;
@@ -0,0 +1,6 @@
+begin;
+
+drop table if exists logstf_killstreak;
+drop table if exists logstf_chat;
+
+commit;
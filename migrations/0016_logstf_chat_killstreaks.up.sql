@@ -0,0 +1,26 @@
+begin;
+
+create table if not exists logstf_chat
+(
+    log_id   bigint not null references logstf (log_id) ON DELETE CASCADE,
+    idx      int    not null,
+    -- Console and other non player messages use 0
+    steam_id bigint not null,
+    name     text   not null,
+    message  text   not null,
+    primary key (log_id, idx)
+);
+
+create index if not exists logstf_chat_steam_id_idx ON logstf_chat (steam_id);
+
+create table if not exists logstf_killstreak
+(
+    log_id   bigint not null references logstf (log_id) ON DELETE CASCADE,
+    steam_id bigint not null,
+    streak   int    not null,
+    time     bigint not null
+);
+
+create index if not exists logstf_killstreak_log_id_idx ON logstf_killstreak (log_id);
+
+commit;
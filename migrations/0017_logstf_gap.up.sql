@@ -0,0 +1,20 @@
+begin;
+
+create table if not exists logstf_gap
+(
+    gap_id       bigserial primary key,
+    start_id     int       not null,
+    end_id       int       not null,
+    -- pending gaps are retried, missing and failed gaps are only retried when backfilled
+    status       text      not null,
+    reason       text      not null,
+    attempts     int       not null default 0,
+    next_attempt timestamp not null,
+    created_on   timestamp not null,
+    updated_on   timestamp not null,
+    check (start_id <= end_id)
+);
+
+create index if not exists logstf_gap_due_idx ON logstf_gap (status, next_attempt);
+
+commit;
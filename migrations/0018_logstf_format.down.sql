@@ -0,0 +1,8 @@
+begin;
+
+drop index if exists logstf_player_class_steam_id_idx;
+drop index if exists logstf_format_idx;
+
+update logstf set format = '';
+
+commit;
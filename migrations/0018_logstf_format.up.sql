@@ -0,0 +1,31 @@
+begin;
+
+-- Format was never populated by the scraper. Matches the logic in logsTFFormat: the team size is the total time
+-- played by the team divided by the match length, falling back to the number of players without class times.
+update logstf l
+set format = case sizes.team_size
+                 when 2 then 'ultiduo'
+                 when 4 then '4v4'
+                 when 6 then '6v6'
+                 when 7 then 'prolander'
+                 when 9 then 'highlander'
+                 else '' end
+from (select teams.log_id, max(teams.size) as team_size
+      from (select p.log_id,
+                   p.team,
+                   case
+                       when coalesce(sum(c.played), 0) > 0 and lg.duration > 0
+                           then round(sum(c.played)::numeric / lg.duration)
+                       else count(distinct p.steam_id) end as size
+            from logstf_player p
+                     join logstf lg on lg.log_id = p.log_id
+                     left join logstf_player_class c on c.log_id = p.log_id and c.steam_id = p.steam_id
+            group by p.log_id, p.team, lg.duration) teams
+      group by teams.log_id) sizes
+where sizes.log_id = l.log_id
+  and l.format = '';
+
+create index if not exists logstf_format_idx ON logstf (format);
+create index if not exists logstf_player_class_steam_id_idx ON logstf_player_class (steam_id);
+
+commit;
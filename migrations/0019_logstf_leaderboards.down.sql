@@ -0,0 +1,8 @@
+begin;
+
+drop materialized view if exists logstf_player_percentile;
+drop materialized view if exists logstf_leaderboard;
+
+ALTER TABLE logstf_player DROP COLUMN airshots;
+
+commit;
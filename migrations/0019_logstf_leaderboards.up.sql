@@ -0,0 +1,66 @@
+begin;
+
+ALTER TABLE logstf_player ADD COLUMN airshots int not null default 0;
+
+-- Per class totals for each player and format, plus an 'all' row covering every format. Airshots are only tracked
+-- per player, so they are attributed to the class the player spent the most time on in each match.
+create materialized view if not exists logstf_leaderboard as
+with primary_class as (select distinct on (log_id, steam_id) log_id, steam_id, player_class
+                       from logstf_player_class
+                       order by log_id, steam_id, played desc)
+select c.steam_id,
+       c.player_class,
+       case when grouping(l.format) = 1 then 'all' else l.format end                         as format,
+       count(c.log_id)                                                                         as games,
+       sum(c.played)::bigint                                                                   as played,
+       sum(c.kills)::bigint                                                                    as kills,
+       sum(c.deaths)::bigint                                                                   as deaths,
+       sum(c.damage)::bigint                                                                   as damage,
+       coalesce(sum(p.airshots) filter (where pc.player_class = c.player_class), 0)::bigint     as airshots,
+       coalesce(sum(m.healing), 0)::bigint                                                     as healing,
+       coalesce(sum(m.charges_kritz + m.charges_quickfix + m.charges_medigun + m.charges_vacc), 0)::bigint as ubers
+from logstf_player_class c
+         join logstf l on l.log_id = c.log_id
+         join logstf_player p on p.log_id = c.log_id and p.steam_id = c.steam_id
+         left join primary_class pc on pc.log_id = c.log_id and pc.steam_id = c.steam_id
+         left join logstf_medic m on m.log_id = c.log_id and m.steam_id = c.steam_id and c.player_class = 7
+where c.played > 0
+group by grouping sets ((c.steam_id, c.player_class, l.format), (c.steam_id, c.player_class))
+with no data;
+
+-- The unique index is required to refresh concurrently
+create unique index if not exists logstf_leaderboard_uidx ON logstf_leaderboard (steam_id, player_class, format);
+create index if not exists logstf_leaderboard_class_idx ON logstf_leaderboard (player_class, format, games);
+
+-- Percentile ranks of each players averages, compared to every player with at least 10 matches. Higher is always
+-- better, so damage taken is ranked in reverse.
+create materialized view if not exists logstf_player_percentile as
+with totals as (select steam_id,
+                       count(log_id)                                         as games,
+                       avg(dpm)                                              as dpm,
+                       sum(kills)::float / greatest(sum(deaths), 1)          as kd,
+                       sum(kills + assists)::float / greatest(sum(deaths), 1) as kad,
+                       avg(kills)                                            as kills,
+                       avg(damage)                                           as damage,
+                       avg(dtm)                                              as dtm,
+                       avg(airshots)                                         as airshots,
+                       avg(hs)                                               as headshots
+                from logstf_player
+                group by steam_id
+                having count(log_id) >= 10)
+select steam_id,
+       games,
+       round((percent_rank() over (order by dpm) * 100)::numeric, 1)       as dpm,
+       round((percent_rank() over (order by kd) * 100)::numeric, 1)        as kd,
+       round((percent_rank() over (order by kad) * 100)::numeric, 1)       as kad,
+       round((percent_rank() over (order by kills) * 100)::numeric, 1)     as kills,
+       round((percent_rank() over (order by damage) * 100)::numeric, 1)    as damage,
+       round((percent_rank() over (order by dtm desc) * 100)::numeric, 1)  as dtm,
+       round((percent_rank() over (order by airshots) * 100)::numeric, 1)  as airshots,
+       round((percent_rank() over (order by headshots) * 100)::numeric, 1) as headshots
+from totals
+with no data;
+
+create unique index if not exists logstf_player_percentile_uidx ON logstf_player_percentile (steam_id);
+
+commit;
@@ -0,0 +1,19 @@
+begin;
+
+-- Keys used by game servers to upload logs to the logs.tf compatible POST /upload endpoint. Only the sha256 hash of
+-- the key is stored.
+create table if not exists logstf_upload_key
+(
+    upload_key_id bigserial primary key,
+    name          text        not null,
+    key_hash      text        not null,
+    enabled       bool        not null default true,
+    uploads       int         not null default 0,
+    last_used_on  timestamptz,
+    created_on    timestamptz not null
+);
+
+create unique index if not exists logstf_upload_key_name_uidx ON logstf_upload_key (name);
+create unique index if not exists logstf_upload_key_hash_uidx ON logstf_upload_key (key_hash);
+
+commit;
@@ -0,0 +1,8 @@
+begin;
+
+drop table if exists ugc_player;
+drop table if exists ugc_team_member;
+drop table if exists ugc_team;
+drop table if exists ugc_ban;
+
+commit;
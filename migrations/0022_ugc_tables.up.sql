@@ -0,0 +1,50 @@
+begin;
+
+create table if not exists ugc_ban
+(
+    steam_id        bigint      not null references player (steam_id),
+    alias           text        not null,
+    created_at      timestamptz not null,
+    expires_at      timestamptz not null,
+    reason          text        not null,
+    reason_category text        not null default ''
+);
+
+create index if not exists ugc_ban_steam_id_idx ON ugc_ban (steam_id);
+
+-- scraped_on is null for teams only seen in a players team history, their page has not been fetched yet.
+create table if not exists ugc_team
+(
+    team_id       int primary key,
+    team_name     text        not null,
+    tag           text        not null default '',
+    format_name   text        not null default '',
+    division_name text        not null default '',
+    region_name   text        not null default '',
+    scraped_on    timestamptz,
+    created_on    timestamptz not null,
+    updated_on    timestamptz not null
+);
+
+create table if not exists ugc_team_member
+(
+    team_id        int         not null references ugc_team (team_id),
+    steam_id       bigint      not null references player (steam_id),
+    name           text        not null,
+    is_team_leader bool        not null default false,
+    joined_at      timestamptz not null,
+    left_at        timestamptz
+);
+
+create unique index if not exists ugc_team_member_uidx ON ugc_team_member (team_id, steam_id, joined_at);
+create index if not exists ugc_team_member_steam_id_idx ON ugc_team_member (steam_id);
+
+-- Tracks when each players page was last scraped.
+create table if not exists ugc_player
+(
+    steam_id   bigint primary key references player (steam_id),
+    name       text        not null,
+    scraped_on timestamptz not null
+);
+
+commit;
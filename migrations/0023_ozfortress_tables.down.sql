@@ -0,0 +1,9 @@
+begin;
+
+drop table if exists ozfortress_player;
+drop table if exists ozfortress_match;
+drop table if exists ozfortress_roster_member;
+drop table if exists ozfortress_roster;
+drop table if exists ozfortress_ban;
+
+commit;
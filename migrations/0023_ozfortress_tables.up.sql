@@ -0,0 +1,66 @@
+begin;
+
+create table if not exists ozfortress_ban
+(
+    steam_id        bigint      not null references player (steam_id),
+    alias           text        not null,
+    created_at      timestamptz not null,
+    expires_at      timestamptz not null,
+    reason          text        not null,
+    reason_category text        not null default ''
+);
+
+create index if not exists ozfortress_ban_steam_id_idx ON ozfortress_ban (steam_id);
+
+create table if not exists ozfortress_roster
+(
+    roster_id     int primary key,
+    team_id       int         not null,
+    team_name     text        not null,
+    roster_name   text        not null,
+    division_name text        not null default '',
+    league_id     int         not null,
+    league_name   text        not null default '',
+    created_on    timestamptz not null,
+    updated_on    timestamptz not null
+);
+
+create table if not exists ozfortress_roster_member
+(
+    roster_id int    not null references ozfortress_roster (roster_id) on delete cascade,
+    steam_id  bigint not null references player (steam_id),
+    name      text   not null
+);
+
+create unique index if not exists ozfortress_roster_member_uidx ON ozfortress_roster_member (roster_id, steam_id);
+create index if not exists ozfortress_roster_member_steam_id_idx ON ozfortress_roster_member (steam_id);
+
+-- Rosters are not referenced by foreign key, a match can be fetched before the opposing roster.
+create table if not exists ozfortress_match
+(
+    match_id       int primary key,
+    league_id      int         not null,
+    round_name     text        not null default '',
+    status         text        not null default '',
+    home_roster_id int         not null,
+    away_roster_id int,
+    home_score     int         not null default 0,
+    away_score     int         not null default 0,
+    forfeit_by     text        not null default '',
+    maps           text[]      not null default '{}',
+    created_on     timestamptz not null
+);
+
+create index if not exists ozfortress_match_home_roster_id_idx ON ozfortress_match (home_roster_id);
+create index if not exists ozfortress_match_away_roster_id_idx ON ozfortress_match (away_roster_id);
+
+-- Tracks when each players user profile, and their bans, were last fetched.
+create table if not exists ozfortress_player
+(
+    steam_id   bigint primary key references player (steam_id),
+    user_id    int         not null default 0,
+    name       text        not null,
+    scraped_on timestamptz not null
+);
+
+commit;
@@ -0,0 +1,8 @@
+begin;
+
+drop table if exists etf2l_player;
+drop table if exists etf2l_match;
+drop table if exists etf2l_team_member;
+drop table if exists etf2l_team;
+
+commit;
@@ -0,0 +1,57 @@
+begin;
+
+-- scraped_on is null for teams only seen in a transfer, their details have not been fetched yet.
+create table if not exists etf2l_team
+(
+    team_id    int primary key,
+    team_name  text        not null,
+    tag        text        not null default '',
+    team_type  text        not null default '',
+    country    text        not null default '',
+    scraped_on timestamptz,
+    created_on timestamptz not null,
+    updated_on timestamptz not null
+);
+
+create table if not exists etf2l_team_member
+(
+    team_id   int         not null references etf2l_team (team_id),
+    steam_id  bigint      not null references player (steam_id),
+    name      text        not null,
+    joined_at timestamptz not null,
+    left_at   timestamptz
+);
+
+create unique index if not exists etf2l_team_member_uidx ON etf2l_team_member (team_id, steam_id, joined_at);
+create index if not exists etf2l_team_member_steam_id_idx ON etf2l_team_member (steam_id);
+
+-- Teams are not referenced by foreign key, results are stored before the opposing team is known.
+create table if not exists etf2l_match
+(
+    match_id         int primary key,
+    competition_id   int         not null,
+    competition_name text        not null default '',
+    division_name    text        not null default '',
+    round_name       text        not null default '',
+    team1_id         int         not null,
+    team2_id         int         not null,
+    team1_score      int         not null default 0,
+    team2_score      int         not null default 0,
+    default_win      bool        not null default false,
+    maps             text[]      not null default '{}',
+    played_at        timestamptz not null
+);
+
+create index if not exists etf2l_match_team1_id_idx ON etf2l_match (team1_id);
+create index if not exists etf2l_match_team2_id_idx ON etf2l_match (team2_id);
+
+-- Tracks when each players profile and transfers were last fetched.
+create table if not exists etf2l_player
+(
+    steam_id   bigint primary key references player (steam_id),
+    player_id  int         not null default 0,
+    name       text        not null,
+    scraped_on timestamptz not null
+);
+
+commit;
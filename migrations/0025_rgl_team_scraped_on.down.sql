@@ -0,0 +1,8 @@
+begin;
+
+-- The previous left_at values are not restored, they were never when a player left.
+
+alter table rgl_team
+    drop column if exists scraped_on;
+
+commit;
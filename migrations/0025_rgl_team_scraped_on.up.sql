@@ -0,0 +1,13 @@
+begin;
+
+-- Teams are fetched again once stale, so their rosters pick up players who left. Existing teams are treated as
+-- never fetched.
+alter table rgl_team
+    add column if not exists scraped_on timestamptz;
+
+-- Roster members were stored with the players roster update time as their left_at, which was never when they left.
+-- They are current until their team is fetched again and they are missing from the roster.
+update rgl_team_member
+set left_at = null;
+
+commit;
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"golang.org/x/time/rate"
+)
+
+var (
+	errOzfortressStatus   = errors.New("unexpected ozfortress response status")
+	errOzfortressNotFound = errors.New("ozfortress resource not found")
+	errOzfortressAPIKey   = errors.New("ozfortress api key is not set")
+)
+
+const (
+	ozfortressBaseURL = "https://ozfortress.com/api/v1"
+	// ozfortressRefreshInterval is how long a players user profile, and their bans, are considered fresh.
+	ozfortressRefreshInterval = 7 * 24 * time.Hour
+	ozfortressRefillRate      = 1
+	ozfortressBucketSize      = 2
+	// ozfortressMatchConfirmed is the status of a match once both teams have agreed on the result.
+	ozfortressMatchConfirmed = "confirmed"
+)
+
+// ozfortressPermanent is the expiry used for bans which have not been terminated.
+func ozfortressPermanent() time.Time {
+	return time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func NewOzfortressLimiter() *LimiterCustom {
+	return &LimiterCustom{Limiter: rate.NewLimiter(ozfortressRefillRate, ozfortressBucketSize)}
+}
+
+// ozfortressClient queries the ozfortress (citadel) json api. Every request requires an api key.
+type ozfortressClient struct {
+	httpClient *http.Client
+	limiter    *LimiterCustom
+	baseURL    string
+	apiKey     string
+}
+
+func newOzfortressClient(apiKey string) *ozfortressClient {
+	return &ozfortressClient{
+		httpClient: NewHTTPClient(),
+		limiter:    NewOzfortressLimiter(),
+		baseURL:    ozfortressBaseURL,
+		apiKey:     apiKey,
+	}
+}
+
+type ozfortressRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ozfortressBan struct {
+	Reason       string     `json:"reason"`
+	CreatedAt    time.Time  `json:"created_at"`
+	TerminatedAt *time.Time `json:"terminated_at"`
+}
+
+type ozfortressUser struct {
+	ID        int             `json:"id"`
+	Name      string          `json:"name"`
+	SteamID64 int64           `json:"steam_64"`
+	Bans      []ozfortressBan `json:"bans"`
+}
+
+type ozfortressRoster struct {
+	ID        int              `json:"id"`
+	Name      string           `json:"name"`
+	Team      ozfortressRef    `json:"team"`
+	Division  ozfortressRef    `json:"division"`
+	League    ozfortressRef    `json:"league"`
+	Players   []ozfortressUser `json:"players"`
+	Matches   []ozfortressRef  `json:"matches"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+type ozfortressRound struct {
+	Map           ozfortressRef `json:"map"`
+	HomeTeamScore int           `json:"home_team_score"`
+	AwayTeamScore int           `json:"away_team_score"`
+}
+
+type ozfortressMatch struct {
+	ID        int               `json:"id"`
+	League    ozfortressRef     `json:"league"`
+	RoundName string            `json:"round_name"`
+	Status    string            `json:"status"`
+	ForfeitBy string            `json:"forfeit_by"`
+	HomeTeam  ozfortressRef     `json:"home_team"`
+	AwayTeam  *ozfortressRef    `json:"away_team"`
+	Rounds    []ozfortressRound `json:"rounds"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func (c *ozfortressClient) get(ctx context.Context, path string, out any) error {
+	if c.apiKey == "" {
+		return errOzfortressAPIKey
+	}
+
+	c.limiter.Wait(ctx)
+
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if errReq != nil {
+		return errors.Join(errReq, errRequestCreate)
+	}
+
+	req.Header.Set("X-API-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, errResp := c.httpClient.Do(req)
+	if errResp != nil {
+		return errors.Join(errResp, errRequestPerform)
+	}
+
+	defer logCloser(resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return errOzfortressNotFound
+	default:
+		return fmt.Errorf("%w: %d", errOzfortressStatus, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.Join(err, errResponseDecode)
+	}
+
+	return nil
+}
+
+func (c *ozfortressClient) user(ctx context.Context, steamID steamid.SteamID) (ozfortressUser, error) {
+	var resp struct {
+		User ozfortressUser `json:"user"`
+	}
+
+	if err := c.get(ctx, "/users/steam_id/"+steamID.String(), &resp); err != nil {
+		return ozfortressUser{}, err
+	}
+
+	return resp.User, nil
+}
+
+func (c *ozfortressClient) roster(ctx context.Context, rosterID int) (ozfortressRoster, error) {
+	var resp struct {
+		Roster ozfortressRoster `json:"roster"`
+	}
+
+	if err := c.get(ctx, "/rosters/"+strconv.Itoa(rosterID), &resp); err != nil {
+		return ozfortressRoster{}, err
+	}
+
+	return resp.Roster, nil
+}
+
+func (c *ozfortressClient) match(ctx context.Context, matchID int) (ozfortressMatch, error) {
+	var resp struct {
+		Match ozfortressMatch `json:"match"`
+	}
+
+	if err := c.get(ctx, "/matches/"+strconv.Itoa(matchID), &resp); err != nil {
+		return ozfortressMatch{}, err
+	}
+
+	return resp.Match, nil
+}
+
+// toBans converts the users bans. Bans which have not been terminated are treated as permanent.
+func (u ozfortressUser) toBans(steamID steamid.SteamID) []domain.OzfortressBan {
+	bans := make([]domain.OzfortressBan, 0, len(u.Bans))
+
+	for _, ban := range u.Bans {
+		expiresAt := ozfortressPermanent()
+		if ban.TerminatedAt != nil {
+			expiresAt = *ban.TerminatedAt
+		}
+
+		bans = append(bans, domain.OzfortressBan{
+			SteamID:        steamID,
+			Alias:          u.Name,
+			ExpiresAt:      expiresAt,
+			CreatedAt:      ban.CreatedAt,
+			Reason:         ban.Reason,
+			ReasonCategory: classifyReason(ban.Reason),
+		})
+	}
+
+	return bans
+}
+
+// toDomain converts the roster and its players. Players without a valid steam id are skipped.
+func (r ozfortressRoster) toDomain() (domain.OzfortressRoster, []domain.OzfortressRosterMember) {
+	roster := domain.OzfortressRoster{
+		RosterID:     r.ID,
+		TeamID:       r.Team.ID,
+		TeamName:     r.Team.Name,
+		RosterName:   r.Name,
+		DivisionName: r.Division.Name,
+		LeagueID:     r.League.ID,
+		LeagueName:   r.League.Name,
+		CreatedOn:    r.CreatedAt,
+		UpdatedOn:    r.UpdatedAt,
+	}
+
+	members := make([]domain.OzfortressRosterMember, 0, len(r.Players))
+
+	for _, player := range r.Players {
+		sid := steamid.New(player.SteamID64)
+		if !sid.Valid() {
+			continue
+		}
+
+		members = append(members, domain.OzfortressRosterMember{RosterID: r.ID, SteamID: sid, Name: player.Name})
+	}
+
+	return roster, members
+}
+
+// toDomain converts the match, scoring each team by the number of rounds won. A forfeit_by of "no_forfeit" is
+// stored as an empty string.
+func (m ozfortressMatch) toDomain() domain.OzfortressMatch {
+	match := domain.OzfortressMatch{
+		MatchID:      m.ID,
+		LeagueID:     m.League.ID,
+		RoundName:    m.RoundName,
+		Status:       m.Status,
+		HomeRosterID: m.HomeTeam.ID,
+		AwayRosterID: nil,
+		HomeScore:    0,
+		AwayScore:    0,
+		ForfeitBy:    m.ForfeitBy,
+		Maps:         make([]string, 0, len(m.Rounds)),
+		CreatedOn:    m.CreatedAt,
+	}
+
+	if match.ForfeitBy == "no_forfeit" {
+		match.ForfeitBy = ""
+	}
+
+	if m.AwayTeam != nil {
+		match.AwayRosterID = &m.AwayTeam.ID
+	}
+
+	for _, round := range m.Rounds {
+		match.Maps = append(match.Maps, round.Map.Name)
+
+		switch {
+		case round.HomeTeamScore > round.AwayTeamScore:
+			match.HomeScore++
+		case round.AwayTeamScore > round.HomeTeamScore:
+			match.AwayScore++
+		}
+	}
+
+	return match
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// newTestOzfortressClient returns a client backed by a local stand in for the api, serving the json fixtures.
+func newTestOzfortressClient(t *testing.T) *ozfortressClient {
+	t.Helper()
+
+	server := newFixtureServer(t, map[string]string{
+		"/users/steam_id/76561198084134025": "testdata/ozfortress_user.json",
+		"/rosters/1210":                     "testdata/ozfortress_roster.json",
+		"/matches/9001":                     "testdata/ozfortress_match.json",
+	}, http.Header{"X-Api-Key": []string{"test-key"}})
+
+	client := newOzfortressClient("test-key")
+	client.baseURL = server.URL
+	client.limiter = &LimiterCustom{Limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	return client
+}
+
+func TestOzfortressClient(t *testing.T) {
+	t.Parallel()
+
+	client := newTestOzfortressClient(t)
+	ctx := context.Background()
+	sid := steamid.New(76561198084134025)
+
+	user, errUser := client.user(ctx, sid)
+	require.NoError(t, errUser)
+	require.Equal(t, 4410, user.ID)
+
+	bans := user.toBans(sid)
+	require.Len(t, bans, 2)
+	require.Equal(t, "koala", bans[0].Alias)
+	require.Equal(t, "Cheating - VAC ban on record", bans[0].Reason)
+	require.Equal(t, ozfortressPermanent(), bans[0].ExpiresAt)
+	require.True(t, time.Date(2023, time.June, 12, 10, 0, 0, 0, time.UTC).Equal(bans[1].ExpiresAt))
+
+	roster, errRoster := client.roster(ctx, 1210)
+	require.NoError(t, errRoster)
+
+	team, members := roster.toDomain()
+	require.Equal(t, 512, team.TeamID)
+	require.Equal(t, "Premier", team.DivisionName)
+	require.Equal(t, "ozfortress Sixes Season 36", team.LeagueName)
+	require.Len(t, members, 2)
+	require.Equal(t, sid, members[0].SteamID)
+	require.Len(t, roster.Matches, 2)
+
+	apiMatch, errMatch := client.match(ctx, 9001)
+	require.NoError(t, errMatch)
+
+	match := apiMatch.toDomain()
+	require.Equal(t, 1210, match.HomeRosterID)
+	require.NotNil(t, match.AwayRosterID)
+	require.Equal(t, 1211, *match.AwayRosterID)
+	require.Equal(t, 2, match.HomeScore)
+	require.Equal(t, 1, match.AwayScore)
+	require.Empty(t, match.ForfeitBy)
+	require.Equal(t, []string{"cp_process_f12", "koth_product_final", "cp_gullywash_f9"}, match.Maps)
+
+	_, errMissing := client.roster(ctx, 1)
+	require.ErrorIs(t, errMissing, errOzfortressNotFound)
+
+	client.apiKey = "invalid"
+	_, errStatus := client.match(ctx, 9001)
+	require.ErrorIs(t, errStatus, errOzfortressStatus)
+
+	client.apiKey = ""
+	_, errKey := client.match(ctx, 9001)
+	require.ErrorIs(t, errKey, errOzfortressAPIKey)
+}
+
+func TestOzfortressMatchForfeit(t *testing.T) {
+	t.Parallel()
+
+	match := ozfortressMatch{ //nolint:exhaustruct
+		ID:        1,
+		ForfeitBy: "home_team_forfeit",
+		HomeTeam:  ozfortressRef{ID: 10, Name: "home"},
+	}.toDomain()
+
+	require.Equal(t, "home_team_forfeit", match.ForfeitBy)
+	require.Nil(t, match.AwayRosterID)
+	require.Equal(t, 0, match.HomeScore)
+	require.Empty(t, match.Maps)
+}
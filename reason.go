@@ -0,0 +1,95 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/leighmacdonald/bd-api/domain"
+)
+
+// reasonRule matches a category against a set of keywords. Each keyword is matched against the start of
+// a word, so "cheat" matches "cheater" and "cheating". Keywords with a trailing space must match the whole
+// word, which is used for short or ambiguous terms like "wh" and "bot".
+type reasonRule struct {
+	category domain.BanCategory
+	keywords []string
+}
+
+// reasonRules are checked in order and the first match wins. Ban evasion comes first since those reasons
+// often also mention the original offence, while bot is checked after cheating so that "aimbot" is not
+// considered a bot.
+func reasonRules() []reasonRule {
+	return []reasonRule{
+		{
+			category: domain.CategoryBanEvasion,
+			keywords: []string{
+				"ban evasion", "ban evading", "evading", "evasion", "ban bypass", "bypassing ban", "alt account",
+				"alternate account", "alt ", "alts ", "обход бана", "обход блокировки", "твинк", "ban atlatma",
+				"yan hesap", "obcházení banu",
+			},
+		},
+		{
+			category: domain.CategoryCheating,
+			keywords: []string{
+				"cheat", "hack", "aimbot", "aim bot", "aimlock", "aim assist", "silent aim", "wallhack", "wall hack",
+				"wh ", "esp ", "triggerbot", "trigger bot", "spinbot", "spin bot", "speedhack", "norecoil",
+				"no recoil", "bhop script", "third party", "3rd party", "smac ", "lilac ", "stac ", "vac ",
+				"lmaobox", "nullcore", "ncc ", "чит", "аимбот", "аим ", "вх ", "валлхак", "hile", "podvád",
+				"trampa", "trapaça",
+			},
+		},
+		{
+			category: domain.CategoryBot,
+			keywords: []string{
+				"bot ", "bots ", "botting", "botter", "idle bot", "бот ", "боты ",
+			},
+		},
+		{
+			category: domain.CategoryExploiting,
+			keywords: []string{
+				"exploit", "glitch", "bug abuse", "bug abusing", "abusing bug", "bug using", "pixel walk",
+				"pixelwalk", "багоюз", "использование багов", "bug kullan", "zneužívání chyb", "zneužívání bugů",
+			},
+		},
+		{
+			category: domain.CategoryToxicity,
+			keywords: []string{
+				"racis", "rasis", "nigg", "slur", "homophob", "sexis", "toxic", "harass", "insult", "disrespect",
+				"threat", "doxx", "hate speech", "offensive", "nazi", "troll", "grief", "beleidig", "оскорб",
+				"расизм", "токсич", "мат ", "küfür", "hakaret", "ırkçı", "urážk", "urážen",
+			},
+		},
+		{
+			category: domain.CategorySpam,
+			keywords: []string{
+				"spam", "micspam", "flood", "advert", "soundboard", "loud mic", "earrape", "ear rape", "спам",
+				"флуд", "реклам", "reklam",
+			},
+		},
+	}
+}
+
+var rxReasonWords = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// classifyReason maps a free text ban reason onto one of the known ban categories.
+func classifyReason(reason string) domain.BanCategory {
+	// Turkish uppercase İ lowers into i + U+0307 which would otherwise split the word in two.
+	lowered := strings.ReplaceAll(strings.ToLower(reason), "\u0307", "")
+
+	words := rxReasonWords.FindAllString(lowered, -1)
+	if len(words) == 0 {
+		return domain.CategoryOther
+	}
+
+	normalized := " " + strings.Join(words, " ") + " "
+
+	for _, rule := range reasonRules() {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(normalized, " "+keyword) {
+				return rule.category
+			}
+		}
+	}
+
+	return domain.CategoryOther
+}
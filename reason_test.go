@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyReason(t *testing.T) {
+	t.Parallel()
+
+	for _, testCase := range []struct {
+		reason   string
+		expected domain.BanCategory
+	}{
+		{"aimbot", domain.CategoryCheating},
+		{"[SMAC] Aimbot Detected", domain.CategoryCheating},
+		{"Wallhack", domain.CategoryCheating},
+		{"WH", domain.CategoryCheating},
+		{"Читы", domain.CategoryCheating},
+		{"HİLE", domain.CategoryCheating},
+		{"hile kullanımı", domain.CategoryCheating},
+		{"Cheating (3rd party software)", domain.CategoryCheating},
+		{"racism", domain.CategoryToxicity},
+		{"Racist remarks in chat", domain.CategoryToxicity},
+		{"Оскорбление игроков", domain.CategoryToxicity},
+		{"küfür", domain.CategoryToxicity},
+		{"mic spam", domain.CategorySpam},
+		{"Micspam", domain.CategorySpam},
+		{"Спам", domain.CategorySpam},
+		{"Ban Evasion", domain.CategoryBanEvasion},
+		{"evading ban for aimbot", domain.CategoryBanEvasion},
+		{"Alt account", domain.CategoryBanEvasion},
+		{"bot", domain.CategoryBot},
+		{"Cheater bots", domain.CategoryCheating},
+		{"Bot hosting", domain.CategoryBot},
+		{"Exploiting", domain.CategoryExploiting},
+		{"Bug abuse", domain.CategoryExploiting},
+		{"both teams", domain.CategoryOther},
+		{"Especially annoying", domain.CategoryOther},
+		{"", domain.CategoryOther},
+		{"Web Ban", domain.CategoryOther},
+	} {
+		require.Equalf(t, testCase.expected, classifyReason(testCase.reason), "Reason: %s", testCase.reason)
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
 	"errors"
 	"log/slog"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -68,3 +71,20 @@ func NewScraper(database *pgStore, config appConfig, domain string) (*SiteScrape
 
 	return &scraper, nil
 }
+
+// uncacheResponse removes a cached response, so that the next request fetches it again. Colly caches every
+// response below 500, including rate limit responses.
+func uncacheResponse(cacheDir string, rawURL string) {
+	if cacheDir == "" {
+		return
+	}
+
+	// Must match the cache key used by colly.
+	sum := sha1.Sum([]byte(rawURL)) //nolint:gosec
+	hash := hex.EncodeToString(sum[:])
+
+	errRemove := os.Remove(filepath.Join(cacheDir, hash[:2], hash))
+	if errRemove != nil && !os.IsNotExist(errRemove) {
+		slog.Warn("Failed to remove cached response", slog.String("url", rawURL), ErrAttr(errRemove))
+	}
+}
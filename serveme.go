@@ -96,10 +96,11 @@ func downloadServeMeList(ctx context.Context) ([]domain.ServeMeRecord, error) {
 		}
 
 		records = append(records, domain.ServeMeRecord{
-			SteamID: sid,
-			Name:    row[1],
-			Reason:  row[2],
-			Deleted: false,
+			SteamID:        sid,
+			Name:           row[1],
+			Reason:         row[2],
+			ReasonCategory: classifyReason(row[2]),
+			Deleted:        false,
 			TimeStamped: domain.TimeStamped{
 				UpdatedOn: now,
 				CreatedOn: now,
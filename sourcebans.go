@@ -1,21 +1,21 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"github.com/gocolly/colly"
 	"github.com/gocolly/colly/debug"
 	"github.com/gocolly/colly/extensions"
@@ -32,8 +32,12 @@
 	errScrapeLimit        = errors.New("failed to set scraper limit")
 	errScrapeLauncherInit = errors.New("failed to setup browser launcher")
 	errScrapeWait         = errors.New("failed to wait for content load")
-	errScrapeCFOpen       = errors.New("could not open cloudflare transport")
 	errScrapeParseTime    = errors.New("failed to parse time value")
+	errScrapeParseLength  = errors.New("failed to parse ban length value")
+	errScrapeUnknownSite  = errors.New("unknown sourcebans site")
+	errScrapeCancelled    = errors.New("sourcebans scrape was cancelled")
+	errScrapeFixture      = errors.New("failed to fetch fixture")
+	errScrapeTimezone     = errors.New("failed to load site timezone")
 )
 
 type nextURLFunc func(scraper *sbScraper, doc *goquery.Selection) string
@@ -48,27 +52,70 @@ func initScrapers(ctx context.Context, database *pgStore, cacheDir string) ([]*s
 		return nil, errScrapers
 	}
 
+	enabled := make([]*sbScraper, 0, len(scrapers))
+
 	for _, scraper := range scrapers {
-		// Attach a site_id to the scraper, so we can keep track of the scrape source
-		var s domain.SbSite
-		if errSave := database.sourcebansSiteGetOrCreate(ctx, scraper.name, &s); errSave != nil {
-			return nil, errSave
+		site, errSite := scraper.attachSite(ctx, database)
+		if errSite != nil {
+			return nil, errSite
 		}
 
-		scraper.ID = uint32(s.SiteID)
+		if !site.Enabled {
+			slog.Info("Skipping disabled site", slog.String("name", string(scraper.name)))
+
+			continue
+		}
+
+		enabled = append(enabled, scraper)
 	}
 
-	return scrapers, nil
+	return enabled, nil
 }
 
-func runScrapers(ctx context.Context, database *pgStore, scrapers []*sbScraper) {
-	waitGroup := &sync.WaitGroup{}
+// findScraper creates the scraper for a single site.
+func findScraper(cacheDir string, name domain.Site) (*sbScraper, error) {
+	scrapers, errScrapers := createScrapers(cacheDir)
+	if errScrapers != nil {
+		return nil, errScrapers
+	}
+
+	for _, scraper := range scrapers {
+		if scraper.name == name {
+			return scraper, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", errScrapeUnknownSite, name)
+}
+
+// runScrapers crawls the sites, running at most maxSites at once. Sites that have not started when the
+// context is cancelled are skipped.
+func runScrapers(ctx context.Context, database *pgStore, scrapers []*sbScraper, maxSites int) {
+	if maxSites <= 0 {
+		maxSites = defaultMaxSites
+	}
+
+	var (
+		waitGroup = &sync.WaitGroup{}
+		running   = make(chan struct{}, maxSites)
+	)
 
 	for _, scraper := range scrapers {
+		select {
+		case <-ctx.Done():
+			waitGroup.Wait()
+
+			return
+		case running <- struct{}{}:
+		}
+
 		waitGroup.Add(1)
 
 		go func(s *sbScraper) {
-			defer waitGroup.Done()
+			defer func() {
+				<-running
+				waitGroup.Done()
+			}()
 
 			s.start(ctx, database)
 		}(scraper)
@@ -78,12 +125,19 @@ func runScrapers(ctx context.Context, database *pgStore, scrapers []*sbScraper)
 }
 
 type sbRecord struct {
-	Name      string
-	SteamID   steamid.SteamID
-	Reason    string
-	CreatedOn time.Time
-	Length    time.Duration
-	Permanent bool
+	Name           string          `json:"name"`
+	SteamID        steamid.SteamID `json:"steam_id"`
+	Reason         string          `json:"reason"`
+	CreatedOn      time.Time       `json:"created_on"`
+	Length         time.Duration   `json:"length"`
+	Permanent      bool            `json:"permanent"`
+	LengthMismatch bool            `json:"length_mismatch"`
+	Admin          string          `json:"admin"`
+	Server         string          `json:"server"`
+	TotalBans      int             `json:"total_bans"`
+	DemoURL        string          `json:"demo_url"`
+	// expiresOn is kept so the length can be checked once both columns are known, whichever comes first.
+	expiresOn time.Time
 }
 
 func (r *sbRecord) setPlayer(name string) {
@@ -101,21 +155,35 @@ func (r *sbRecord) setInvokedOn(parseTime parseTimeFunc, value string) error {
 	}
 
 	r.CreatedOn = parsedTime
+	r.checkLength()
 
 	return nil
 }
 
-func (r *sbRecord) setBanLength(value string) {
-	lowerVal := strings.ToLower(value)
-	if strings.Contains(lowerVal, "unbanned") {
+func (r *sbRecord) setBanLength(norm *normalizer, value string) error {
+	length, errLength := norm.banLength(value)
+	if errLength != nil {
+		return errLength
+	}
+
+	switch {
+	case length.unbanned:
 		r.SteamID = steamid.SteamID{} // invalidate it
-	} else if lowerVal == "permanent" {
+	case length.permanent:
 		r.Permanent = true
+		r.Length = 0
+	default:
+		r.Length = length.duration
+		r.checkLength()
 	}
 
-	r.Length = 0
+	return nil
 }
 
+// maxLengthDrift is how far the expiry date may stray from the displayed ban length before the
+// record is flagged. Sites render times in local time, so this absorbs a DST shift.
+const maxLengthDrift = time.Hour
+
 func (r *sbRecord) setExpiredOn(parseTime parseTimeFunc, value string) error {
 	if r.Permanent || !r.SteamID.Valid() {
 		// Ignore when
@@ -127,14 +195,40 @@ func (r *sbRecord) setExpiredOn(parseTime parseTimeFunc, value string) error {
 		return errTime
 	}
 
-	r.Length = parsedTime.Sub(r.CreatedOn)
+	r.expiresOn = parsedTime
+	r.checkLength()
 
-	if r.Length < 0 {
+	return nil
+}
+
+// checkLength compares the ban length against the expiry date once the created, length and expiry columns
+// have been parsed. Sites order these columns differently, so it is run after each of them is set.
+func (r *sbRecord) checkLength() {
+	if r.Permanent || !r.SteamID.Valid() || r.CreatedOn.IsZero() || r.expiresOn.IsZero() {
+		return
+	}
+
+	expiredLength := r.expiresOn.Sub(r.CreatedOn)
+
+	if expiredLength < 0 {
 		// Some temp ban/actions use a negative duration?, just invalidate these
 		r.SteamID = steamid.SteamID{}
+
+		return
 	}
 
-	return nil
+	if r.Length == 0 {
+		// The ban length was hidden or unparsable, fall back to the expiry date.
+		r.Length = expiredLength
+
+		return
+	}
+
+	r.LengthMismatch = false
+
+	if drift := r.Length - expiredLength; drift > maxLengthDrift || drift < -maxLengthDrift {
+		r.LengthMismatch = true
+	}
 }
 
 func (r *sbRecord) setReason(value string) {
@@ -145,6 +239,45 @@ func (r *sbRecord) setReason(value string) {
 	r.Reason = value
 }
 
+func (r *sbRecord) setAdmin(value string) {
+	if value == "" {
+		return
+	}
+
+	r.Admin = value
+}
+
+// setServer records the server the ban was issued from. Most themes only render a "Please Wait..."
+// placeholder which gets filled in via xajax, so the page wide server list is used to resolve it instead.
+func (r *sbRecord) setServer(meta sbPageMeta, selection *goquery.Selection, value string) {
+	if banID, found := meta.banID(selection); found {
+		r.DemoURL = meta.demos[banID]
+
+		if serverID, foundServer := meta.banServers[banID]; foundServer {
+			r.Server = meta.serverAddrs[serverID]
+		}
+	}
+
+	if value != "" && !strings.HasSuffix(value, "...") {
+		r.Server = value
+	}
+}
+
+func (r *sbRecord) setTotalBans(value string) {
+	// Values look like: "2 (search)" or "No previous bans"
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return
+	}
+
+	count, errCount := strconv.Atoi(fields[0])
+	if errCount != nil {
+		return
+	}
+
+	r.TotalBans = count
+}
+
 func (r *sbRecord) setSteam(value string) {
 	if r.SteamID.Valid() {
 		return
@@ -163,11 +296,21 @@ type sbScraper struct {
 	queue     *queue.Queue
 	resultsMu sync.RWMutex
 	baseURL   string
-	sleepTime time.Duration
 	startPath string
 	parser    parserFunc
 	nextURL   nextURLFunc
 	parseTIme parseTimeFunc
+	// dryRun disables saving any results to the database
+	dryRun bool
+	// maxPages limits how many pages are visited, 0 for no limit
+	maxPages int
+	// browser is set for sites that must be fetched using a real browser
+	browser *cfTransport
+	// transport retries rate limited requests
+	transport *backoffTransport
+	policy    sbPolicy
+	// location is the timezone the site renders its times in, loaded from the sites sb_site row
+	location *time.Location
 }
 
 func createScrapers(cacheDir string) ([]*sbScraper, error) {
@@ -228,12 +371,46 @@ func (scraper *sbScraper) start(ctx context.Context, database *pgStore) {
 	slog.Info("Starting scrape job",
 		slog.String("name", string(scraper.name)), slog.String("theme", scraper.theme))
 
-	lastURL := ""
-	startTime := time.Now()
-	totalErrorCount := 0
+	var (
+		lastURL         = ""
+		startURL        = scraper.url(scraper.startPath)
+		page            = 1
+		pageCount       = 1
+		startTime       = time.Now()
+		totalErrorCount = 0
+		finished        = false
+	)
+
+	if !scraper.policy.allowedAt(time.Now(), scraper.location) {
+		slog.Info("Skipping scrape job outside of allowed hours", slog.String("name", string(scraper.name)))
+
+		return
+	}
+
+	scraper.transport.ctx = ctx
+
+	if !scraper.dryRun {
+		if checkpoint, found := scraper.loadCheckpoint(ctx, database); found {
+			startURL = checkpoint.URL
+			page = checkpoint.Page
+			scraper.curPage = checkpoint.Page
+		}
+	}
+
+	scraper.Collector.OnRequest(func(request *colly.Request) {
+		if ctx.Err() != nil {
+			request.Abort()
+		}
+	})
 
 	scraper.Collector.OnHTML("body", func(element *colly.HTMLElement) {
-		results, errorCount, parseErr := scraper.parser(element.DOM, scraper.log, scraper.parseTIme)
+		if ctx.Err() != nil {
+			return
+		}
+
+		parseTime := inLocation(scraper.parseTIme, scraper.location)
+
+		results, errorCount, parseErr := scraper.parser(element.DOM, scraper.log, parseTime)
 		if parseErr != nil {
 			slog.Error("Parser returned error", ErrAttr(parseErr))
 
@@ -244,55 +421,41 @@ func (scraper *sbScraper) start(ctx context.Context, database *pgStore) {
 		scraper.resultsMu.Lock()
 		scraper.results = append(scraper.results, results...)
 		scraper.resultsMu.Unlock()
-		for _, result := range results {
-			pRecord := newPlayerRecord(result.SteamID)
-			if errPlayer := database.playerGetOrCreate(ctx, result.SteamID, &pRecord); errPlayer != nil {
-				slog.Error("failed to get player record", slog.String("sid64", result.SteamID.String()), ErrAttr(errPlayer))
-
-				continue
-			}
+		if !scraper.dryRun {
+			scraper.saveResults(ctx, database, results)
+		}
+		if nextURL == "" || nextURL == lastURL {
+			finished = true
 
-			bRecord := domain.SbBanRecord{
-				BanID:       0,
-				SiteName:    "",
-				SiteID:      int(scraper.ID),
-				PersonaName: result.Name,
-				SteamID:     pRecord.SteamID,
-				Reason:      result.Reason,
-				Duration:    result.Length,
-				Permanent:   result.Permanent,
-				TimeStamped: domain.TimeStamped{
-					UpdatedOn: time.Now(),
-					CreatedOn: result.CreatedOn,
-				},
-			}
+			return
+		}
+		if scraper.maxPages > 0 && pageCount >= scraper.maxPages {
+			// Stopping at the page limit is not an interruption, the next run should start from the beginning.
+			finished = true
 
-			if errBanSave := database.sourcebansBanRecordSave(ctx, &bRecord); errBanSave != nil {
-				if errors.Is(errBanSave, errDatabaseUnique) {
-					// slog.Debug("Failed to save ban record (duplicate)",
-					//	slog.String("sid64", pRecord.SteamID.String()), ErrAttr(errBanSave))
+			return
+		}
+		lastURL = nextURL
+		page++
+		pageCount++
+		if !scraper.dryRun {
+			scraper.saveCheckpoint(ctx, database, page, nextURL)
+		}
+		if !scraper.policy.allowedAt(time.Now(), scraper.location) {
+			slog.Info("Pausing scrape job outside of allowed hours", slog.String("name", string(scraper.name)),
+				slog.Int("page", page))
 
-					continue
-				}
-				slog.Error("Failed to save ban record",
-					slog.String("sid64", pRecord.SteamID.String()), ErrAttr(errBanSave))
-			}
+			return
 		}
-		if nextURL != "" && nextURL != lastURL {
-			lastURL = nextURL
-			if scraper.sleepTime > 0 {
-				time.Sleep(scraper.sleepTime)
-			}
-			slog.Debug("Visiting next url", slog.String("url", nextURL))
-			if errAdd := scraper.queue.AddURL(nextURL); errAdd != nil {
-				slog.Error("Failed to add queue error", ErrAttr(errAdd))
+		slog.Debug("Visiting next url", slog.String("url", nextURL))
+		if errAdd := scraper.queue.AddURL(nextURL); errAdd != nil {
+			slog.Error("Failed to add queue error", ErrAttr(errAdd))
 
-				return
-			}
+			return
 		}
 	})
 
-	if errAdd := scraper.queue.AddURL(scraper.url(scraper.startPath)); errAdd != nil {
+	if errAdd := scraper.queue.AddURL(startURL); errAdd != nil {
 		slog.Error("Failed to add queue error", ErrAttr(errAdd))
 
 		return
@@ -304,11 +467,124 @@ func (scraper *sbScraper) start(ctx context.Context, database *pgStore) {
 		return
 	}
 
+	if ctx.Err() != nil {
+		slog.Warn("Cancelled scrape job", slog.String("name", string(scraper.name)),
+			slog.Int("page", page), slog.Duration("duration", time.Since(startTime)))
+
+		return
+	}
+
+	if finished && !scraper.dryRun {
+		if errDelete := database.sourcebansCheckpointDelete(ctx, int(scraper.ID)); errDelete != nil {
+			slog.Error("Failed to delete checkpoint", slog.String("name", string(scraper.name)), ErrAttr(errDelete))
+		}
+
+		if errScraped := database.sourcebansSiteScraped(ctx, int(scraper.ID)); errScraped != nil {
+			slog.Error("Failed to update last scrape time", slog.String("name", string(scraper.name)),
+				ErrAttr(errScraped))
+		}
+	}
+
 	slog.Info("Completed scrape job", slog.String("name", string(scraper.name)),
 		slog.Int("valid", len(scraper.results)), slog.Int("skipped", totalErrorCount),
 		slog.Duration("duration", time.Since(startTime)))
 }
 
+// loadCheckpoint fetches the page to resume the crawl from, if a previous crawl was interrupted.
+func (scraper *sbScraper) loadCheckpoint(ctx context.Context, database *pgStore) (sbCheckpoint, bool) {
+	checkpoint, errCheckpoint := database.sourcebansCheckpointGet(ctx, int(scraper.ID))
+	if errCheckpoint != nil {
+		if !errors.Is(errCheckpoint, errDatabaseNoResults) {
+			slog.Error("Failed to load checkpoint", slog.String("name", string(scraper.name)), ErrAttr(errCheckpoint))
+		}
+
+		return checkpoint, false
+	}
+
+	// Only resume from pages of the current site, the base url may have changed since.
+	if !strings.HasPrefix(checkpoint.URL, scraper.baseURL) {
+		return checkpoint, false
+	}
+
+	slog.Info("Resuming scrape job", slog.String("name", string(scraper.name)),
+		slog.Int("page", checkpoint.Page), slog.String("url", checkpoint.URL))
+
+	return checkpoint, true
+}
+
+func (scraper *sbScraper) saveCheckpoint(ctx context.Context, database *pgStore, page int, nextURL string) {
+	checkpoint := sbCheckpoint{SiteID: int(scraper.ID), Page: page, URL: nextURL} //nolint:exhaustruct
+	if errSave := database.sourcebansCheckpointSave(ctx, &checkpoint); errSave != nil {
+		slog.Error("Failed to save checkpoint", slog.String("name", string(scraper.name)), ErrAttr(errSave))
+	}
+}
+
+// attachSite attaches a site_id to the scraper, so we can keep track of the scrape source.
+func (scraper *sbScraper) attachSite(ctx context.Context, database *pgStore) (domain.SbSite, error) {
+	var site domain.SbSite
+	if errSave := database.sourcebansSiteGetOrCreate(ctx, scraper.name, &site); errSave != nil {
+		return site, errSave
+	}
+
+	scraper.ID = uint32(site.SiteID)
+
+	if site.Timezone == "" {
+		slog.Warn("Site has no timezone set, assuming UTC", slog.String("name", string(scraper.name)))
+	}
+
+	location, errLocation := siteLocation(site.Timezone)
+	if errLocation != nil {
+		return site, errLocation
+	}
+
+	scraper.location = location
+
+	return site, nil
+}
+
+func (scraper *sbScraper) saveResults(ctx context.Context, database *pgStore, results []sbRecord) {
+	for _, result := range results {
+		pRecord := newPlayerRecord(result.SteamID)
+		if errPlayer := database.playerGetOrCreate(ctx, result.SteamID, &pRecord); errPlayer != nil {
+			slog.Error("failed to get player record", slog.String("sid64", result.SteamID.String()), ErrAttr(errPlayer))
+
+			continue
+		}
+
+		bRecord := domain.SbBanRecord{
+			BanID:            0,
+			SiteName:         "",
+			SiteID:           int(scraper.ID),
+			PersonaName:      result.Name,
+			SteamID:          pRecord.SteamID,
+			Reason:           result.Reason,
+			ReasonCategory:   classifyReason(result.Reason),
+			Duration:         result.Length,
+			Permanent:        result.Permanent,
+			DurationMismatch: result.LengthMismatch,
+			AdminName:        result.Admin,
+			Server:           result.Server,
+			TotalBans:        result.TotalBans,
+			DemoURL:          scraper.demoURL(result.DemoURL),
+			TimeStamped: domain.TimeStamped{
+				UpdatedOn: time.Now(),
+				CreatedOn: result.CreatedOn,
+			},
+		}
+
+		if errBanSave := database.sourcebansBanRecordSave(ctx, &bRecord); errBanSave != nil {
+			if errors.Is(errBanSave, errDatabaseUnique) {
+				// slog.Debug("Failed to save ban record (duplicate)",
+				//	slog.String("sid64", pRecord.SteamID.String()), ErrAttr(errBanSave))
+
+				continue
+			}
+			slog.Error("Failed to save ban record",
+				slog.String("sid64", pRecord.SteamID.String()), ErrAttr(errBanSave))
+		}
+	}
+}
+
 type scrapeLogger struct {
 	logger *slog.Logger
 	start  time.Time
@@ -341,33 +617,49 @@ func (log *scrapeLogger) Event(event *debug.Event) {
 	}
 }
 
-const defaultStartPath = "index.php?p=banlist"
+const (
+	defaultStartPath = "index.php?p=banlist"
+	// defaultMaxSites is the default number of sites crawled at the same time.
+	defaultMaxSites = 8
+)
 
-func newScraperWithTransport(cacheDir string, name domain.Site,
+// newBrowserScraper creates a scraper which fetches pages using a real browser. This is required for
+// sites behind cloudflare bot protection. A browser pool must be attached with setBrowserPool before use.
+func newBrowserScraper(cacheDir string, name domain.Site,
 	baseURL string, startPath string, parser parserFunc, nextURL nextURLFunc, parseTime parseTimeFunc,
-	transport http.RoundTripper,
 ) (*sbScraper, error) {
 	scraper, errScraper := newScraper(cacheDir, name, baseURL, startPath, parser, nextURL, parseTime)
 	if errScraper != nil {
 		return nil, errScraper
 	}
 
-	scraper.Collector.WithTransport(transport)
+	scraper.browser = newCFTransport(string(name))
+	scraper.transport.next = scraper.browser
 
 	return scraper, nil
 }
 
-const (
-	randomDelay    = 5 * time.Second
-	requestTimeout = time.Second * 30
-)
+// setBrowserPool attaches the browser pool used by browser backed scrapers. It does nothing for
+// regular scrapers.
+func (scraper *sbScraper) setBrowserPool(pool *browserPool) {
+	if scraper.browser == nil {
+		return
+	}
+
+	scraper.browser.pool = pool
+}
+
+const requestTimeout = time.Second * 30
 
 func newSourcebansSite(name domain.Site) domain.SbSite {
 	createdOn := time.Now()
 
 	return domain.SbSite{
-		SiteID: 0,
-		Name:   name,
+		SiteID:        0,
+		Name:          name,
+		Enabled:       true,
+		LastScrapedOn: nil,
+		Timezone:      "",
 		TimeStamped: domain.TimeStamped{
 			UpdatedOn: createdOn,
 			CreatedOn: createdOn,
@@ -379,14 +671,15 @@ func newSourcebansRecord(site domain.SbSite, sid64 steamid.SteamID, personaName
 	timeStamp time.Time, duration time.Duration, perm bool,
 ) domain.SbBanRecord {
 	return domain.SbBanRecord{
-		BanID:       0,
-		SiteName:    site.Name,
-		SiteID:      site.SiteID,
-		PersonaName: personaName,
-		SteamID:     sid64,
-		Reason:      reason,
-		Duration:    duration,
-		Permanent:   perm,
+		BanID:          0,
+		SiteName:       site.Name,
+		SiteID:         site.SiteID,
+		PersonaName:    personaName,
+		SteamID:        sid64,
+		Reason:         reason,
+		ReasonCategory: classifyReason(reason),
+		Duration:       duration,
+		Permanent:      perm,
 		TimeStamped: domain.TimeStamped{
 			UpdatedOn: timeStamp,
 			CreatedOn: timeStamp,
@@ -420,6 +713,8 @@ func newScraper(cacheDir string, name domain.Site, baseURL string,
 		colly.AllowedDomains(parsedURL.Hostname()),
 	)
 
+	policy := sitePolicy(name)
+
 	scraper := sbScraper{ //nolint:exhaustruct
 		baseURL:   baseURL,
 		name:      name,
@@ -432,24 +727,39 @@ func newScraper(cacheDir string, name domain.Site, baseURL string,
 		nextURL:   nextURL,
 		parseTIme: parseTime,
 		Collector: collector,
+		transport: newBackoffTransport(http.DefaultTransport),
+		policy:    policy,
+		location:  time.UTC,
+		maxPages:  policy.maxPages,
 	}
 
 	scraper.SetRequestTimeout(requestTimeout)
+	scraper.WithTransport(scraper.transport)
+	scraper.IgnoreRobotsTxt = !policy.robotsTxt
 	scraper.OnRequest(func(r *colly.Request) {
 		slog.Debug("Visiting", slog.String("url", r.URL.String()))
 	})
-	extensions.RandomUserAgent(scraper.Collector)
+
+	if policy.userAgent != "" {
+		scraper.UserAgent = policy.userAgent
+	} else {
+		extensions.RandomUserAgent(scraper.Collector)
+	}
 
 	if errLimit := scraper.Limit(&colly.LimitRule{ //nolint:exhaustruct
 		DomainGlob:  "*" + parsedURL.Hostname(),
-		RandomDelay: randomDelay,
-		Delay:       time.Second,
+		RandomDelay: policy.randomDelay,
+		Delay:       policy.delay,
 	}); errLimit != nil {
 		return nil, errors.Join(errLimit, errScrapeLimit)
 	}
 
 	scraper.OnError(func(r *colly.Response, err error) {
 		slog.Error("Request error", slog.String("url", r.Request.URL.String()), ErrAttr(err))
+
+		if r.StatusCode == http.StatusTooManyRequests {
+			uncacheResponse(scraper.CacheDir, r.Request.URL.String())
+		}
 	})
 
 	return &scraper, nil
@@ -459,6 +769,68 @@ func (scraper *sbScraper) url(path string) string {
 	return scraper.baseURL + path
 }
 
+// fetchFixture downloads the first ban list page into outDir/<name>.html, bypassing the response cache,
+// so that it can be used as a parser test fixture.
+func (scraper *sbScraper) fetchFixture(outDir string) (string, error) {
+	var (
+		body     []byte
+		errFetch error
+	)
+
+	scraper.Collector.CacheDir = ""
+	scraper.Collector.OnResponse(func(response *colly.Response) {
+		body = response.Body
+	})
+	scraper.Collector.OnError(func(_ *colly.Response, err error) {
+		errFetch = err
+	})
+
+	if errVisit := scraper.Visit(scraper.url(scraper.startPath)); errVisit != nil {
+		return "", errors.Join(errVisit, errScrapeFixture)
+	}
+
+	if errFetch != nil {
+		return "", errors.Join(errFetch, errScrapeFixture)
+	}
+
+	if len(body) == 0 {
+		return "", errScrapeFixture
+	}
+
+	outPath := filepath.Join(outDir, string(scraper.name)+".html")
+	if errWrite := os.WriteFile(outPath, body, 0o600); errWrite != nil {
+		return "", errors.Join(errWrite, errScrapeFixture)
+	}
+
+	return outPath, nil
+}
+
+func (scraper *sbScraper) demoURL(path string) string {
+	if path == "" || strings.HasPrefix(path, "http") {
+		return path
+	}
+
+	return scraper.url(path)
+}
+
+// inLocation wraps a time parser so the wall clock times it returns are interpreted in the sites location
+// and converted to UTC. Times that were parsed with a known, non-zero, offset are only converted to UTC.
+func inLocation(parseTime parseTimeFunc, location *time.Location) parseTimeFunc {
+	return func(timeStr string) (time.Time, error) {
+		parsed, errParse := parseTime(timeStr)
+		if errParse != nil || parsed.IsZero() {
+			return parsed, errParse
+		}
+
+		if _, offset := parsed.Zone(); offset != 0 {
+			return parsed.UTC(), nil
+		}
+
+		return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), parsed.Hour(), parsed.Minute(),
+			parsed.Second(), parsed.Nanosecond(), location).UTC(), nil
+	}
+}
+
 func doTimeParse(layout string, timeStr string) (time.Time, error) {
 	parsedTime, errParse := time.Parse(layout, timeStr)
 	if errParse != nil {
@@ -730,47 +1102,178 @@ func nextURLLast(scraper *sbScraper, doc *goquery.Selection) string {
 	keyBanLength      mappedKey = "ban length"
 	keyExpiredOn      mappedKey = "expires on"
 
-	keyReason   mappedKey = "reason"
-	keySteam3ID mappedKey = "steam3"
-	keyPlayer   mappedKey = "player"
+	keyReason    mappedKey = "reason"
+	keySteam3ID  mappedKey = "steam3"
+	keyPlayer    mappedKey = "player"
+	keyAdmin     mappedKey = "admin"
+	keyServer    mappedKey = "server"
+	keyTotalBans mappedKey = "total bans"
+)
+
+type lengthFlag int
+
+const (
+	lengthPermanent lengthFlag = iota + 1
+	lengthUnbanned
+)
+
+// Sourcebans renders lengths with a fixed 30 day month.
+const (
+	lengthDay   = time.Hour * 24
+	lengthWeek  = lengthDay * 7
+	lengthMonth = lengthDay * 30
+	lengthYear  = lengthDay * 365
 )
 
 type normalizer struct {
-	keyMap  map[string]mappedKey
-	spaceRm *regexp.Regexp
+	keyMap     map[string]mappedKey
+	unitMap    map[string]time.Duration
+	flagMap    map[string]lengthFlag
+	spaceRm    *regexp.Regexp
+	lengthToks *regexp.Regexp
 }
 
 func newNormalizer() *normalizer {
 	return &normalizer{
-		spaceRm: regexp.MustCompile(`\s+`),
+		spaceRm:    regexp.MustCompile(`\s+`),
+		lengthToks: regexp.MustCompile(`\d+|\p{L}+`),
 		keyMap: map[string]mappedKey{
-			"community links":      "community links",
-			"banlanma tarihi":      "invoked on",
-			"был выдан":            "invoked on",
-			"datum a čas udělení":  "invoked on",
-			"invoked on":           "invoked on",
-			"steam community":      "steam community",
-			"steam komunitní":      "steam community",
-			"ban uzunluğu":         "ban length",
-			"délka":                "ban length",
-			"banlength":            "ban length",
-			"ban length":           "ban length",
-			"длительность":         "ban length",
-			"şu zaman sona eriyor": "expires on",
-			"vyprší":               "expires on",
-			"будет снят":           "expires on",
-			"expires on":           "expires on",
-			"причина разбана":      "reason unbanned",
-			"sebep":                "reason",
-			"důvod":                "reason",
-			"reason":               "reason",
-			"разбанен админом":     "unbanned by",
-			"причина бана":         "reason",
-			"oyuncu":               "player",
-			"игрок":                "player",
-			"player":               "player",
-			"steam3 id":            "steam3",
-			"steam id":             "steam",
+			"community links":              "community links",
+			"banlanma tarihi":              "invoked on",
+			"был выдан":                    "invoked on",
+			"datum a čas udělení":          "invoked on",
+			"invoked on":                   "invoked on",
+			"steam community":              "steam community",
+			"steam komunitní":              "steam community",
+			"ban uzunluğu":                 "ban length",
+			"délka":                        "ban length",
+			"banlength":                    "ban length",
+			"ban length":                   "ban length",
+			"длительность":                 "ban length",
+			"şu zaman sona eriyor":         "expires on",
+			"vyprší":                       "expires on",
+			"будет снят":                   "expires on",
+			"expires on":                   "expires on",
+			"причина разбана":              "reason unbanned",
+			"sebep":                        "reason",
+			"důvod":                        "reason",
+			"reason":                       "reason",
+			"разбанен админом":             "unbanned by",
+			"причина бана":                 "reason",
+			"oyuncu":                       "player",
+			"игрок":                        "player",
+			"player":                       "player",
+			"steam3 id":                    "steam3",
+			"steam id":                     "steam",
+			"banned by admin":              "admin",
+			"banned by":                    "admin",
+			"bu admin tarafından banlandı": "admin",
+			"zabanoval admin":              "admin",
+			"banned from":                  "server",
+			"şurdan banlandı":              "server",
+			"místo banu":                   "server",
+			"сервер":                       "server",
+			"server":                       "server",
+			"total bans":                   "total bans",
+			"toplam ban sayısı":            "total bans",
+			"celkem banů":                  "total bans",
+			"предыдущие баны":              "total bans",
+		},
+		unitMap: map[string]time.Duration{
+			"sec":     time.Second,
+			"secs":    time.Second,
+			"second":  time.Second,
+			"seconds": time.Second,
+			"сек":     time.Second,
+			"секунда": time.Second,
+			"секунды": time.Second,
+			"секунд":  time.Second,
+			"saniye":  time.Second,
+			"sekunda": time.Second,
+			"sekundy": time.Second,
+			"sekund":  time.Second,
+			"min":     time.Minute,
+			"mins":    time.Minute,
+			"minute":  time.Minute,
+			"minutes": time.Minute,
+			"мин":     time.Minute,
+			"минута":  time.Minute,
+			"минуты":  time.Minute,
+			"минут":   time.Minute,
+			"dakika":  time.Minute,
+			"dk":      time.Minute,
+			"minuta":  time.Minute,
+			"minuty":  time.Minute,
+			"minut":   time.Minute,
+			"hr":      time.Hour,
+			"hrs":     time.Hour,
+			"hour":    time.Hour,
+			"hours":   time.Hour,
+			"ч":       time.Hour,
+			"час":     time.Hour,
+			"часа":    time.Hour,
+			"часов":   time.Hour,
+			"saat":    time.Hour,
+			"hodina":  time.Hour,
+			"hodiny":  time.Hour,
+			"hodin":   time.Hour,
+			"d":       lengthDay,
+			"day":     lengthDay,
+			"days":    lengthDay,
+			"дн":      lengthDay,
+			"день":    lengthDay,
+			"дня":     lengthDay,
+			"дней":    lengthDay,
+			"gün":     lengthDay,
+			"den":     lengthDay,
+			"dny":     lengthDay,
+			"dní":     lengthDay,
+			"dnů":     lengthDay,
+			"wk":      lengthWeek,
+			"wks":     lengthWeek,
+			"week":    lengthWeek,
+			"weeks":   lengthWeek,
+			"нед":     lengthWeek,
+			"неделя":  lengthWeek,
+			"недели":  lengthWeek,
+			"недель":  lengthWeek,
+			"hafta":   lengthWeek,
+			"týden":   lengthWeek,
+			"týdny":   lengthWeek,
+			"týdnů":   lengthWeek,
+			"mo":      lengthMonth,
+			"month":   lengthMonth,
+			"months":  lengthMonth,
+			"мес":     lengthMonth,
+			"месяц":   lengthMonth,
+			"месяца":  lengthMonth,
+			"месяцев": lengthMonth,
+			"ay":      lengthMonth,
+			"měsíc":   lengthMonth,
+			"měsíce":  lengthMonth,
+			"měsíců":  lengthMonth,
+			"yr":      lengthYear,
+			"year":    lengthYear,
+			"years":   lengthYear,
+			"год":     lengthYear,
+			"года":    lengthYear,
+			"лет":     lengthYear,
+			"yıl":     lengthYear,
+			"rok":     lengthYear,
+			"roky":    lengthYear,
+			"let":     lengthYear,
+		},
+		flagMap: map[string]lengthFlag{
+			"permanent":   lengthPermanent,
+			"навсегда":    lengthPermanent,
+			"kalıcı":      lengthPermanent,
+			"süresiz":     lengthPermanent,
+			"trvalý":      lengthPermanent,
+			"permanentní": lengthPermanent,
+			"unbanned":    lengthUnbanned,
+			"разбанен":    lengthUnbanned,
+			"kaldırıldı":  lengthUnbanned,
+			"odbanován":   lengthUnbanned,
 		},
 	}
 }
@@ -785,15 +1288,137 @@ func (n normalizer) getMappedKey(s string) (mappedKey, bool) {
 	return mk, found
 }
 
+var (
+	rxHostPlayers = regexp.MustCompile(`ServerHostPlayers\('?(\d+)'?,\s*(?:'id',\s*'host_)?(\d+)`)
+	rxServerAddr  = regexp.MustCompile(`\(([^)]+)\)`)
+	rxBanRef      = regexp.MustCompile(`^(?:host|ban_server)_(\d+)$`)
+	rxDemoID      = regexp.MustCompile(`[?&]id=(\d+)`)
+)
+
+// sbPageMeta holds the page wide lookups needed to resolve details that are only referenced by id
+// within each ban, such as the server address and demo download.
+type sbPageMeta struct {
+	serverAddrs map[int]string
+	banServers  map[int]int
+	demos       map[int]string
+}
+
+func newSbPageMeta(doc *goquery.Selection) sbPageMeta {
+	meta := sbPageMeta{
+		serverAddrs: map[int]string{},
+		banServers:  map[int]int{},
+		demos:       map[int]string{},
+	}
+
+	// <option value="1" id="ss1">Retrieving Hostname... (216.52.148.47:27015)</option>
+	doc.Find("select#server option[id^=ss]").Each(func(_ int, selection *goquery.Selection) {
+		serverID, errID := strconv.Atoi(selection.AttrOr("value", ""))
+		if errID != nil {
+			return
+		}
+
+		if match := rxServerAddr.FindStringSubmatch(selection.Text()); match != nil {
+			meta.serverAddrs[serverID] = strings.TrimSpace(match[1])
+		}
+	})
+
+	// xajax_ServerHostPlayers(902, 'id', 'host_88813') / xajax_ServerHostPlayers(2, 899)
+	doc.Find("[onclick*=ServerHostPlayers]").Each(func(_ int, selection *goquery.Selection) {
+		match := rxHostPlayers.FindStringSubmatch(selection.AttrOr("onclick", ""))
+		if match == nil {
+			return
+		}
+
+		serverID, _ := strconv.Atoi(match[1])
+		banID, _ := strconv.Atoi(match[2])
+		meta.banServers[banID] = serverID
+	})
+
+	// getdemo.php?type=B&id=56799
+	doc.Find("a[href*=getdemo]").Each(func(_ int, selection *goquery.Selection) {
+		href := selection.AttrOr("href", "")
+		if match := rxDemoID.FindStringSubmatch(href); match != nil {
+			banID, _ := strconv.Atoi(match[1])
+			meta.demos[banID] = href
+		}
+	})
+
+	return meta
+}
+
+// banID finds the ban id from the host_N/ban_server_N id attached to the "banned from" value.
+func (m sbPageMeta) banID(selection *goquery.Selection) (int, bool) {
+	for _, node := range selection.Find("[id]").AddSelection(selection).Nodes {
+		for _, attr := range node.Attr {
+			if attr.Key != "id" {
+				continue
+			}
+
+			if match := rxBanRef.FindStringSubmatch(attr.Val); match != nil {
+				banID, errID := strconv.Atoi(match[1])
+
+				return banID, errID == nil
+			}
+		}
+	}
+
+	return 0, false
+}
+
+type banLength struct {
+	duration  time.Duration
+	permanent bool
+	unbanned  bool
+}
+
+// banLength parses the localized ban length column, eg: "2 wk, 3 d", "1 month", "Навсегда" or "30 minut".
+// Words that are neither a unit nor a known flag, like "(Expired)", are ignored.
+func (n normalizer) banLength(value string) (banLength, error) {
+	var (
+		length  banLength
+		amount  int64
+		pending bool
+	)
+
+	for _, tok := range n.lengthToks.FindAllString(strings.ToLower(value), -1) {
+		if num, errNum := strconv.ParseInt(tok, 10, 64); errNum == nil {
+			amount = num
+			pending = true
+
+			continue
+		}
+
+		switch n.flagMap[tok] {
+		case lengthPermanent:
+			length.permanent = true
+		case lengthUnbanned:
+			length.unbanned = true
+		default:
+			if unit, found := n.unitMap[tok]; found && pending {
+				length.duration += time.Duration(amount) * unit
+				pending = false
+			}
+		}
+	}
+
+	if !length.permanent && !length.unbanned && length.duration == 0 {
+		return length, fmt.Errorf("%w: %s", errScrapeParseLength, value)
+	}
+
+	return length, nil
+}
+
 // https://github.com/SB-MaterialAdmin/Web/tree/stable-dev
 func parseMaterial(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeFunc) ([]sbRecord, int, error) {
 	var (
 		bans      []sbRecord
 		curBan    sbRecord
+		lastBan   *sbRecord
 		skipCount int
 	)
 
 	norm := newNormalizer()
+	meta := newSbPageMeta(doc)
 
 	doc.Find("div.opener .card-body").Each(func(_ int, selection *goquery.Selection) {
 		selection.First().Children().Children().Each(func(_ int, selection *goquery.Selection) {
@@ -826,7 +1451,10 @@ func parseMaterial(doc *goquery.Selection, log *slog.Logger, parseTime parseTime
 					log.Error("failed to set invoke time", slog.String("input", value), ErrAttr(errInvoke))
 				}
 			case keyBanLength:
-				curBan.setBanLength(value)
+				if errLength := curBan.setBanLength(norm, value); errLength != nil {
+					// Falls back to the expiry date
+					log.Debug("failed to parse ban length", slog.String("input", value), ErrAttr(errLength))
+				}
 			case keyExpiredOn:
 				if errExpiration := curBan.setExpiredOn(parseTime, value); errExpiration != nil {
 					log.Error("failed to set expiration time", slog.String("input", value), ErrAttr(errExpiration))
@@ -836,10 +1464,25 @@ func parseMaterial(doc *goquery.Selection, log *slog.Logger, parseTime parseTime
 				curBan.Reason = value
 				if curBan.SteamID.Valid() && curBan.Name != "" {
 					bans = append(bans, curBan)
+					// The remaining details are rendered after the reason
+					lastBan = &bans[len(bans)-1]
 				} else {
 					skipCount++
+					lastBan = nil
 				}
 				curBan = sbRecord{} //nolint:exhaustruct
+			case keyAdmin:
+				if lastBan != nil {
+					lastBan.setAdmin(value)
+				}
+			case keyServer:
+				if lastBan != nil {
+					lastBan.setServer(meta, second, value)
+				}
+			case keyTotalBans:
+				if lastBan != nil {
+					lastBan.setTotalBans(value)
+				}
 			}
 		})
 	})
@@ -854,10 +1497,12 @@ func parseStar(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeFunc
 	var (
 		bans      []sbRecord
 		curBan    sbRecord
+		lastBan   *sbRecord
 		skipCount int
 	)
 
 	norm := newNormalizer()
+	meta := newSbPageMeta(doc)
 
 	doc.Find("div").Each(func(_ int, selection *goquery.Selection) {
 		idAttr, ok := selection.Attr("id")
@@ -905,7 +1550,10 @@ func parseStar(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeFunc
 					log.Error("failed to set invoke time", slog.String("input", value), ErrAttr(errInvoke))
 				}
 			case keyBanLength:
-				curBan.setBanLength(value)
+				if errLength := curBan.setBanLength(norm, value); errLength != nil {
+					// Falls back to the expiry date
+					log.Debug("failed to parse ban length", slog.String("input", value), ErrAttr(errLength))
+				}
 			case keyExpiredOn:
 				if errExpiration := curBan.setExpiredOn(parseTime, value); errExpiration != nil {
 					log.Error("failed to set expiration time", slog.String("input", value), ErrAttr(errExpiration))
@@ -915,10 +1563,25 @@ func parseStar(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeFunc
 				curBan.setReason(value)
 				if curBan.SteamID.Valid() && curBan.Name != "" {
 					bans = append(bans, curBan)
+					// The remaining details are rendered after the reason
+					lastBan = &bans[len(bans)-1]
 				} else {
 					skipCount++
+					lastBan = nil
 				}
 				curBan = sbRecord{} //nolint:exhaustruct
+			case keyAdmin:
+				if lastBan != nil {
+					lastBan.setAdmin(value)
+				}
+			case keyServer:
+				if lastBan != nil {
+					lastBan.setServer(meta, second, value)
+				}
+			case keyTotalBans:
+				if lastBan != nil {
+					lastBan.setTotalBans(value)
+				}
 			}
 		})
 	})
@@ -931,10 +1594,12 @@ func parseFluent(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeFu
 	var (
 		bans      []sbRecord
 		curBan    sbRecord
+		lastBan   *sbRecord
 		skipCount int
 	)
 
 	norm := newNormalizer()
+	meta := newSbPageMeta(doc)
 
 	doc.Find("ul.ban_list_detal li").Each(func(_ int, selection *goquery.Selection) {
 		child := selection.Children()
@@ -957,7 +1622,10 @@ func parseFluent(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeFu
 				log.Error("failed to set invoke time", slog.String("input", value), ErrAttr(errInvoke))
 			}
 		case keyBanLength:
-			curBan.setBanLength(value)
+			if errLength := curBan.setBanLength(norm, value); errLength != nil {
+				// Falls back to the expiry date
+				log.Debug("failed to parse ban length", slog.String("input", value), ErrAttr(errLength))
+			}
 		case keyExpiredOn:
 			if errExpiration := curBan.setExpiredOn(parseTime, value); errExpiration != nil {
 				log.Error("failed to set expiration time", slog.String("input", value), ErrAttr(errExpiration))
@@ -966,10 +1634,25 @@ func parseFluent(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeFu
 			curBan.setReason(value)
 			if curBan.SteamID.Valid() && curBan.Name != "" {
 				bans = append(bans, curBan)
+				// The remaining details are rendered after the reason
+				lastBan = &bans[len(bans)-1]
 			} else {
 				skipCount++
+				lastBan = nil
 			}
 			curBan = sbRecord{} //nolint:exhaustruct
+		case keyAdmin:
+			if lastBan != nil {
+				lastBan.setAdmin(value)
+			}
+		case keyServer:
+			if lastBan != nil {
+				lastBan.setServer(meta, child.Last(), value)
+			}
+		case keyTotalBans:
+			if lastBan != nil {
+				lastBan.setTotalBans(value)
+			}
 		}
 	})
 
@@ -980,12 +1663,14 @@ func parseDefault(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeF
 	var (
 		bans     []sbRecord
 		curBan   sbRecord
+		lastBan  *sbRecord
 		curState mappedKey
 		isValue  bool
 		skipped  int
 	)
 
 	norm := newNormalizer()
+	meta := newSbPageMeta(doc)
 
 	doc.Find("#banlist .listtable table tr td").Each(func(_ int, selection *goquery.Selection) {
 		value := strings.TrimSpace(selection.Text())
@@ -1018,6 +1703,15 @@ func parseDefault(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeF
 			case keyReason:
 				curState = keyReason
 				isValue = true
+			case keyAdmin:
+				curState = keyAdmin
+				isValue = true
+			case keyServer:
+				curState = keyServer
+				isValue = true
+			case keyTotalBans:
+				curState = keyTotalBans
+				isValue = true
 			}
 
 			return
@@ -1043,7 +1737,10 @@ func parseDefault(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeF
 				log.Error("failed to set invoke time", slog.String("input", value), ErrAttr(errInvoke))
 			}
 		case keyBanLength:
-			curBan.setBanLength(value)
+			if errLength := curBan.setBanLength(norm, value); errLength != nil {
+				// Falls back to the expiry date
+				log.Debug("failed to parse ban length", slog.String("input", value), ErrAttr(errLength))
+			}
 		case keyExpiredOn:
 			if errExpiration := curBan.setExpiredOn(parseTime, value); errExpiration != nil {
 				log.Error("failed to set expiration time", slog.String("input", value), ErrAttr(errExpiration))
@@ -1052,10 +1749,25 @@ func parseDefault(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeF
 			curBan.setReason(value)
 			if curBan.SteamID.Valid() && curBan.Name != "" {
 				bans = append(bans, curBan)
+				// The remaining details are rendered after the reason
+				lastBan = &bans[len(bans)-1]
 			} else {
 				skipped++
+				lastBan = nil
 			}
 			curBan = sbRecord{} //nolint:exhaustruct
+		case keyAdmin:
+			if lastBan != nil {
+				lastBan.setAdmin(value)
+			}
+		case keyServer:
+			if lastBan != nil {
+				lastBan.setServer(meta, selection, value)
+			}
+		case keyTotalBans:
+			if lastBan != nil {
+				lastBan.setTotalBans(value)
+			}
 		}
 	})
 
@@ -1114,102 +1826,3 @@ func parseDefault(doc *goquery.Selection, log *slog.Logger, parseTime parseTimeF
 //	}
 //	return nil, nil
 //}
-
-type cfResult struct {
-	page string
-	body string
-}
-
-type cfTransport struct {
-	launchURL      string
-	browser        *rod.Browser
-	page           *rod.Page
-	waitStableTime time.Duration
-	similarity     float32
-}
-
-func newCFTransport() *cfTransport {
-	const (
-		stableWaitTimeout = time.Second * 5
-		pageSimilarity    = 0.5
-	)
-
-	return &cfTransport{waitStableTime: stableWaitTimeout, similarity: pageSimilarity} //nolint:exhaustruct
-}
-
-func (t *cfTransport) Open(ctx context.Context) error {
-	const slowTimeout = time.Second * 5
-
-	launchURL, errLauncher := launcher.
-		NewUserMode().
-		Leakless(true).
-		Headless(false).
-		UserDataDir("cache/t"). // *must* be this?
-		Set("disable-default-apps").
-		Set("no-first-run").
-		Launch()
-	if errLauncher != nil {
-		return errors.Join(errLauncher, errScrapeLauncherInit)
-	}
-
-	t.launchURL = launchURL
-	t.browser = rod.
-		New().
-		SlowMotion(slowTimeout).
-		Context(ctx).
-		ControlURL(t.launchURL).
-		MustConnect().
-		NoDefaultDevice()
-
-	return nil
-}
-
-// NopCloser exists to satisfy io.ReadCloser interface for our fake http.Response.
-type NopCloser struct {
-	*bytes.Reader
-}
-
-func (b NopCloser) Close() error {
-	return nil
-}
-
-// RoundTrip implements the minimal http.Transport interface so that the underlying browser used for
-// scraping cloudflare protected sites can be integrated into the colly.Collector pipeline.
-func (t *cfTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	reqURL := req.URL.String()
-
-	res, errReq := t.fetch(reqURL)
-	if errReq != nil {
-		return nil, errReq
-	}
-
-	body := NopCloser{Reader: bytes.NewReader([]byte(res.body))}
-	resp := &http.Response{ //nolint:exhaustruct
-		Request:    req,
-		Body:       body,
-		StatusCode: http.StatusOK,
-		Status:     http.StatusText(http.StatusOK),
-		Proto:      "HTTP/1.0",
-	}
-
-	return resp, nil
-}
-
-func (t *cfTransport) fetch(url string) (*cfResult, error) {
-	if t.page == nil {
-		t.page = t.browser.MustPage(url)
-	} else {
-		t.page = t.page.MustNavigate(url)
-	}
-
-	if errWait := t.page.WaitStable(t.waitStableTime); errWait != nil {
-		return nil, errors.Join(errWait, errScrapeWait)
-	}
-
-	body, errBody := t.page.HTML()
-	if errBody != nil {
-		return nil, errors.Join(errBody, errResponseRead)
-	}
-
-	return &cfResult{page: url, body: body}, nil
-}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/leighmacdonald/bd-api/domain"
+)
+
+const (
+	defaultDelay       = time.Second
+	defaultRandomDelay = 5 * time.Second
+	backoffRetries     = 5
+	backoffBaseDelay   = 30 * time.Second
+	backoffMaxDelay    = 10 * time.Minute
+)
+
+// sbPolicy controls how politely a site is crawled.
+type sbPolicy struct {
+	// delay is the minimum time between requests
+	delay time.Duration
+	// randomDelay is the max random jitter added on top of delay
+	randomDelay time.Duration
+	// maxPages limits how many pages are visited per run, 0 for no limit
+	maxPages int
+	// startHour and endHour define the hours, in the sites timezone, in which the site may be crawled. The
+	// window can wrap around midnight. When equal, the site can be crawled at any time.
+	startHour int
+	endHour   int
+	// userAgent overrides the randomly chosen user agent
+	userAgent string
+	// robotsTxt enables honoring the sites robots.txt rules
+	robotsTxt bool
+}
+
+func defaultPolicy() sbPolicy {
+	return sbPolicy{ //nolint:exhaustruct
+		delay:       defaultDelay,
+		randomDelay: defaultRandomDelay,
+	}
+}
+
+// allowedAt checks if the site may be crawled at the given time.
+func (p sbPolicy) allowedAt(now time.Time, location *time.Location) bool {
+	if p.startHour == p.endHour {
+		return true
+	}
+
+	hour := now.In(location).Hour()
+
+	if p.startHour < p.endHour {
+		return hour >= p.startHour && hour < p.endHour
+	}
+
+	return hour >= p.startHour || hour < p.endHour
+}
+
+// sitePolicy returns the crawl policy for a site, falling back to the default policy.
+func sitePolicy(name domain.Site) sbPolicy {
+	policy, found := sitePolicies()[name]
+	if !found {
+		return defaultPolicy()
+	}
+
+	return policy
+}
+
+// backoffTransport retries requests that were rate limited (429) or hit an overloaded server (503). The
+// Retry-After header is used when provided, otherwise the delay doubles on each attempt.
+type backoffTransport struct {
+	ctx        context.Context //nolint:containedctx
+	next       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+func newBackoffTransport(next http.RoundTripper) *backoffTransport {
+	return &backoffTransport{
+		ctx:        context.Background(),
+		next:       next,
+		maxRetries: backoffRetries,
+		baseDelay:  backoffBaseDelay,
+		maxDelay:   backoffMaxDelay,
+	}
+}
+
+func (t *backoffTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, errResp := t.next.RoundTrip(req)
+		if errResp != nil {
+			return nil, errResp
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+			return resp, nil
+		}
+
+		// Requests with a body cannot safely be replayed.
+		if attempt >= t.maxRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+
+		delay := t.delay(attempt, resp.Header.Get("Retry-After"))
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		slog.Warn("Backing off from rate limited site", slog.String("url", req.URL.String()),
+			slog.Int("status", resp.StatusCode), slog.Int("attempt", attempt+1), slog.Duration("delay", delay))
+
+		select {
+		case <-t.ctx.Done():
+			return nil, t.ctx.Err()
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// delay calculates how long to wait before the next attempt.
+func (t *backoffTransport) delay(attempt int, retryAfter string) time.Duration {
+	delay := t.baseDelay << attempt
+
+	if seconds, errSeconds := strconv.Atoi(retryAfter); errSeconds == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if retryTime, errTime := http.ParseTime(retryAfter); errTime == nil {
+		delay = time.Until(retryTime)
+	}
+
+	return max(min(delay, t.maxDelay), 0)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyAllowedAt(t *testing.T) {
+	t.Parallel()
+
+	location, errLocation := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, errLocation)
+
+	at := func(hour int) time.Time {
+		return time.Date(2024, 1, 10, hour, 30, 0, 0, location)
+	}
+
+	anyTime := defaultPolicy()
+	require.True(t, anyTime.allowedAt(at(3), location))
+
+	night := sbPolicy{startHour: 1, endHour: 6} //nolint:exhaustruct
+	require.True(t, night.allowedAt(at(1), location))
+	require.True(t, night.allowedAt(at(5), location))
+	require.False(t, night.allowedAt(at(6), location))
+	require.False(t, night.allowedAt(at(0), location))
+	// 02:30 UTC is 03:30 in Berlin during winter
+	require.True(t, night.allowedAt(time.Date(2024, 1, 10, 2, 30, 0, 0, time.UTC), location))
+
+	wrapped := sbPolicy{startHour: 22, endHour: 4} //nolint:exhaustruct
+	require.True(t, wrapped.allowedAt(at(23), location))
+	require.True(t, wrapped.allowedAt(at(2), location))
+	require.False(t, wrapped.allowedAt(at(12), location))
+}
+
+func TestBackoffTransport(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		switch requests.Add(1) {
+		case 1:
+			writer.Header().Set("Retry-After", "0")
+			writer.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			writer.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	transport := newBackoffTransport(http.DefaultTransport)
+	transport.baseDelay = time.Millisecond
+
+	req, errReq := http.NewRequest(http.MethodGet, server.URL, nil) //nolint:noctx
+	require.NoError(t, errReq)
+
+	resp, errResp := transport.RoundTrip(req)
+	require.NoError(t, errResp)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(3), requests.Load())
+
+	transport.maxRetries = 0
+	requests.Store(0)
+
+	resp, errResp = transport.RoundTrip(req)
+	require.NoError(t, errResp)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func TestBackoffDelay(t *testing.T) {
+	t.Parallel()
+
+	transport := newBackoffTransport(http.DefaultTransport)
+
+	require.Equal(t, backoffBaseDelay, transport.delay(0, ""))
+	require.Equal(t, backoffBaseDelay*4, transport.delay(2, ""))
+	require.Equal(t, backoffMaxDelay, transport.delay(10, ""))
+	require.Equal(t, 120*time.Second, transport.delay(0, "120"))
+	require.Equal(t, backoffMaxDelay, transport.delay(0, "86400"))
+	require.Equal(t, time.Duration(0), transport.delay(0, time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)))
+}
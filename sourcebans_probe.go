@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	errProbeTheme    = errors.New("could not detect sourcebans theme")
+	errProbeTime     = errors.New("no known time layout matched")
+	errProbeDocument = errors.New("failed to parse probe document")
+	errProbeStatus   = errors.New("unexpected probe response status")
+)
+
+// sbTheme describes one of the supported sourcebans themes along with a DOM selector that
+// is unique to it.
+type sbTheme struct {
+	name      string
+	funcName  string
+	signature string
+	parser    parserFunc
+}
+
+// sbThemes returns the known themes, ordered from most to least specific signature.
+func sbThemes() []sbTheme {
+	return []sbTheme{
+		{name: "material", funcName: "parseMaterial", signature: "div.opener .card-body", parser: parseMaterial},
+		{name: "fluent", funcName: "parseFluent", signature: "ul.ban_list_detal li", parser: parseFluent},
+		{name: "star", funcName: "parseStar", signature: "div[id^=expand_] tbody tr", parser: parseStar},
+		{name: "default", funcName: "parseDefault", signature: "#banlist .listtable table tr td", parser: parseDefault},
+	}
+}
+
+type namedTimeParser struct {
+	funcName string
+	parse    parseTimeFunc
+}
+
+// knownTimeParsers returns all the time parsers used by the configured sites. When multiple layouts
+// match equally well, the earlier entry wins.
+func knownTimeParsers() []namedTimeParser {
+	return []namedTimeParser{
+		{"parseDefaultTime", parseDefaultTime},
+		{"parseDefaultTimeMonthFirst", parseDefaultTimeMonthFirst},
+		{"parseSkialTime", parseSkialTime},
+		{"parseSkialAltTime", parseSkialAltTime},
+		{"parseTrailYear", parseTrailYear},
+		{"parseRushyTime", parseRushyTime},
+		{"parseBachuruServasTime", parseBachuruServasTime},
+		{"parseBaitedTime", parseBaitedTime},
+		{"parseGunServer", parseGunServer},
+		{"parseProGamesZetTime", parseProGamesZetTime},
+		{"parsePRWHTime", parsePRWHTime},
+		{"parseSVDos", parseSVDos},
+		{"parseTriggerHappyTime", parseTriggerHappyTime},
+		{"parseDarkPyroTime", parseDarkPyroTime},
+		{"parseHellClanTime", parseHellClanTime},
+		{"parseSneakTime", parseSneakTime},
+		{"parseAMSGamingTime", parseAMSGamingTime},
+		{"parsePancakesTime", parsePancakesTime},
+		{"parseTitanTime", parseTitanTime},
+		{"parseSGGamingTime", parseSGGamingTime},
+		{"parseFurryPoundTime", parseFurryPoundTime},
+		{"parseFluxTime", parseFluxTime},
+		{"parseWonderlandTime", parseWonderlandTime},
+	}
+}
+
+type namedNextURL struct {
+	funcName string
+	next     nextURLFunc
+}
+
+func knownNextURLs() []namedNextURL {
+	return []namedNextURL{
+		{"nextURLFluent", nextURLFluent},
+		{"nextURLLast", nextURLLast},
+		{"nextURLFirst", nextURLFirst},
+	}
+}
+
+// sbProbeResult is the best guess at how a sourcebans site should be scraped.
+type sbProbeResult struct {
+	Name       string
+	BaseURL    string
+	StartPath  string
+	Theme      string
+	Parser     string
+	TimeParser string
+	NextURL    string
+	NextPage   string
+	Records    []sbRecord
+	Skipped    int
+	TimeErrors int
+}
+
+// probeSourcebans works out the theme, time layout and pagination strategy of a sourcebans ban list page.
+// pageURL is the url the document was loaded from and is used to derive the base url.
+func probeSourcebans(doc *goquery.Selection, pageURL string) (sbProbeResult, error) {
+	var result sbProbeResult
+
+	baseURL, startPath, name, errURL := probeURLParts(pageURL)
+	if errURL != nil {
+		return result, errURL
+	}
+
+	result.Name = name
+	result.BaseURL = baseURL
+	result.StartPath = startPath
+
+	theme, found := detectTheme(doc)
+	if !found {
+		return result, errProbeTheme
+	}
+
+	result.Theme = theme.name
+	result.Parser = theme.funcName
+
+	// Parser errors are expected while trying out the wrong layouts.
+	quietLog := slog.New(slog.NewTextHandler(io.Discard, nil))
+	bestValid := -1
+
+	for _, candidate := range knownTimeParsers() {
+		timeErrors := 0
+		countingParser := func(value string) (time.Time, error) {
+			parsed, errParse := candidate.parse(value)
+			if errParse != nil {
+				timeErrors++
+			}
+
+			return parsed, errParse
+		}
+
+		records, skipped, errParse := theme.parser(doc, quietLog, countingParser)
+		if errParse != nil {
+			continue
+		}
+
+		valid := 0
+
+		for _, record := range records {
+			if record.CreatedOn.Unix() > minValidBanTime {
+				valid++
+			}
+		}
+
+		if valid > bestValid || (valid == bestValid && timeErrors < result.TimeErrors) {
+			bestValid = valid
+			result.TimeParser = candidate.funcName
+			result.TimeErrors = timeErrors
+			result.Records = records
+			result.Skipped = skipped
+		}
+	}
+
+	if bestValid <= 0 {
+		return result, errProbeTime
+	}
+
+	result.NextURL, result.NextPage = detectNextURL(doc, baseURL)
+
+	return result, nil
+}
+
+// Anything before this is considered an invalid creation date, 1995-01-01.
+const minValidBanTime = 788943600
+
+func detectTheme(doc *goquery.Selection) (sbTheme, bool) {
+	for _, theme := range sbThemes() {
+		if doc.Find(theme.signature).Length() > 0 {
+			return theme, true
+		}
+	}
+
+	return sbTheme{}, false
+}
+
+// detectNextURL tries each pagination strategy, preferring one that leads to the 2nd page. Falls back
+// to nextURLFirst when no pagination could be found at all.
+func detectNextURL(doc *goquery.Selection, baseURL string) (string, string) {
+	scraper := &sbScraper{baseURL: baseURL} //nolint:exhaustruct
+
+	var (
+		fallbackName string
+		fallbackURL  string
+	)
+
+	for _, candidate := range knownNextURLs() {
+		next := candidate.next(scraper, doc)
+		if next == "" || next == baseURL || !strings.Contains(next, "page=") {
+			continue
+		}
+
+		if strings.Contains(next, "page=2") {
+			return candidate.funcName, next
+		}
+
+		if fallbackName == "" {
+			fallbackName, fallbackURL = candidate.funcName, next
+		}
+	}
+
+	if fallbackName == "" {
+		return "nextURLFirst", ""
+	}
+
+	return fallbackName, fallbackURL
+}
+
+// probeURLParts splits a ban list url into the base url and start path used by newScraper, along with
+// a suggested site name derived from the hostname.
+func probeURLParts(pageURL string) (string, string, string, error) {
+	parsed, errParse := url.Parse(pageURL)
+	if errParse != nil {
+		return "", "", "", errors.Join(errParse, errScrapeURL)
+	}
+
+	dir, file := path.Split(parsed.Path)
+	if dir == "" {
+		dir = "/"
+	}
+
+	startPath := file
+	if parsed.RawQuery != "" {
+		startPath += "?" + parsed.RawQuery
+	}
+
+	if startPath == defaultStartPath {
+		startPath = ""
+	}
+
+	name := parsed.Hostname()
+	for _, prefix := range []string{"www.", "bans.", "sb.", "sourcebans.", "banlist."} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+
+	if idx := strings.Index(name, "."); idx > 0 {
+		name = name[:idx]
+	}
+
+	return fmt.Sprintf("%s://%s%s", parsed.Scheme, parsed.Host, dir), startPath, name, nil
+}
+
+// goIdent converts a site name like "ugc-gaming" into "UgcGaming".
+func goIdent(name string) string {
+	var builder strings.Builder
+
+	upper := true
+
+	for _, char := range name {
+		if !unicode.IsLetter(char) && !unicode.IsDigit(char) {
+			upper = true
+
+			continue
+		}
+
+		if upper {
+			char = unicode.ToUpper(char)
+			upper = false
+		}
+
+		builder.WriteRune(char)
+	}
+
+	return builder.String()
+}
+
+// siteDefinition renders the go code required to add the site to domain/domain.go and sourcebans_sites.go.
+func (r sbProbeResult) siteDefinition() string {
+	ident := goIdent(r.Name)
+
+	return fmt.Sprintf(`// domain/domain.go
+	%s Site = "%s"
+
+// sourcebans_sites.go
+func new%sScraper(cacheDir string) (*sbScraper, error) {
+	return newScraper(cacheDir, domain.%s, "%s", "%s",
+		%s, %s, %s)
+}
+
+// If the site does not display its times in UTC, also set its IANA timezone in sb_site.timezone.
+`, ident, strings.ToLower(ident), ident, ident, r.BaseURL, r.StartPath, r.Parser, r.NextURL, r.TimeParser)
+}
+
+func fetchProbeDocument(ctx context.Context, pageURL string) (*goquery.Document, error) {
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if errReq != nil {
+		return nil, errors.Join(errReq, errRequestCreate)
+	}
+
+	resp, errResp := NewHTTPClient().Do(req)
+	if errResp != nil {
+		return nil, errors.Join(errResp, errRequestPerform)
+	}
+
+	defer logCloser(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", errProbeStatus, resp.StatusCode)
+	}
+
+	doc, errDoc := goquery.NewDocumentFromReader(resp.Body)
+	if errDoc != nil {
+		return nil, errors.Join(errDoc, errProbeDocument)
+	}
+
+	return doc, nil
+}
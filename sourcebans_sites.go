@@ -1,7 +1,6 @@
 package main
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -10,6 +9,29 @@
 	"github.com/leighmacdonald/bd-api/domain"
 )
 
+// sitePolicies holds the crawl policies of sites that need to be treated differently from the default. Sites
+// that are not listed use defaultPolicy. This is where to slow down a site when its admins ask us to.
+func sitePolicies() map[domain.Site]sbPolicy {
+	return map[domain.Site]sbPolicy{
+		domain.CuteProject: {delay: 5 * time.Second, randomDelay: defaultRandomDelay},  //nolint:exhaustruct
+		domain.Wonderland:  {delay: 10 * time.Second, randomDelay: defaultRandomDelay}, //nolint:exhaustruct
+	}
+}
+
+// siteLocation loads the timezone stored for a site. Sites without one are assumed to use UTC.
+func siteLocation(zone string) (*time.Location, error) {
+	if zone == "" {
+		return time.UTC, nil
+	}
+
+	location, errLocation := time.LoadLocation(zone)
+	if errLocation != nil {
+		return nil, errors.Join(errLocation, errScrapeTimezone)
+	}
+
+	return location, nil
+}
+
 func newSkialScraper(cacheDir string) (*sbScraper, error) {
 	return newScraper(cacheDir, domain.Skial, "https://www.skial.com/sourcebans/", "",
 		parseDefault, nextURLFirst, parseSkialTime,
@@ -62,28 +84,17 @@ func newScrapTFScraper(cacheDir string) (*sbScraper, error) {
 }
 
 func newWonderlandTFScraper(cacheDir string) (*sbScraper, error) {
-	const siteSleepTime = time.Second * 10
-
-	transport := newCFTransport()
-
-	if errOpen := transport.Open(context.Background()); errOpen != nil {
-		return nil, errors.Join(errOpen, errScrapeCFOpen)
-	}
-
-	scraper, errScraper := newScraperWithTransport(cacheDir,
+	scraper, errScraper := newBrowserScraper(cacheDir,
 		domain.Wonderland,
 		"https://bans.wonderland.tf/",
 		"",
 		parseDefault,
 		nextURLLast,
-		parseWonderlandTime,
-		transport)
+		parseWonderlandTime)
 	if errScraper != nil {
 		return nil, errScraper
 	}
 
-	scraper.sleepTime = siteSleepTime
-
 	// Cached versions do not have a proper next link, so we have to generate one.
 	scraper.nextURL = func(scraper *sbScraper, _ *goquery.Selection) string {
 		scraper.curPage++
@@ -423,18 +434,8 @@ func newG44Scraper(cacheDir string) (*sbScraper, error) {
 }
 
 func newCuteProjectScraper(cacheDir string) (*sbScraper, error) {
-	const siteSleepTime = time.Second * 4
-
-	scraper, errScraper := newScraper(cacheDir, domain.CuteProject, "https://bans.cute-project.net/", "",
+	return newScraper(cacheDir, domain.CuteProject, "https://bans.cute-project.net/", "",
 		parseMaterial, nextURLLast, parseProGamesZetTime)
-
-	if errScraper != nil {
-		return nil, errScraper
-	}
-
-	scraper.sleepTime = siteSleepTime
-
-	return scraper, nil
 }
 
 func newPhoenixSourceScraper(cacheDir string) (*sbScraper, error) {
@@ -2,660 +2,359 @@
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/leighmacdonald/bd-api/domain"
 	"github.com/stretchr/testify/require"
 )
 
 type scraperFn func(cacheDir string) (*sbScraper, error)
 
-func testParser(t *testing.T, scraperFn scraperFn, count int, nextPage string) {
-	t.Helper()
-
-	scraper, scraperErr := scraperFn("./cache/")
-	require.NoError(t, scraperErr, "Failed to create scraper")
-
-	testBody, errOpen := os.Open(fmt.Sprintf("testdata/%s.html", scraper.name))
-	require.NoError(t, errOpen)
-
-	defer logCloser(testBody)
-
-	doc, errDoc := goquery.NewDocumentFromReader(testBody)
-	require.NoError(t, errDoc)
-
-	results, _, errParse := scraper.parser(doc.Selection, slog.Default(), scraper.parseTIme)
-	require.NoError(t, errParse)
-	require.Equal(t, count, len(results))
-
-	if nextPage != "" {
-		next := scraper.nextURL(scraper, doc.Selection)
-		require.Equal(t, scraper.url(nextPage), next)
-	}
-
-	for _, result := range results {
-		require.NotEqual(t, "", result.Name)
-		require.Truef(t, result.SteamID.Valid(), "Invalid steamid: %s", result.SteamID.String())
-		require.True(t, result.Length >= 0, "negative duration")
-
-		if result.Length == 0 {
-			require.True(t, result.Permanent)
-		} else {
-			require.False(t, result.Permanent)
-		}
-
-		const ninetyFive = 788943600
-		require.Truef(t, result.CreatedOn.Unix() > ninetyFive, "Date: %s", result.CreatedOn)
-	}
-}
-
-func TestParseSkial(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSkialScraper, 48, "index.php?p=banlist&page=2")
-}
-
-func TestParseUGC(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newUGCScraper, 49, "index.php?p=banlist&page=2")
-}
-
-// func TestParseWonderland(t *testing.T) {
-
-//	t.Parallel()
-
-//	testParser(t, newWonderlandTFScraper, 30, "index.php?p=banlist&page=2")
-
-// }
-
-func TestParseGFL(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newGFLScraper, 28, "index.php?p=banlist&page=2")
-}
-
-func TestParsePancakes(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newPancakesScraper, 10, "index.php?p=banlist&page=2")
-}
-
-func TestParseOWL(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newOwlTFScraper, 22, "index.php?p=banlist&page=2")
-}
-
-func TestParseSpaceShip(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSpaceShipScraper, 69, "index.php?p=banlist&page=2")
-}
-
-func TestParseLazyPurple(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newLazyPurpleScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestParseFirePowered(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newFirePoweredScraper, 26, "index.php?p=banlist&page=2")
-}
-
-func TestDixiGame(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newDixiGameScraper, 23, "index.php?p=banlist&page=2")
-}
-
-func TestParseHarpoon(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newHarpoonScraper, 38, "index.php?p=banlist&page=2")
-}
-
-func TestParsePanda(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newPandaScraper, 40, "index.php?p=banlist&page=2")
-}
-
-func TestParseNeonHeights(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newNeonHeightsScraper, 28, "index.php?p=banlist&page=2")
-}
-
-func TestParseLOOS(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newLOOSScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestParsePubsTF(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newPubsTFScraper, 26, "index.php?p=banlist&page=2")
-}
-
-func TestParseScrapTF(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newScrapTFScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestParseServiliveCl(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newServiliveClScraper, 27, "index.php?p=banlist&page=2")
-}
-
-func TestParseZMBrasil(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newZMBrasilScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestParseSirPlease(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSirPleaseScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestVidyaGaems(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newVidyaGaemsScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestSGGaming(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSGGamingScraper, 50, "index.php?p=banlist&page=2")
-}
-
-func TestApeMode(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newApeModeScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestMaxDB(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newMaxDBScraper, 26, "index.php?p=banlist&page=2")
-}
-
-func TestSvdosBrothers(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSvdosBrothersScraper, 27, "index.php?p=banlist&page=2")
-}
-
-func TestElectric(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newElectricScraper, 24, "index.php?p=banlist&page=2")
-}
-
-func TestGlobalParadise(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newGlobalParadiseScraper, 23, "index.php?p=banlist&page=2")
-}
-
-func TestSavageServidores(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSavageServidoresScraper, 29, "index.php?p=banlist&page=2")
-}
-
-func TestCSIServers(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newCSIServersScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestLBGaming(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newLBGamingScraper, 29, "index.php?p=banlist&page=2")
-}
-
-func TestFluxTF(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newFluxTFScraper, 29, "index.php?p=banlist&page=2")
-}
-
-func TestCutiePie(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newCutiePieScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestDarkPyro(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newDarkPyroScraper, 16, "index.php?p=banlist&page=2")
-}
-
-func TestOpstOnline(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newOpstOnlineScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestBouncyBall(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newBouncyBallScraper, 49, "index.php?p=banlist&page=2")
-}
-
-func TestFurryPound(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newFurryPoundScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestRetroServers(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newRetroServersScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestSwapShop(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSwapShopScraper, 76, "index.php?p=banlist&page=2")
-}
-
-func TestECJ(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newECJScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestJumpAcademy(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newJumpAcademyScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestTF2RO(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newTF2ROScraper, 21, "")
-}
-
-func TestSameTeem(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSameTeemScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestPowerFPS(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newPowerFPSScraper, 28, "index.php?p=banlist&page=2")
-}
-
-func Test7Mau(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, new7MauScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestGhostCap(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newGhostCapScraper, 28, "index.php?p=banlist&page=2")
-}
-
-func TestSpectre(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSpectreScraper, 29, "index.php?p=banlist&page=2")
-}
-
-func TestDreamFire(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newDreamFireScraper, 29, "index.php?p=banlist&page=2")
-}
-
-func TestSetti(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSettiScraper, 25, "index.php?p=banlist&page=2")
-}
-
-func TestGunServer(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newGunServerScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestHellClan(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newHellClanScraper, 59, "index.php?p=banlist&page=2")
-}
-
-func TestSneaks(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newSneaksScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestNide(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newNideScraper, 20, "index.php?p=banlist&page=2")
-}
-
-func TestAstraMania(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newAstraManiaScraper, 38, "index.php?p=banlist&page=2")
-}
-
-func TestTF2Maps(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newTF2MapsScraper, 56, "index.php?p=banlist&page=2")
-}
-
-func TestPetrolTF(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newPetrolTFScraper, 98, "index.php?p=banlist&page=2")
-}
-
-func TestVaticanCity(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newVaticanCityScraper, 50, "index.php?p=banlist&page=2")
-}
-
-func TestLazyNeer(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newLazyNeerScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestTheVille(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newTheVilleScraper, 48, "index.php?p=banlist&page=2")
-}
-
-func TestOreon(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newOreonScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestTriggerHappy(t *testing.T) {
+func TestParseGFLTime(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newTriggerHappyScraper, 27, "index.php?p=banlist&page=2")
+	parsed, e := parseDefaultTime("2023-05-17 03:07:05")
+	require.NoError(t, e)
+	require.Equal(t, time.Date(2023, time.May, 17, 3, 7, 5, 0, time.UTC), parsed)
 }
 
-func TestDefuseRo(t *testing.T) {
+func TestParseWonderlandTime(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newDefuseRoScraper, 25, "index.php?p=banlist&page=2")
+	parsed, e := parseWonderlandTime("May 17th, 2023 (3:07)")
+	require.NoError(t, e)
+	require.Equal(t, time.Date(2023, time.May, 17, 3, 7, 0, 0, time.UTC), parsed)
 }
 
-// func TestTawerna(t *testing.T) {
-
-//	t.Parallel()
-
-//	testParser(t, newTawernaScraper, 30, "index.php?p=banlist&page=2")
-
-// }
-
-func TestTitan(t *testing.T) {
+func TestParseSkialTime(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newTitanScraper, 30, "index.php?p=banlist&page=2")
-}
-
-func TestDiscFF(t *testing.T) {
-	t.Parallel()
+	parsed, e := parseSkialTime("05-17-23 03:07")
+	require.NoError(t, e)
+	require.Equal(t, time.Date(2023, time.May, 17, 3, 7, 0, 0, time.UTC), parsed)
 
-	testParser(t, newDiscFFScraper, 29, "index.php?p=banlist&page=2")
+	perm, ePerm := parseSkialTime("Permanent")
+	require.NoError(t, ePerm)
+	require.Equal(t, time.Time{}, perm)
 }
 
-// func TestOtaku(t *testing.T) {
-
-//	testParser(t, NewOtakuScraper(), 30, "index.php?p=banlist&page=2")
-
-// }
-
-func TestAMSGaming(t *testing.T) {
+func TestParsePancakesTime(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newAMSGamingScraper, 29, "index.php?p=banlist&page=2")
-}
-
-func TestBaitedCommunity(t *testing.T) {
-	t.Parallel()
+	parsed, e := parsePancakesTime("Thu, May 17, 2023 3:07 AM")
+	require.NoError(t, e)
+	require.Equal(t, time.Date(2023, time.May, 17, 3, 7, 0, 0, time.UTC), parsed)
 
-	testParser(t, newBaitedCommunityScraper, 28, "index.php?p=banlist&page=2")
+	perm, ePerm := parsePancakesTime("never, this is permanent")
+	require.NoError(t, ePerm)
+	require.Equal(t, time.Time{}, perm)
 }
 
-func TestCedaPugCommunity(t *testing.T) {
+func TestParseFluxTFTime(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newCedaPugScraper, 30, "index.php?p=banlist&page=2")
+	parsed, e := parseFluxTime("Tuesday 30th of August 2022 08:30:45 PM")
+	require.NoError(t, e)
+	require.Equal(t, time.Date(2022, time.August, 30, 20, 30, 45, 0, time.UTC), parsed)
 }
 
-func TestGameSitesCommunity(t *testing.T) {
+func TestParseTimeInLocation(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newGameSitesScraper, 30, "index.php?p=banlist&page=2")
-}
+	moscow, errMoscow := siteLocation("Europe/Moscow")
+	require.NoError(t, errMoscow)
 
-func TestBachuruServasCommunity(t *testing.T) {
-	t.Parallel()
+	parsed, errParse := inLocation(parseDefaultTime, moscow)("2023-05-17 03:07:05")
+	require.NoError(t, errParse)
+	require.Equal(t, "2023-05-17 00:07:05 +0000 UTC", parsed.String())
+
+	utc, errUTC := siteLocation("")
+	require.NoError(t, errUTC)
+	require.Equal(t, time.UTC, utc)
+
+	_, errUnknown := siteLocation("Europe/Nowhere")
+	require.ErrorIs(t, errUnknown, errScrapeTimezone)
+
+	permanent, errPerm := inLocation(parseSkialTime, moscow)("Permanent")
+	require.NoError(t, errPerm)
+	require.True(t, permanent.IsZero())
+
+	// Times with a real offset are left alone
+	offset, errOffset := inLocation(func(s string) (time.Time, error) {
+		return time.Parse(time.RFC3339, s)
+	}, moscow)("2023-05-17T03:07:05+02:00")
+	require.NoError(t, errOffset)
+	require.Equal(t, "2023-05-17 01:07:05 +0000 UTC", offset.String())
+}
+
+func TestParseBanLength(t *testing.T) {
+	t.Parallel()
+
+	norm := newNormalizer()
+
+	for _, testCase := range []struct {
+		input    string
+		expected banLength
+	}{
+		{"2 wk, 3 d", banLength{duration: lengthWeek*2 + lengthDay*3}},
+		{"1 month", banLength{duration: lengthMonth}},
+		{"1 mo, 2 hr, 30 min (Expired)", banLength{duration: lengthMonth + time.Hour*2 + time.Minute*30}},
+		{"30 minut", banLength{duration: time.Minute * 30}},
+		{"5 мин. Истек", banLength{duration: time.Minute * 5}},
+		{"3 Нед Истек", banLength{duration: lengthWeek * 3}},
+		{"2 gün", banLength{duration: lengthDay * 2}},
+		{"Permanent", banLength{permanent: true}},
+		{"Навсегда", banLength{permanent: true}},
+		{"Kalıcı", banLength{permanent: true}},
+		{"Permanent (Unbanned)", banLength{permanent: true, unbanned: true}},
+		{"1 d (Unbanned)", banLength{duration: lengthDay, unbanned: true}},
+		{"Навсегда Разбанен", banLength{permanent: true, unbanned: true}},
+	} {
+		length, errLength := norm.banLength(testCase.input)
+		require.NoError(t, errLength, testCase.input)
+		require.Equal(t, testCase.expected, length, testCase.input)
+	}
 
-	testParser(t, newBachuruServasScraper, 26, "index.php?p=banlist&page=2")
+	_, errSession := norm.banLength("Session (Expired)")
+	require.ErrorIs(t, errSession, errScrapeParseLength)
 }
 
-func TestBierwieseCommunity(t *testing.T) {
+func TestBanLengthMismatch(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newBierwieseScraper, 30, "index.php?p=banlist&page=2")
-}
+	var (
+		norm    = newNormalizer()
+		created = time.Date(2023, time.May, 17, 3, 7, 0, 0, time.UTC)
+	)
 
-func TestAceKillCommunity(t *testing.T) {
-	t.Parallel()
+	matched := sbRecord{SteamID: testIDb4nny, CreatedOn: created} //nolint:exhaustruct
+	require.NoError(t, matched.setBanLength(norm, "2 wk"))
+	require.NoError(t, matched.setExpiredOn(parseDefaultTime, "2023-05-31 03:07:00"))
+	require.Equal(t, lengthWeek*2, matched.Length)
+	require.False(t, matched.LengthMismatch)
 
-	testParser(t, newAceKillScraper, 30, "index.php?p=banlist&page=2")
-}
+	mismatched := sbRecord{SteamID: testIDb4nny, CreatedOn: created} //nolint:exhaustruct
+	require.NoError(t, mismatched.setBanLength(norm, "1 d"))
+	require.NoError(t, mismatched.setExpiredOn(parseDefaultTime, "2023-05-31 03:07:00"))
+	require.Equal(t, lengthDay, mismatched.Length)
+	require.True(t, mismatched.LengthMismatch)
 
-func TestMagyarhns(t *testing.T) {
-	t.Parallel()
+	hidden := sbRecord{SteamID: testIDb4nny, CreatedOn: created} //nolint:exhaustruct
+	require.NoError(t, hidden.setExpiredOn(parseDefaultTime, "2023-05-18 03:07:00"))
+	require.Equal(t, lengthDay, hidden.Length)
+	require.False(t, hidden.LengthMismatch)
 
-	testParser(t, newMagyarhnsScraper, 27, "index.php?p=banlist&page=2")
+	// Expiry column rendered before the created and length columns
+	reversed := sbRecord{SteamID: testIDb4nny} //nolint:exhaustruct
+	require.NoError(t, reversed.setExpiredOn(parseDefaultTime, "2023-05-31 03:07:00"))
+	require.NoError(t, reversed.setInvokedOn(parseDefaultTime, "2023-05-17 03:07:00"))
+	require.NoError(t, reversed.setBanLength(norm, "1 d"))
+	require.Equal(t, lengthDay, reversed.Length)
+	require.True(t, reversed.LengthMismatch)
 }
 
-func TestGamesTown(t *testing.T) {
+func TestParseBanDetails(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newGamesTownScraper, 29, "index.php?p=banlist&page=2")
-}
+	parse := func(scraperFn scraperFn) []sbRecord {
+		scraper, errScraper := scraperFn("./cache/")
+		require.NoError(t, errScraper)
 
-func TestProGamesZet(t *testing.T) {
-	t.Parallel()
+		testBody, errOpen := os.Open(fmt.Sprintf("testdata/%s.html", scraper.name))
+		require.NoError(t, errOpen)
 
-	testParser(t, newProGamesZetScraper, 16, "index.php?p=banlist&page=2")
-}
+		defer logCloser(testBody)
 
-func TestG44(t *testing.T) {
-	t.Parallel()
+		doc, errDoc := goquery.NewDocumentFromReader(testBody)
+		require.NoError(t, errDoc)
 
-	testParser(t, newG44Scraper, 52, "index.php?p=banlist&page=2")
-}
+		results, _, errParse := scraper.parser(doc.Selection, slog.Default(), scraper.parseTIme)
+		require.NoError(t, errParse)
 
-func TestCuteProject(t *testing.T) {
-	t.Parallel()
+		return results
+	}
 
-	testParser(t, newCuteProjectScraper, 12, "index.php?p=banlist&page=2")
-}
+	// Server resolved from the xajax host reference
+	ugc := parse(newUGCScraper)
+	require.Equal(t, "CONSOLE", ugc[0].Admin)
+	require.Equal(t, "193.221.192.13:27015", ugc[0].Server)
+	require.Equal(t, 0, ugc[0].TotalBans)
 
-func TestPhoenixSource(t *testing.T) {
-	t.Parallel()
+	gunServer := parse(newGunServerScraper)
+	require.Equal(t, "Natasha_Heide", gunServer[1].Admin)
+	require.Equal(t, "css.gunserver.ru:27016", gunServer[1].Server)
+	require.Equal(t, "getdemo.php?type=B&id=56799", gunServer[1].DemoURL)
 
-	testParser(t, newPhoenixSourceScraper, 20, "index.php?p=banlist&page=2")
+	amsGaming := parse(newAMSGamingScraper)
+	require.Equal(t, "Web Ban", amsGaming[0].Server)
+	require.Equal(t, 2, amsGaming[0].TotalBans)
 }
 
-func TestSlavonServer(t *testing.T) {
+func TestFindScraper(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newSlavonServerScraper, 26, "index.php?p=banlist&page=2")
-}
-
-func TestGetSome(t *testing.T) {
-	t.Parallel()
+	scraper, errScraper := findScraper("./cache/", "skial")
+	require.NoError(t, errScraper)
+	require.Equal(t, "skial", string(scraper.name))
 
-	testParser(t, newGetSomeScraper, 30, "index.php?p=banlist&page=2")
+	_, errUnknown := findScraper("./cache/", "not-a-site")
+	require.ErrorIs(t, errUnknown, errScrapeUnknownSite)
 }
 
-func TestRushy(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newRushyScraper, 20, "index.php?p=banlist&page=2")
-}
+// updateGolden regenerates the golden files in testdata/golden from the current parser output.
+var updateGolden = flag.Bool("update", false, "Regenerate the sourcebans golden files") //nolint:gochecknoglobals
 
-func TestMoevsMachine(t *testing.T) {
+// TestSourcebansGolden compares the full parser output of every site with a fixture against the
+// checked in golden json. Run with -update to regenerate them after an intentional change.
+func TestSourcebansGolden(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newMoevsMachineScraper, 27, "index.php?p=banlist&page=2")
-}
+	scrapers, errScrapers := createScrapers("./cache/")
+	require.NoError(t, errScrapers)
 
-func TestPRWH(t *testing.T) {
-	t.Parallel()
+	// Disabled sites which still have a working fixture.
+	for _, scraperFn := range []scraperFn{
+		new7MauScraper, newDixiGameScraper, newGhostCapScraper, newHellClanScraper, newServiliveClScraper,
+		newTF2ROScraper,
+	} {
+		scraper, errScraper := scraperFn("./cache/")
+		require.NoError(t, errScraper)
 
-	testParser(t, newPRWHScraper, 30, "index.php?p=banlist&page=2")
-}
+		scrapers = append(scrapers, scraper)
+	}
 
-func TestVortex(t *testing.T) {
-	t.Parallel()
+	for _, scraper := range scrapers {
+		t.Run(string(scraper.name), func(t *testing.T) {
+			t.Parallel()
 
-	testParser(t, newVortexScraper, 47, "index.php?p=banlist&page=2")
+			testGolden(t, scraper)
+		})
+	}
 }
 
-func TestCasualFun(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newCasualFunScraper, 30, "index.php?p=banlist&page=2")
+// sbGolden is the checked in parser output of a single fixture page.
+type sbGolden struct {
+	NextURL string     `json:"next_url"`
+	Records []sbRecord `json:"records"`
 }
 
-func TestRandomTF2(t *testing.T) {
-	t.Parallel()
-
-	testParser(t, newRandomTF2Scraper, 3, "")
-}
+func testGolden(t *testing.T, scraper *sbScraper) {
+	t.Helper()
 
-func TestPlayesRO(t *testing.T) {
-	t.Parallel()
+	testBody, errOpen := os.Open(fmt.Sprintf("testdata/%s.html", scraper.name))
+	if errOpen != nil {
+		t.Skipf("No fixture found: %v", errOpen)
+	}
 
-	testParser(t, newPlayesROScraper, 29, "index.php?p=banlist&page=2")
-}
+	defer logCloser(testBody)
 
-func TestEOTLGaming(t *testing.T) {
-	t.Parallel()
+	doc, errDoc := goquery.NewDocumentFromReader(testBody)
+	require.NoError(t, errDoc)
 
-	testParser(t, newEOTLGamingScraper, 30, "index.php?p=banlist&page=2")
-}
+	results, _, errParse := scraper.parser(doc.Selection, slog.Default(), scraper.parseTIme)
+	require.NoError(t, errParse)
 
-func TestBioCrafting(t *testing.T) {
-	t.Parallel()
+	for _, result := range results {
+		require.NotEqual(t, "", result.Name)
+		require.Truef(t, result.SteamID.Valid(), "Invalid steamid: %s", result.SteamID.String())
+		require.True(t, result.Length >= 0, "negative duration")
+		require.Equal(t, result.Length == 0, result.Permanent)
+		require.Truef(t, result.CreatedOn.Unix() > minValidBanTime, "Date: %s", result.CreatedOn)
+	}
 
-	testParser(t, newBioCraftingScraper, 30, "index.php?p=banlist&page=2")
-}
+	actual, errJSON := json.MarshalIndent(sbGolden{
+		NextURL: scraper.nextURL(scraper, doc.Selection),
+		Records: results,
+	}, "", "  ")
+	require.NoError(t, errJSON)
 
-func TestBigBangGamers(t *testing.T) {
-	t.Parallel()
+	goldenPath := filepath.Join("testdata", "golden", string(scraper.name)+".json")
 
-	testParser(t, newBigBangGamersScraper, 5, "")
-}
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(goldenPath, append(actual, '\n'), 0o600))
 
-func TestEpicZone(t *testing.T) {
-	t.Parallel()
+		return
+	}
 
-	testParser(t, newEpicZoneScraper, 30, "index.php?p=banlist&page=2")
+	expected, errRead := os.ReadFile(goldenPath)
+	require.NoErrorf(t, errRead, "Missing golden file, run with -update to create it: %s", goldenPath)
+	require.Equal(t, strings.TrimSpace(string(expected)), string(actual))
 }
 
-func TestZubat(t *testing.T) {
-	t.Parallel()
+// funcName returns the unqualified name of a package level function.
+func funcName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
 
-	testParser(t, newZubatScraper, 30, "index.php?p=banlist&page=2")
+	return name[strings.LastIndex(name, ".")+1:]
 }
 
-func TestLunario(t *testing.T) {
+func TestProbeSourcebans(t *testing.T) {
 	t.Parallel()
 
-	testParser(t, newLunarioScraper, 15, "index.php?p=banlist&page=2")
-}
-
-func TestParseGFLTime(t *testing.T) {
-	t.Parallel()
+	scrapers, errScrapers := createScrapers("./cache/")
+	require.NoError(t, errScrapers)
 
-	parsed, e := parseDefaultTime("2023-05-17 03:07:05")
-	require.NoError(t, e)
-	require.Equal(t, time.Date(2023, time.May, 17, 3, 7, 5, 0, time.UTC), parsed)
-}
+	for _, scraper := range scrapers {
+		testBody, errOpen := os.Open(fmt.Sprintf("testdata/%s.html", scraper.name))
+		if errOpen != nil {
+			continue
+		}
 
-func TestParseWonderlandTime(t *testing.T) {
-	t.Parallel()
+		doc, errDoc := goquery.NewDocumentFromReader(testBody)
+		logCloser(testBody)
+		require.NoError(t, errDoc)
 
-	parsed, e := parseWonderlandTime("May 17th, 2023 (3:07)")
-	require.NoError(t, e)
-	require.Equal(t, time.Date(2023, time.May, 17, 3, 7, 0, 0, time.UTC), parsed)
-}
+		expected, _, errParse := scraper.parser(doc.Selection, slog.Default(), scraper.parseTIme)
+		require.NoError(t, errParse)
 
-func TestParseSkialTime(t *testing.T) {
-	t.Parallel()
+		expectedTheme := ""
 
-	parsed, e := parseSkialTime("05-17-23 03:07")
-	require.NoError(t, e)
-	require.Equal(t, time.Date(2023, time.May, 17, 3, 7, 0, 0, time.UTC), parsed)
+		for _, theme := range sbThemes() {
+			if theme.funcName == funcName(scraper.parser) {
+				expectedTheme = theme.name
+			}
+		}
 
-	perm, ePerm := parseSkialTime("Permanent")
-	require.NoError(t, ePerm)
-	require.Equal(t, time.Time{}, perm)
+		result, errProbe := probeSourcebans(doc.Selection, scraper.url(scraper.startPath))
+		require.NoErrorf(t, errProbe, "Failed to probe: %s", scraper.name)
+		require.Equalf(t, expectedTheme, result.Theme, "Wrong theme: %s", scraper.name)
+		require.Equalf(t, funcName(scraper.parser), result.Parser, "Wrong parser: %s", scraper.name)
+		require.Equalf(t, funcName(scraper.parseTIme), result.TimeParser, "Wrong time parser: %s", scraper.name)
+		require.Equalf(t, expected, result.Records, "Wrong records: %s", scraper.name)
+		require.Equalf(t, strings.TrimRight(scraper.baseURL, "/"), strings.TrimRight(result.BaseURL, "/"),
+			"Wrong base url: %s", scraper.name)
+
+		if next := scraper.nextURL(scraper, doc.Selection); strings.Contains(next, "page=") {
+			require.Equalf(t, next, result.NextPage, "Wrong next page: %s", scraper.name)
+		}
+	}
 }
 
-func TestParsePancakesTime(t *testing.T) {
+func TestProbeURLParts(t *testing.T) {
 	t.Parallel()
 
-	parsed, e := parsePancakesTime("Thu, May 17, 2023 3:07 AM")
-	require.NoError(t, e)
-	require.Equal(t, time.Date(2023, time.May, 17, 3, 7, 0, 0, time.UTC), parsed)
+	baseURL, startPath, name, errURL := probeURLParts("https://bans.example-gaming.com/sb/index.php?p=banlist")
+	require.NoError(t, errURL)
+	require.Equal(t, "https://bans.example-gaming.com/sb/", baseURL)
+	require.Equal(t, "", startPath)
+	require.Equal(t, "example-gaming", name)
+	require.Equal(t, "ExampleGaming", goIdent(name))
 
-	perm, ePerm := parsePancakesTime("never, this is permanent")
-	require.NoError(t, ePerm)
-	require.Equal(t, time.Time{}, perm)
-}
-
-func TestParseFluxTFTime(t *testing.T) {
-	t.Parallel()
-
-	parsed, e := parseFluxTime("Tuesday 30th of August 2022 08:30:45 PM")
-	require.NoError(t, e)
-	require.Equal(t, time.Date(2022, time.August, 30, 20, 30, 45, 0, time.UTC), parsed)
+	_, startPath, _, errURL = probeURLParts("https://example.com/bans/index.php?p=banlist&hideinactive=true")
+	require.NoError(t, errURL)
+	require.Equal(t, "index.php?p=banlist&hideinactive=true", startPath)
 }
 
 // func TestParseMegaScatter(t *testing.T) {
@@ -668,26 +367,25 @@ func TestParseFluxTFTime(t *testing.T) {
 //}
 
 func TestCFBotProtectedRequest(t *testing.T) { //nolint:paralleltest
-	if os.Getenv("$DISPLAY") == "" && os.Getenv("DISPLAY") == "" {
-		t.Skip("No display found")
-	}
-
 	t.Skip("fix parser")
 
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
 	var (
 		url = "https://bans.wonderland.tf/index.php?p=banlist&page=2"
-		cdt = newCFTransport()
+		cdt = newCFTransport(string(domain.Wonderland))
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-	defer cancel()
-
-	require.NoError(t, cdt.Open(ctx))
+	cdt.pool = newBrowserPool(ctx, t.TempDir(), 1, true)
+	defer cdt.pool.Close()
 
 	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	require.NoError(t, reqErr)
 
 	resp, errResp := cdt.RoundTrip(req)
+	require.NoError(t, errResp)
+
 	doc, errDoc := goquery.NewDocumentFromReader(resp.Body)
 	require.NoError(t, errDoc)
 
@@ -701,7 +399,6 @@ func TestCFBotProtectedRequest(t *testing.T) { //nolint:paralleltest
 	nextPage := "https://bans.wonderland.tf/index.php?p=banlist&page=3"
 	next := scraper.nextURL(scraper, doc.Selection)
 	require.Equal(t, scraper.url(nextPage), next)
-	require.NoError(t, errResp)
 	require.NoError(t, resp.Body.Close())
 	require.Equal(t, resp.StatusCode, http.StatusOK)
 }
@@ -76,6 +76,16 @@ func newStore(ctx context.Context, dsn string) (*pgStore, error) {
 	return &database, nil
 }
 
+// viewErr is dbErr for materialized views, which can't be queried until they have been refreshed once.
+func viewErr(err error, wrapMsg string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ObjectNotInPrerequisiteState {
+		return errors.Join(err, errDatabaseNoResults)
+	}
+
+	return dbErr(err, wrapMsg)
+}
+
 func dbErr(err error, wrapMsg string) error {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
@@ -538,7 +548,7 @@ func (db *pgStore) playerGetExpiredProfiles(ctx context.Context, limit int) ([]P
 
 func (db *pgStore) sourcebansSiteGetOrCreate(ctx context.Context, name domain.Site, site *domain.SbSite) error {
 	query, args, errSQL := sb.
-		Select("sb_site_id", "name", "updated_on", "created_on").
+		Select("sb_site_id", "name", "enabled", "last_scraped_on", "timezone", "updated_on", "created_on").
 		From("sb_site").
 		Where(sq.Eq{"name": name}).
 		ToSql()
@@ -548,10 +558,12 @@ func (db *pgStore) sourcebansSiteGetOrCreate(ctx context.Context, name domain.Si
 
 	if errQuery := db.pool.
 		QueryRow(ctx, query, args...).
-		Scan(&site.SiteID, &site.Name, &site.UpdatedOn, &site.CreatedOn); errQuery != nil {
+		Scan(&site.SiteID, &site.Name, &site.Enabled, &site.LastScrapedOn, &site.Timezone,
+			&site.UpdatedOn, &site.CreatedOn); errQuery != nil {
 		wrappedErr := dbErr(errQuery, "Failed to query sourcebans site")
 		if errors.Is(wrappedErr, errDatabaseNoResults) {
 			site.Name = name
+			site.Enabled = true
 
 			return db.sourcebansSiteSave(ctx, site)
 		}
@@ -562,6 +574,8 @@ func (db *pgStore) sourcebansSiteGetOrCreate(ctx context.Context, name domain.Si
 	return nil
 }
 
+// sourcebansSiteSave creates or updates a site. The last scrape time is left alone, it is only set by
+// sourcebansSiteScraped.
 func (db *pgStore) sourcebansSiteSave(ctx context.Context, site *domain.SbSite) error {
 	site.UpdatedOn = time.Now()
 
@@ -570,8 +584,8 @@ func (db *pgStore) sourcebansSiteSave(ctx context.Context, site *domain.SbSite)
 
 		query, args, errSQL := sb.
 			Insert("sb_site").
-			Columns("name", "updated_on", "created_on").
-			Values(site.Name, site.UpdatedOn, site.CreatedOn).
+			Columns("name", "enabled", "timezone", "updated_on", "created_on").
+			Values(site.Name, site.Enabled, site.Timezone, site.UpdatedOn, site.CreatedOn).
 			Suffix("RETURNING sb_site_id").
 			ToSql()
 		if errSQL != nil {
@@ -588,7 +602,10 @@ func (db *pgStore) sourcebansSiteSave(ctx context.Context, site *domain.SbSite)
 	query, args, errSQL := sb.
 		Update("sb_site").
 		Set("name", site.Name).
+		Set("enabled", site.Enabled).
+		Set("timezone", site.Timezone).
 		Set("updated_on", site.UpdatedOn).
+		Where(sq.Eq{"sb_site_id": site.SiteID}).
 		ToSql()
 	if errSQL != nil {
 		return dbErr(errSQL, "Failed to generate query")
@@ -603,7 +620,7 @@ func (db *pgStore) sourcebansSiteSave(ctx context.Context, site *domain.SbSite)
 
 func (db *pgStore) sourcebansSiteGet(ctx context.Context, siteID int, site *domain.SbSite) error {
 	query, args, errSQL := sb.
-		Select("sb_site_id", "name", "updated_on", "created_on").
+		Select("sb_site_id", "name", "enabled", "last_scraped_on", "timezone", "updated_on", "created_on").
 		From("sb_site").
 		Where(sq.Eq{"sb_site_id": siteID}).
 		ToSql()
@@ -612,7 +629,8 @@ func (db *pgStore) sourcebansSiteGet(ctx context.Context, siteID int, site *doma
 	}
 
 	if errQuery := db.pool.QueryRow(ctx, query, args...).
-		Scan(&site.SiteID, &site.Name, &site.UpdatedOn, &site.CreatedOn); errQuery != nil {
+		Scan(&site.SiteID, &site.Name, &site.Enabled, &site.LastScrapedOn, &site.Timezone,
+			&site.UpdatedOn, &site.CreatedOn); errQuery != nil {
 		return dbErr(errQuery, "Failed to scan sourcebans site")
 	}
 
@@ -621,7 +639,7 @@ func (db *pgStore) sourcebansSiteGet(ctx context.Context, siteID int, site *doma
 
 func (db *pgStore) sourcebansSites(ctx context.Context) ([]domain.SbSite, error) {
 	query, args, errSQL := sb.
-		Select("sb_site_id", "name", "updated_on", "created_on").
+		Select("sb_site_id", "name", "enabled", "last_scraped_on", "timezone", "updated_on", "created_on").
 		From("sb_site").
 		ToSql()
 	if errSQL != nil {
@@ -639,7 +657,8 @@ func (db *pgStore) sourcebansSites(ctx context.Context) ([]domain.SbSite, error)
 
 	for rows.Next() {
 		var site domain.SbSite
-		if errQuery := rows.Scan(&site.SiteID, &site.Name, &site.UpdatedOn, &site.CreatedOn); errQuery != nil {
+		if errQuery := rows.Scan(&site.SiteID, &site.Name, &site.Enabled, &site.LastScrapedOn, &site.Timezone,
+			&site.UpdatedOn, &site.CreatedOn); errQuery != nil {
 			return nil, dbErr(errQuery, "Failed to scan sourcebans site")
 		}
 
@@ -649,6 +668,121 @@ func (db *pgStore) sourcebansSites(ctx context.Context) ([]domain.SbSite, error)
 	return sites, nil
 }
 
+func (db *pgStore) sourcebansSiteGetByName(ctx context.Context, name domain.Site, site *domain.SbSite) error {
+	query, args, errSQL := sb.
+		Select("sb_site_id", "name", "enabled", "last_scraped_on", "timezone", "updated_on", "created_on").
+		From("sb_site").
+		Where(sq.Eq{"name": name}).
+		ToSql()
+	if errSQL != nil {
+		return dbErr(errSQL, "Failed to generate query")
+	}
+
+	if errQuery := db.pool.QueryRow(ctx, query, args...).
+		Scan(&site.SiteID, &site.Name, &site.Enabled, &site.LastScrapedOn, &site.Timezone,
+			&site.UpdatedOn, &site.CreatedOn); errQuery != nil {
+		return dbErr(errQuery, "Failed to scan sourcebans site")
+	}
+
+	return nil
+}
+
+// sbSiteSummary is a sourcebans site along with the number of bans collected from it.
+type sbSiteSummary struct {
+	domain.SbSite
+	BanCount int
+}
+
+func (db *pgStore) sourcebansSiteSummaries(ctx context.Context) ([]sbSiteSummary, error) {
+	query, args, errSQL := sb.
+		Select("s.sb_site_id", "s.name", "s.enabled", "s.last_scraped_on", "s.timezone", "s.updated_on", "s.created_on",
+			"count(b.sb_ban_id)").
+		From("sb_site s").
+		LeftJoin("sb_ban b ON b.sb_site_id = s.sb_site_id").
+		GroupBy("s.sb_site_id").
+		OrderBy("s.name").
+		ToSql()
+	if errSQL != nil {
+		return nil, dbErr(errSQL, "Failed to generate query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query sourcebans sites")
+	}
+
+	defer rows.Close()
+
+	summaries := make([]sbSiteSummary, 0)
+
+	for rows.Next() {
+		var summary sbSiteSummary
+		if errScan := rows.Scan(&summary.SiteID, &summary.Name, &summary.Enabled, &summary.LastScrapedOn,
+			&summary.Timezone, &summary.UpdatedOn, &summary.CreatedOn, &summary.BanCount); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan sourcebans site")
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// sourcebansSiteScraped records the time of the last completed scrape of a site.
+func (db *pgStore) sourcebansSiteScraped(ctx context.Context, siteID int) error {
+	query, args, errSQL := sb.
+		Update("sb_site").
+		Set("last_scraped_on", time.Now()).
+		Where(sq.Eq{"sb_site_id": siteID}).
+		ToSql()
+	if errSQL != nil {
+		return dbErr(errSQL, "Failed to generate query")
+	}
+
+	if _, errQuery := db.pool.Exec(ctx, query, args...); errQuery != nil {
+		return dbErr(errQuery, "Failed to update sourcebans site")
+	}
+
+	return nil
+}
+
+// sourcebansSitePurge deletes all bans collected from a site and disables it. The site itself is kept so that
+// it is not recreated on the next scrape.
+func (db *pgStore) sourcebansSitePurge(ctx context.Context, siteID int) (int64, error) {
+	transaction, errBegin := db.pool.Begin(ctx)
+	if errBegin != nil {
+		return 0, dbErr(errBegin, "Failed to start tx")
+	}
+
+	defer func() {
+		if err := transaction.Rollback(ctx); err != nil {
+			if !errors.Is(err, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback sourcebans purge tx", ErrAttr(err))
+			}
+		}
+	}()
+
+	tag, errBans := transaction.Exec(ctx, `DELETE FROM sb_ban WHERE sb_site_id = $1`, siteID)
+	if errBans != nil {
+		return 0, dbErr(errBans, "Failed to delete sourcebans bans")
+	}
+
+	if _, err := transaction.Exec(ctx, `DELETE FROM sb_site_checkpoint WHERE sb_site_id = $1`, siteID); err != nil {
+		return 0, dbErr(err, "Failed to delete sourcebans checkpoint")
+	}
+
+	if _, err := transaction.Exec(ctx, `UPDATE sb_site SET enabled = false, updated_on = $2 WHERE sb_site_id = $1`,
+		siteID, time.Now()); err != nil {
+		return 0, dbErr(err, "Failed to disable sourcebans site")
+	}
+
+	if err := transaction.Commit(ctx); err != nil {
+		return 0, dbErr(err, "Failed to commit")
+	}
+
+	return tag.RowsAffected(), nil
+}
+
 func (db *pgStore) sourcebansSiteDelete(ctx context.Context, siteID int) error {
 	query, args, errSQL := sb.
 		Delete("sb_site").
@@ -665,15 +799,79 @@ func (db *pgStore) sourcebansSiteDelete(ctx context.Context, siteID int) error {
 	return nil
 }
 
+// sbCheckpoint records the next page of a site to visit, so an interrupted crawl can resume from it.
+type sbCheckpoint struct {
+	SiteID    int
+	Page      int
+	URL       string
+	UpdatedOn time.Time
+}
+
+func (db *pgStore) sourcebansCheckpointGet(ctx context.Context, siteID int) (sbCheckpoint, error) {
+	checkpoint := sbCheckpoint{SiteID: siteID} //nolint:exhaustruct
+
+	query, args, errSQL := sb.
+		Select("page", "url", "updated_on").
+		From("sb_site_checkpoint").
+		Where(sq.Eq{"sb_site_id": siteID}).
+		ToSql()
+	if errSQL != nil {
+		return checkpoint, dbErr(errSQL, "Failed to generate query")
+	}
+
+	if errQuery := db.pool.
+		QueryRow(ctx, query, args...).
+		Scan(&checkpoint.Page, &checkpoint.URL, &checkpoint.UpdatedOn); errQuery != nil {
+		return checkpoint, dbErr(errQuery, "Failed to query sourcebans checkpoint")
+	}
+
+	return checkpoint, nil
+}
+
+func (db *pgStore) sourcebansCheckpointSave(ctx context.Context, checkpoint *sbCheckpoint) error {
+	const query = `
+		INSERT INTO sb_site_checkpoint (sb_site_id, page, url, updated_on)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (sb_site_id)
+		DO UPDATE SET page = $2, url = $3, updated_on = $4`
+
+	checkpoint.UpdatedOn = time.Now()
+
+	if _, errQuery := db.pool.Exec(ctx, query,
+		checkpoint.SiteID, checkpoint.Page, checkpoint.URL, checkpoint.UpdatedOn); errQuery != nil {
+		return dbErr(errQuery, "Failed to save sourcebans checkpoint")
+	}
+
+	return nil
+}
+
+func (db *pgStore) sourcebansCheckpointDelete(ctx context.Context, siteID int) error {
+	query, args, errSQL := sb.
+		Delete("sb_site_checkpoint").
+		Where(sq.Eq{"sb_site_id": siteID}).
+		ToSql()
+	if errSQL != nil {
+		return dbErr(errSQL, "Failed to generate query")
+	}
+
+	if _, errQuery := db.pool.Exec(ctx, query, args...); errQuery != nil {
+		return dbErr(errQuery, "Failed to delete sourcebans checkpoint")
+	}
+
+	return nil
+}
+
 func (db *pgStore) sourcebansBanRecordSave(ctx context.Context, record *domain.SbBanRecord) error {
 	record.UpdatedOn = time.Now()
 
 	if record.BanID <= 0 {
 		query, args, errSQL := sb.
 			Insert("sb_ban").
-			Columns("sb_site_id", "steam_id", "persona_name", "reason", "created_on", "duration", "permanent").
-			Values(record.SiteID, record.SteamID.Int64(), record.PersonaName, record.Reason, record.CreatedOn,
-				record.Duration.Seconds(), record.Permanent).
+			Columns("sb_site_id", "steam_id", "persona_name", "reason", "reason_category", "created_on", "duration",
+				"permanent", "duration_mismatch", "admin_name", "server", "total_bans", "demo_url").
+			Values(record.SiteID, record.SteamID.Int64(), record.PersonaName, record.Reason, record.ReasonCategory,
+				record.CreatedOn, record.Duration.Seconds(), record.Permanent, record.DurationMismatch,
+				record.AdminName, record.Server, record.TotalBans, record.DemoURL).
 			Suffix("RETURNING sb_ban_id").
 			ToSql()
 		if errSQL != nil {
@@ -693,9 +891,15 @@ func (db *pgStore) sourcebansBanRecordSave(ctx context.Context, record *domain.S
 		Set("steam_id", record.SteamID.Int64()).
 		Set("persona_name", record.PersonaName).
 		Set("reason", record.Reason).
+		Set("reason_category", record.ReasonCategory).
 		Set("created_on", record.CreatedOn).
 		Set("duration", record.Duration.Seconds()).
 		Set("permanent", record.Permanent).
+		Set("duration_mismatch", record.DurationMismatch).
+		Set("admin_name", record.AdminName).
+		Set("server", record.Server).
+		Set("total_bans", record.TotalBans).
+		Set("demo_url", record.DemoURL).
 		ToSql()
 	if errSQL != nil {
 		return dbErr(errSQL, "Failed to generate query")
@@ -713,19 +917,27 @@ func (db *pgStore) sourcebansBanRecordSave(ctx context.Context, record *domain.S
 
 type BanRecordMap map[string][]domain.SbBanRecord
 
-func (db *pgStore) sourcebansRecordBySID(ctx context.Context, sids steamid.Collection) (BanRecordMap, error) {
+func (db *pgStore) sourcebansRecordBySID(ctx context.Context, sids steamid.Collection,
+	categories []domain.BanCategory,
+) (BanRecordMap, error) {
 	ids := make([]int64, len(sids))
 	for idx := range sids {
 		ids[idx] = sids[idx].Int64()
 	}
 
-	query, args, errSQL := sb.
-		Select("b.sb_ban_id", "b.sb_site_id", "b.steam_id", "b.persona_name", "b.reason",
-			"b.created_on", "b.duration", "b.permanent", "s.name").
+	builder := sb.
+		Select("b.sb_ban_id", "b.sb_site_id", "b.steam_id", "b.persona_name", "b.reason", "b.reason_category",
+			"b.created_on", "b.duration", "b.permanent", "b.duration_mismatch", "b.admin_name", "b.server",
+			"b.total_bans", "b.demo_url", "s.name").
 		From("sb_ban b").
 		LeftJoin("sb_site s ON b.sb_site_id = s.sb_site_id").
-		Where(sq.Eq{"steam_id": ids}).
-		ToSql()
+		Where(sq.Eq{"steam_id": ids})
+
+	if len(categories) > 0 {
+		builder = builder.Where(sq.Eq{"b.reason_category": categories})
+	}
+
+	query, args, errSQL := builder.ToSql()
 	if errSQL != nil {
 		return nil, dbErr(errSQL, "Failed to generate query")
 	}
@@ -750,7 +962,10 @@ func (db *pgStore) sourcebansRecordBySID(ctx context.Context, sids steamid.Colle
 			sid      int64
 		)
 		if errScan := rows.Scan(&bRecord.BanID, &bRecord.SiteID, &sid, &bRecord.PersonaName,
-			&bRecord.Reason, &bRecord.CreatedOn, &duration, &bRecord.Permanent, &bRecord.SiteName); errScan != nil {
+			&bRecord.Reason, &bRecord.ReasonCategory, &bRecord.CreatedOn, &duration, &bRecord.Permanent,
+			&bRecord.DurationMismatch,
+			&bRecord.AdminName, &bRecord.Server, &bRecord.TotalBans, &bRecord.DemoURL,
+			&bRecord.SiteName); errScan != nil {
 			return nil, dbErr(errScan, "Failed to scan sourcebans ban")
 		}
 
@@ -1076,6 +1291,14 @@ func (db *pgStore) logsTFMatchCreate(ctx context.Context, match *domain.LogsTFMa
 		return dbErr(err, "Failed to insert logstf medic")
 	}
 
+	if err := db.logsTFMatchChatInsert(ctx, transaction, match.Chat); err != nil {
+		return err
+	}
+
+	if err := db.logsTFMatchKillstreaksInsert(ctx, transaction, match.Killstreaks); err != nil {
+		return err
+	}
+
 	if err := transaction.Commit(ctx); err != nil {
 		return dbErr(err, "Failed to commit")
 	}
@@ -1085,13 +1308,13 @@ func (db *pgStore) logsTFMatchCreate(ctx context.Context, match *domain.LogsTFMa
 
 func (db *pgStore) logsTFMatchGet(ctx context.Context, logID int) (*domain.LogsTFMatch, error) {
 	const query = `
-		SELECT log_id, title, map, format, views, duration, score_red, score_blu, created_on 
+		SELECT log_id, source, title, map, format, views, duration, score_red, score_blu, created_on 
 		FROM logstf
 		WHERE log_id = $1`
 
 	var match domain.LogsTFMatch
 	if err := db.pool.QueryRow(ctx, query, logID).
-		Scan(&match.LogID, &match.Title, &match.Map, &match.Format, &match.Views,
+		Scan(&match.LogID, &match.Source, &match.Title, &match.Map, &match.Format, &match.Views,
 			&match.Duration.Duration, &match.ScoreRED, &match.ScoreBLU, &match.CreatedOn); err != nil {
 		return nil, dbErr(err, "Failed to query match by id")
 	}
@@ -1122,12 +1345,34 @@ func (db *pgStore) logsTFMatchGet(ctx context.Context, logID int) (*domain.LogsT
 
 	match.Rounds = rounds
 
+	chat, errChat := db.logsTFMatchChat(ctx, logID)
+	if errChat != nil {
+		return nil, errChat
+	}
+
+	if chat == nil {
+		chat = []domain.LogsTFChat{}
+	}
+
+	match.Chat = chat
+
+	killstreaks, errKillstreaks := db.logsTFMatchKillstreaks(ctx, logID)
+	if errKillstreaks != nil {
+		return nil, errKillstreaks
+	}
+
+	if killstreaks == nil {
+		killstreaks = []domain.LogsTFKillstreak{}
+	}
+
+	match.Killstreaks = killstreaks
+
 	return &match, nil
 }
 
 func (db *pgStore) logsTFMatchPlayers(ctx context.Context, logID int) ([]domain.LogsTFPlayer, error) {
 	const query = `
-		SELECT log_id, steam_id, team, name, kills, assists, deaths, damage, dpm, kad, kd, dt, dtm, hp, bs, hs, caps, healing_taken 
+		SELECT log_id, steam_id, team, name, kills, assists, deaths, damage, dpm, kad, kd, dt, dtm, hp, bs, hs, caps, healing_taken, airshots 
 		FROM logstf_player
 		WHERE log_id = $1`
 
@@ -1143,7 +1388,8 @@ func (db *pgStore) logsTFMatchPlayers(ctx context.Context, logID int) ([]domain.
 	for rows.Next() {
 		var player domain.LogsTFPlayer
 		if errScan := rows.Scan(&player.LogID, &player.SteamID, &player.Team, &player.Name, &player.Kills, &player.Assists, &player.Deaths, &player.Damage, &player.DPM,
-			&player.KAD, &player.KD, &player.DamageTaken, &player.DTM, &player.HealthPacks, &player.Backstabs, &player.Headshots, &player.Caps, &player.HealingTaken); errScan != nil {
+			&player.KAD, &player.KD, &player.DamageTaken, &player.DTM, &player.HealthPacks, &player.Backstabs, &player.Headshots, &player.Caps, &player.HealingTaken,
+			&player.Airshots); errScan != nil {
 			return nil, dbErr(errScan, "Failed to scan player")
 		}
 
@@ -1155,10 +1401,23 @@ func (db *pgStore) logsTFMatchPlayers(ctx context.Context, logID int) ([]domain.
 		return nil, errClasses
 	}
 
+	weapons, errWeapons := db.logsTFMatchWeapons(ctx, logID)
+	if errWeapons != nil {
+		return nil, errWeapons
+	}
+
+	for classIdx := range classes {
+		for _, weapon := range weapons {
+			if weapon.SteamID == classes[classIdx].SteamID && weapon.Class == classes[classIdx].Class {
+				classes[classIdx].Weapons = append(classes[classIdx].Weapons, weapon)
+			}
+		}
+	}
+
 	for _, class := range classes {
-		for _, player := range players {
-			if class.SteamID == player.SteamID {
-				player.Classes = append(player.Classes, class)
+		for playerIdx := range players {
+			if class.SteamID == players[playerIdx].SteamID {
+				players[playerIdx].Classes = append(players[playerIdx].Classes, class)
 			}
 		}
 	}
@@ -1178,10 +1437,11 @@ func (db *pgStore) logsTFPlayerSummary(ctx context.Context, steamID steamid.Stea
 			coalesce(round(avg(p.dtm)::numeric, 2), 0), coalesce(round(avg(p.hp)::numeric, 2), 0),
 			coalesce(round(avg(p.bs)::numeric, 2), 0), coalesce(round(avg(p.hs)::numeric, 2), 0),
 			coalesce(round(avg(p.caps)::numeric, 2), 0), coalesce(round(avg(p.healing_taken)::numeric, 2), 0),
+			coalesce(round(avg(p.airshots)::numeric, 2), 0),
 		
 			coalesce(sum(p.kills), 0), coalesce(sum(p.assists), 0), coalesce(sum(p.deaths), 0), coalesce(sum(p.damage), 0),
 			coalesce(sum(p.dt), 0), coalesce(sum(p.hp), 0), coalesce(sum(p.bs), 0), coalesce(sum(p.hs), 0),
-			coalesce(sum(p.caps), 0), coalesce(sum(p.healing_taken), 0)
+			coalesce(sum(p.caps), 0), coalesce(sum(p.healing_taken), 0), coalesce(sum(p.airshots), 0)
 		FROM logstf_player p
 		LEFT JOIN public.logstf l on l.log_id = p.log_id
 		WHERE steam_id = $1`
@@ -1193,122 +1453,496 @@ func (db *pgStore) logsTFPlayerSummary(ctx context.Context, steamID steamid.Stea
 			&sum.KillsAvg.Value, &sum.AssistsAvg.Value, &sum.DeathsAvg.Value, &sum.DamageAvg.Value,
 			&sum.DPMAvg.Value, &sum.KADAvg.Value, &sum.KDAvg.Value, &sum.DamageTakenAvg.Value, &sum.DTMAvg.Value,
 			&sum.HealthPacksAvg.Value, &sum.BackstabsAvg.Value, &sum.HeadshotsAvg.Value, &sum.CapsAvg.Value, &sum.HealingTakenAvg.Value,
+			&sum.AirshotsAvg.Value,
 			&sum.KillsSum, &sum.AssistsSum, &sum.DeathsSum, &sum.DamageSum,
 			&sum.DamageTakenSum,
 			&sum.HealthPacksSum, &sum.BackstabsSum, &sum.HeadshotsSum, &sum.CapsSum, &sum.HealingTakenSum,
+			&sum.AirshotsSum,
 		); errScan != nil {
 		return nil, dbErr(errScan, "Failed to scan player")
 	}
 
+	percentiles, errPercentiles := db.logsTFPlayerPercentiles(ctx, steamID)
+	if errPercentiles != nil && !errors.Is(errPercentiles, errDatabaseNoResults) {
+		return nil, errPercentiles
+	}
+
+	sum.Percentiles = percentiles
+
 	return &sum, nil
 }
 
-func (db *pgStore) logsTFMatchRounds(ctx context.Context, logID int) ([]domain.LogsTFRound, error) {
+// logsTFPlayerPercentiles returns the players percentile ranks. Players with too few matches, or before the
+// view has been refreshed for the first time, return errDatabaseNoResults.
+func (db *pgStore) logsTFPlayerPercentiles(ctx context.Context, steamID steamid.SteamID) (*domain.LogsTFPercentiles, error) {
 	const query = `
-		SELECT log_id, round, length, score_blu, score_red, kills_blu, kills_red, ubers_blu, ubers_red, damage_blu, damage_red, midfight 
-		FROM logstf_round
-		WHERE log_id = $1`
+		SELECT dpm, kd, kad, kills, damage, dtm, airshots, headshots
+		FROM logstf_player_percentile
+		WHERE steam_id = $1`
 
-	rows, err := db.pool.Query(ctx, query, logID)
-	if err != nil {
-		return nil, dbErr(err, "Failed to query rounds")
+	var percentiles domain.LogsTFPercentiles
+	if errScan := db.pool.QueryRow(ctx, query, steamID.Int64()).
+		Scan(&percentiles.DPM, &percentiles.KD, &percentiles.KAD, &percentiles.Kills, &percentiles.Damage,
+			&percentiles.DTM, &percentiles.Airshots, &percentiles.Headshots); errScan != nil {
+		return nil, viewErr(errScan, "Failed to query player percentiles")
+	}
+
+	return &percentiles, nil
+}
+
+// logsTFLeaderboard returns the top players of a class for the stat. Medic only stats return no results for
+// other classes.
+func (db *pgStore) logsTFLeaderboard(ctx context.Context, stat logsTFLeaderboardStat, class domain.PlayerClass,
+	format string, minGames int, limit uint64,
+) ([]domain.LogsTFLeaderboardEntry, error) {
+	expr, found := stat.expr()
+	if !found {
+		return nil, errDatabaseNoResults
+	}
+
+	query, args, errSQL := sb.
+		Select("lb.steam_id", "coalesce(pl.persona_name, '')", "lb.games",
+			fmt.Sprintf("round((%s)::numeric, 2) AS value", expr)).
+		From("logstf_leaderboard lb").
+		LeftJoin("player pl ON pl.steam_id = lb.steam_id").
+		Where(sq.Eq{"lb.player_class": class, "lb.format": format}).
+		Where(sq.GtOrEq{"lb.games": minGames}).
+		OrderBy("value DESC", "lb.games DESC").
+		Limit(limit).
+		ToSql()
+	if errSQL != nil {
+		return nil, dbErr(errSQL, "Failed to generate query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, viewErr(errRows, "Failed to query leaderboard")
 	}
 
 	defer rows.Close()
 
-	var rounds []domain.LogsTFRound
+	var entries []domain.LogsTFLeaderboardEntry
 
 	for rows.Next() {
-		var round domain.LogsTFRound
-		if errScan := rows.Scan(&round.LogID, &round.Round, &round.Length.Duration, &round.ScoreBLU, &round.ScoreRED, &round.KillsBLU, &round.KillsRED,
-			&round.UbersBLU, &round.UbersRED, &round.DamageBLU, &round.DamageRED, &round.MidFight); errScan != nil {
-			return nil, dbErr(errScan, "Failed to scan round")
+		entry := domain.LogsTFLeaderboardEntry{Rank: len(entries) + 1} //nolint:exhaustruct
+		if errScan := rows.Scan(&entry.SteamID, &entry.Name, &entry.Games, &entry.Value.Value); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan leaderboard entry")
 		}
 
-		rounds = append(rounds, round)
+		entries = append(entries, entry)
 	}
 
-	return rounds, nil
+	if errRows := rows.Err(); errRows != nil {
+		return nil, viewErr(errRows, "Failed to query leaderboard")
+	}
+
+	return entries, nil
 }
 
-func (db *pgStore) logsTFMatchPlayerClasses(ctx context.Context, logID int) ([]domain.LogsTFPlayerClass, error) {
-	const query = `
-		SELECT log_id, steam_id, player_class, played, kills, assists, deaths, damage 
-		FROM logstf_player_class
-		WHERE log_id = $1`
+// logsTFLeaderboardsRefresh rebuilds the leaderboard and percentile views. Views are refreshed concurrently, so
+// they can still be read during the refresh, once they have been populated for the first time.
+func (db *pgStore) logsTFLeaderboardsRefresh(ctx context.Context) error {
+	for _, view := range []string{"logstf_leaderboard", "logstf_player_percentile"} {
+		var populated bool
+		if errPopulated := db.pool.QueryRow(ctx, `SELECT ispopulated FROM pg_matviews WHERE matviewname = $1`, view).
+			Scan(&populated); errPopulated != nil {
+			return dbErr(errPopulated, "Failed to check view")
+		}
 
-	rows, err := db.pool.Query(ctx, query, logID)
-	if err != nil {
-		return nil, dbErr(err, "Failed to query classes")
+		query := "REFRESH MATERIALIZED VIEW " + view
+		if populated {
+			query = "REFRESH MATERIALIZED VIEW CONCURRENTLY " + view
+		}
+
+		if _, errRefresh := db.pool.Exec(ctx, query); errRefresh != nil {
+			return dbErr(errRefresh, "Failed to refresh "+view)
+		}
 	}
 
-	defer rows.Close()
+	return nil
+}
 
-	var classes []domain.LogsTFPlayerClass
+// logsTFStatsFilter limits player stats to a subset of their matches.
+type logsTFStatsFilter struct {
+	Format domain.LogsTFFormat
+	// Map matches the start of the map name, so that all versions of a map are included
+	Map   string
+	Since time.Time
+	Until time.Time
+}
 
-	for rows.Next() {
-		var p domain.LogsTFPlayerClass
-		if errScan := rows.Scan(&p.LogID, &p.SteamID, &p.Class, &p.Played.Duration, &p.Kills, &p.Assists, &p.Deaths, &p.Damage); errScan != nil {
-			return nil, dbErr(errScan, "Failed to scan player class")
-		}
+func (f logsTFStatsFilter) apply(builder sq.SelectBuilder) sq.SelectBuilder {
+	if f.Format != domain.FormatUnknown {
+		builder = builder.Where(sq.Eq{"l.format": f.Format})
+	}
 
-		classes = append(classes, p)
+	if f.Map != "" {
+		builder = builder.Where(sq.ILike{"l.map": escapeLike(f.Map) + "%"})
 	}
 
-	return classes, nil
+	if !f.Since.IsZero() {
+		builder = builder.Where(sq.GtOrEq{"l.created_on": f.Since})
+	}
+
+	if !f.Until.IsZero() {
+		builder = builder.Where(sq.Lt{"l.created_on": f.Until})
+	}
+
+	return builder
 }
 
-func (db *pgStore) logsTFMatchMedics(ctx context.Context, logID int) ([]domain.LogsTFMedic, error) {
-	const query = `
-		SELECT log_id, steam_id, healing, charges_kritz, charges_quickfix, charges_medigun, charges_vacc, avg_time_build, 
-		       avg_time_use, near_full_death, avg_uber_len, death_after_charge, major_adv_lost, biggest_adv_lost 
-		FROM logstf_medic
-		WHERE log_id = $1`
+// escapeLike escapes the LIKE wildcards in user input, so that it only matches literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
 
-	rows, err := db.pool.Query(ctx, query, logID)
-	if err != nil {
-		return nil, dbErr(err, "Failed to query medics")
+// logsTFMapStats returns the results of the matches played on each map, most played first.
+func (db *pgStore) logsTFMapStats(ctx context.Context, filter logsTFStatsFilter, limit uint64) ([]domain.LogsTFMapStats, error) {
+	builder := sb.
+		Select("l.map", "count(*)", "coalesce(avg(l.duration), 0)::bigint",
+			"count(*) FILTER (WHERE l.score_red > l.score_blu)",
+			"count(*) FILTER (WHERE l.score_blu > l.score_red)",
+			"count(*) FILTER (WHERE l.score_red = l.score_blu)",
+			"coalesce(sum(r.rounds), 0)", "coalesce(round(avg(r.rounds), 2), 0)").
+		From("logstf l").
+		// Old logs have no round data, so they are excluded from the average rather than counted as 0 rounds.
+		JoinClause("LEFT JOIN LATERAL (SELECT nullif(count(*), 0) AS rounds FROM logstf_round WHERE log_id = l.log_id) r ON true").
+		Where(sq.NotEq{"l.map": ""}).
+		Where(sq.Eq{"l.source": domain.SourceLogsTF}).
+		GroupBy("l.map").
+		OrderBy("count(*) DESC", "l.map").
+		Limit(limit)
+
+	query, args, errSQL := filter.apply(builder).ToSql()
+	if errSQL != nil {
+		return nil, dbErr(errSQL, "Failed to generate query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query map stats")
 	}
 
 	defer rows.Close()
 
-	var medics []domain.LogsTFMedic
+	var maps []domain.LogsTFMapStats
 
 	for rows.Next() {
-		var medic domain.LogsTFMedic
-		if errScan := rows.Scan(&medic.LogID, &medic.SteamID, &medic.Healing, &medic.ChargesKritz, &medic.ChargesQuickfix, &medic.ChargesMedigun, &medic.ChargesVacc,
-			&medic.AvgTimeBuild.Duration, &medic.AvgTimeUse.Duration, &medic.NearFullDeath, &medic.AvgUberLen.Duration, &medic.DeathAfterCharge, &medic.MajorAdvLost, &medic.BiggestAdvLost.Duration); errScan != nil {
-			return nil, dbErr(errScan, "Failed to scan medic")
+		var stats domain.LogsTFMapStats
+		if errScan := rows.Scan(&stats.Map, &stats.Games, &stats.DurationAvg.Duration, &stats.RedWins, &stats.BluWins,
+			&stats.Ties, &stats.RoundsSum, &stats.RoundsAvg.Value); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan map stats")
 		}
 
-		medics = append(medics, medic)
+		stats.RedWinRate.Value = winRate(stats.RedWins, stats.Games)
+		stats.BluWinRate.Value = winRate(stats.BluWins, stats.Games)
+
+		maps = append(maps, stats)
 	}
 
-	return medics, nil
+	return maps, nil
 }
 
-func (db *pgStore) logsTFMatchInsert(ctx context.Context, transaction pgx.Tx, match *domain.LogsTFMatch) error {
-	matchQuery, matchArgs, errQuery := sb.Insert("logstf").
-		SetMap(map[string]interface{}{
-			"log_id":     match.LogID,
-			"title":      match.Title,
-			"map":        match.Map,
-			"format":     match.Format,
-			"views":      match.Views,
-			"duration":   match.Duration.Duration,
-			"score_red":  match.ScoreRED,
-			"score_blu":  match.ScoreBLU,
-			"created_on": match.CreatedOn,
-		}).ToSql()
-	if errQuery != nil {
-		return dbErr(errQuery, "Failed to build query")
+// logsTFFormatTrends returns the number of matches played in each format, grouped by the interval, oldest first.
+func (db *pgStore) logsTFFormatTrends(ctx context.Context, filter logsTFStatsFilter, interval logsTFTrendInterval) ([]domain.LogsTFFormatTrend, error) {
+	period := fmt.Sprintf("date_trunc('%s', l.created_on)", interval)
+
+	builder := sb.
+		Select(period+" AS period", "l.format", "count(*)", "coalesce(avg(l.duration), 0)::bigint",
+			fmt.Sprintf("round(count(*) * 100.0 / sum(count(*)) OVER (PARTITION BY %s), 2)", period)).
+		From("logstf l").
+		Where(sq.NotEq{"l.format": domain.FormatUnknown}).
+		Where(sq.Eq{"l.source": domain.SourceLogsTF}).
+		GroupBy("period", "l.format").
+		OrderBy("period", "l.format")
+
+	query, args, errSQL := filter.apply(builder).ToSql()
+	if errSQL != nil {
+		return nil, dbErr(errSQL, "Failed to generate query")
 	}
 
-	if _, err := transaction.Exec(ctx, matchQuery, matchArgs...); err != nil {
-		return dbErr(err, "Failed to insert logstf table")
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query format trends")
 	}
 
-	return nil
+	defer rows.Close()
+
+	var trends []domain.LogsTFFormatTrend
+
+	for rows.Next() {
+		var trend domain.LogsTFFormatTrend
+		if errScan := rows.Scan(&trend.Period, &trend.Format, &trend.Games, &trend.DurationAvg.Duration,
+			&trend.Share.Value); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan format trend")
+		}
+
+		trends = append(trends, trend)
+	}
+
+	return trends, nil
+}
+
+// logsTFPlayerClassStats returns the players stats for each class they have played, most played first. Medic
+// stats are only attributed to the medic class.
+func (db *pgStore) logsTFPlayerClassStats(ctx context.Context, steamID steamid.SteamID, filter logsTFStatsFilter) ([]domain.LogsTFPlayerClassStats, error) {
+	builder := sb.
+		Select("c.player_class", "count(c.log_id)", "coalesce(sum(c.played), 0)::bigint",
+			"coalesce(sum(c.kills), 0)", "coalesce(sum(c.assists), 0)", "coalesce(sum(c.deaths), 0)",
+			"coalesce(sum(c.damage), 0)::bigint",
+			"coalesce(round(avg(c.kills), 2), 0)", "coalesce(round(avg(c.assists), 2), 0)",
+			"coalesce(round(avg(c.deaths), 2), 0)", "coalesce(round(avg(c.damage), 2), 0)",
+			"coalesce(sum(m.healing), 0)::bigint",
+			"coalesce(sum(m.charges_kritz + m.charges_quickfix + m.charges_medigun + m.charges_vacc), 0)",
+			"coalesce(avg(m.avg_uber_len), 0)::bigint").
+		From("logstf_player_class c").
+		Join("logstf l ON l.log_id = c.log_id").
+		LeftJoin(fmt.Sprintf("logstf_medic m ON m.log_id = c.log_id AND m.steam_id = c.steam_id AND c.player_class = %d",
+			domain.Medic)).
+		Where(sq.Eq{"c.steam_id": steamID.Int64()}).
+		GroupBy("c.player_class").
+		OrderBy("sum(c.played) DESC")
+
+	query, args, errSQL := filter.apply(builder).ToSql()
+	if errSQL != nil {
+		return nil, dbErr(errSQL, "Failed to generate query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query player class stats")
+	}
+
+	defer rows.Close()
+
+	var stats []domain.LogsTFPlayerClassStats
+
+	for rows.Next() {
+		var class domain.LogsTFPlayerClassStats
+		if errScan := rows.Scan(&class.Class, &class.Games, &class.Played.Duration,
+			&class.KillsSum, &class.AssistsSum, &class.DeathsSum, &class.DamageSum,
+			&class.KillsAvg.Value, &class.AssistsAvg.Value, &class.DeathsAvg.Value, &class.DamageAvg.Value,
+			&class.HealingSum, &class.ChargesSum, &class.AvgUberLen.Duration); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan player class stats")
+		}
+
+		// Rates use the total time played rather than averaging each game, so short games don't skew them.
+		if minutes := class.Played.Minutes(); minutes > 0 {
+			class.DPM.Value = float32(float64(class.DamageSum) / minutes)
+			class.KillsPerMin.Value = float32(float64(class.KillsSum) / minutes)
+			class.HealingPerMin.Value = float32(float64(class.HealingSum) / minutes)
+		}
+
+		class.KD.Value = float32(class.KillsSum) / float32(max(class.DeathsSum, 1))
+		class.KAD.Value = float32(class.KillsSum+class.AssistsSum) / float32(max(class.DeathsSum, 1))
+
+		stats = append(stats, class)
+	}
+
+	return stats, nil
+}
+
+func (db *pgStore) logsTFMatchRounds(ctx context.Context, logID int) ([]domain.LogsTFRound, error) {
+	const query = `
+		SELECT log_id, round, length, score_blu, score_red, kills_blu, kills_red, ubers_blu, ubers_red, damage_blu, damage_red, midfight 
+		FROM logstf_round
+		WHERE log_id = $1`
+
+	rows, err := db.pool.Query(ctx, query, logID)
+	if err != nil {
+		return nil, dbErr(err, "Failed to query rounds")
+	}
+
+	defer rows.Close()
+
+	var rounds []domain.LogsTFRound
+
+	for rows.Next() {
+		var round domain.LogsTFRound
+		if errScan := rows.Scan(&round.LogID, &round.Round, &round.Length.Duration, &round.ScoreBLU, &round.ScoreRED, &round.KillsBLU, &round.KillsRED,
+			&round.UbersBLU, &round.UbersRED, &round.DamageBLU, &round.DamageRED, &round.MidFight); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan round")
+		}
+
+		rounds = append(rounds, round)
+	}
+
+	return rounds, nil
+}
+
+func (db *pgStore) logsTFMatchPlayerClasses(ctx context.Context, logID int) ([]domain.LogsTFPlayerClass, error) {
+	const query = `
+		SELECT log_id, steam_id, player_class, played, kills, assists, deaths, damage 
+		FROM logstf_player_class
+		WHERE log_id = $1`
+
+	rows, err := db.pool.Query(ctx, query, logID)
+	if err != nil {
+		return nil, dbErr(err, "Failed to query classes")
+	}
+
+	defer rows.Close()
+
+	var classes []domain.LogsTFPlayerClass
+
+	for rows.Next() {
+		var p domain.LogsTFPlayerClass
+		if errScan := rows.Scan(&p.LogID, &p.SteamID, &p.Class, &p.Played.Duration, &p.Kills, &p.Assists, &p.Deaths, &p.Damage); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan player class")
+		}
+
+		classes = append(classes, p)
+	}
+
+	return classes, nil
+}
+
+func (db *pgStore) logsTFMatchWeapons(ctx context.Context, logID int) ([]domain.LogsTFPlayerClassWeapon, error) {
+	const query = `
+		SELECT log_id, steam_id, player_class, weapon, kills, damage, accuracy 
+		FROM logstf_player_class_weapon
+		WHERE log_id = $1
+		ORDER BY weapon`
+
+	rows, err := db.pool.Query(ctx, query, logID)
+	if err != nil {
+		return nil, dbErr(err, "Failed to query weapons")
+	}
+
+	defer rows.Close()
+
+	var weapons []domain.LogsTFPlayerClassWeapon
+
+	for rows.Next() {
+		var weapon domain.LogsTFPlayerClassWeapon
+		if errScan := rows.Scan(&weapon.LogID, &weapon.SteamID, &weapon.Class, &weapon.Weapon, &weapon.Kills, &weapon.Damage, &weapon.Accuracy); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan player class weapon")
+		}
+
+		weapons = append(weapons, weapon)
+	}
+
+	return weapons, nil
+}
+
+func (db *pgStore) logsTFMatchChat(ctx context.Context, logID int) ([]domain.LogsTFChat, error) {
+	const query = `
+		SELECT log_id, steam_id, name, message 
+		FROM logstf_chat
+		WHERE log_id = $1
+		ORDER BY idx`
+
+	rows, err := db.pool.Query(ctx, query, logID)
+	if err != nil {
+		return nil, dbErr(err, "Failed to query chat")
+	}
+
+	defer rows.Close()
+
+	var chat []domain.LogsTFChat
+
+	for rows.Next() {
+		var (
+			message domain.LogsTFChat
+			sid     int64
+		)
+
+		if errScan := rows.Scan(&message.LogID, &sid, &message.Name, &message.Message); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan chat")
+		}
+
+		// Console messages are stored with a 0 steam id
+		if sid != 0 {
+			message.SteamID = steamid.New(sid)
+		}
+
+		chat = append(chat, message)
+	}
+
+	return chat, nil
+}
+
+func (db *pgStore) logsTFMatchKillstreaks(ctx context.Context, logID int) ([]domain.LogsTFKillstreak, error) {
+	const query = `
+		SELECT log_id, steam_id, streak, time 
+		FROM logstf_killstreak
+		WHERE log_id = $1
+		ORDER BY time`
+
+	rows, err := db.pool.Query(ctx, query, logID)
+	if err != nil {
+		return nil, dbErr(err, "Failed to query killstreaks")
+	}
+
+	defer rows.Close()
+
+	var killstreaks []domain.LogsTFKillstreak
+
+	for rows.Next() {
+		var streak domain.LogsTFKillstreak
+		if errScan := rows.Scan(&streak.LogID, &streak.SteamID, &streak.Streak, &streak.Time.Duration); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan killstreak")
+		}
+
+		killstreaks = append(killstreaks, streak)
+	}
+
+	return killstreaks, nil
+}
+
+func (db *pgStore) logsTFMatchMedics(ctx context.Context, logID int) ([]domain.LogsTFMedic, error) {
+	const query = `
+		SELECT log_id, steam_id, healing, charges_kritz, charges_quickfix, charges_medigun, charges_vacc, avg_time_build, 
+		       avg_time_use, near_full_death, avg_uber_len, death_after_charge, major_adv_lost, biggest_adv_lost 
+		FROM logstf_medic
+		WHERE log_id = $1`
+
+	rows, err := db.pool.Query(ctx, query, logID)
+	if err != nil {
+		return nil, dbErr(err, "Failed to query medics")
+	}
+
+	defer rows.Close()
+
+	var medics []domain.LogsTFMedic
+
+	for rows.Next() {
+		var medic domain.LogsTFMedic
+		if errScan := rows.Scan(&medic.LogID, &medic.SteamID, &medic.Healing, &medic.ChargesKritz, &medic.ChargesQuickfix, &medic.ChargesMedigun, &medic.ChargesVacc,
+			&medic.AvgTimeBuild.Duration, &medic.AvgTimeUse.Duration, &medic.NearFullDeath, &medic.AvgUberLen.Duration, &medic.DeathAfterCharge, &medic.MajorAdvLost, &medic.BiggestAdvLost.Duration); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan medic")
+		}
+
+		medics = append(medics, medic)
+	}
+
+	return medics, nil
+}
+
+func (db *pgStore) logsTFMatchInsert(ctx context.Context, transaction pgx.Tx, match *domain.LogsTFMatch) error {
+	matchQuery, matchArgs, errQuery := sb.Insert("logstf").
+		SetMap(map[string]interface{}{
+			"log_id":     match.LogID,
+			"source":     match.Source,
+			"checksum":   match.Checksum,
+			"title":      match.Title,
+			"map":        match.Map,
+			"format":     match.Format,
+			"views":      match.Views,
+			"duration":   match.Duration.Duration,
+			"score_red":  match.ScoreRED,
+			"score_blu":  match.ScoreBLU,
+			"created_on": match.CreatedOn,
+		}).ToSql()
+	if errQuery != nil {
+		return dbErr(errQuery, "Failed to build query")
+	}
+
+	if _, err := transaction.Exec(ctx, matchQuery, matchArgs...); err != nil {
+		return dbErr(err, "Failed to insert logstf table")
+	}
+
+	return nil
 }
 
 func (db *pgStore) logsTFMatchPlayersInsert(ctx context.Context, transaction pgx.Tx, players []domain.LogsTFPlayer) error {
@@ -1333,6 +1967,7 @@ func (db *pgStore) logsTFMatchPlayersInsert(ctx context.Context, transaction pgx
 				"hs":            player.Headshots,
 				"caps":          player.Caps,
 				"healing_taken": player.HealingTaken,
+				"airshots":      player.Airshots,
 			}).ToSql()
 		if errQuery != nil {
 			return dbErr(errQuery, "Failed to build query")
@@ -1415,12 +2050,13 @@ func (db *pgStore) logsTFMatchPlayerClassWeaponInsert(ctx context.Context, trans
 		for _, classWeapon := range class.Weapons {
 			query, args, errQuery := sb.Insert("logstf_player_class_weapon").
 				SetMap(map[string]interface{}{
-					"log_id":   player.LogID,
-					"steam_id": player.SteamID,
-					"weapon":   classWeapon.Weapon,
-					"kills":    classWeapon.Kills,
-					"damage":   classWeapon.Damage,
-					"accuracy": classWeapon.Accuracy,
+					"log_id":       player.LogID,
+					"steam_id":     player.SteamID,
+					"player_class": class.Class,
+					"weapon":       classWeapon.Weapon,
+					"kills":        classWeapon.Kills,
+					"damage":       classWeapon.Damage,
+					"accuracy":     classWeapon.Accuracy,
 				}).ToSql()
 			if errQuery != nil {
 				return dbErr(errQuery, "Failed to build query")
@@ -1435,6 +2071,49 @@ func (db *pgStore) logsTFMatchPlayerClassWeaponInsert(ctx context.Context, trans
 	return nil
 }
 
+func (db *pgStore) logsTFMatchChatInsert(ctx context.Context, transaction pgx.Tx, chat []domain.LogsTFChat) error {
+	for idx, message := range chat {
+		query, args, errQuery := sb.Insert("logstf_chat").
+			SetMap(map[string]interface{}{
+				"log_id":   message.LogID,
+				"idx":      idx,
+				"steam_id": message.SteamID.Int64(),
+				"name":     message.Name,
+				"message":  message.Message,
+			}).ToSql()
+		if errQuery != nil {
+			return dbErr(errQuery, "Failed to build query")
+		}
+
+		if _, err := transaction.Exec(ctx, query, args...); err != nil {
+			return dbErr(err, "Failed to insert logstf chat")
+		}
+	}
+
+	return nil
+}
+
+func (db *pgStore) logsTFMatchKillstreaksInsert(ctx context.Context, transaction pgx.Tx, killstreaks []domain.LogsTFKillstreak) error {
+	for _, streak := range killstreaks {
+		query, args, errQuery := sb.Insert("logstf_killstreak").
+			SetMap(map[string]interface{}{
+				"log_id":   streak.LogID,
+				"steam_id": streak.SteamID,
+				"streak":   streak.Streak,
+				"time":     streak.Time.Duration,
+			}).ToSql()
+		if errQuery != nil {
+			return dbErr(errQuery, "Failed to build query")
+		}
+
+		if _, err := transaction.Exec(ctx, query, args...); err != nil {
+			return dbErr(err, "Failed to insert logstf killstreak")
+		}
+	}
+
+	return nil
+}
+
 func (db *pgStore) logsTFMatchRoundsInsert(ctx context.Context, transaction pgx.Tx, rounds []domain.LogsTFRound) error {
 	for _, player := range rounds {
 		query, args, errQuery := sb.Insert("logstf_round").
@@ -1466,7 +2145,7 @@ func (db *pgStore) logsTFMatchRoundsInsert(ctx context.Context, transaction pgx.
 
 func (db *pgStore) logsTFMatchList(ctx context.Context, steamID steamid.SteamID) ([]domain.LogsTFMatchInfo, error) {
 	const query = `
-		SELECT l.log_id, l.title, l.map, l.format, l.views, l.duration, l.score_red, l.score_blu, l.created_on 
+		SELECT l.log_id, l.source, l.title, l.map, l.format, l.views, l.duration, l.score_red, l.score_blu, l.created_on 
 		FROM logstf l
 		LEFT JOIN logstf_player lp on l.log_id = lp.log_id
 		WHERE lp.steam_id = $1`
@@ -1482,7 +2161,7 @@ func (db *pgStore) logsTFMatchList(ctx context.Context, steamID steamid.SteamID)
 
 	for rows.Next() {
 		var match domain.LogsTFMatchInfo
-		if errScan := rows.Scan(&match.LogID, &match.Title, &match.Map, &match.Format, &match.Views,
+		if errScan := rows.Scan(&match.LogID, &match.Source, &match.Title, &match.Map, &match.Format, &match.Views,
 			&match.Duration.Duration, &match.ScoreRED, &match.ScoreBLU, &match.CreatedOn); errScan != nil {
 			return nil, dbErr(errScan, "Failed to query match by id")
 		}
@@ -1493,58 +2172,473 @@ func (db *pgStore) logsTFMatchList(ctx context.Context, steamID steamid.SteamID)
 	return matches, nil
 }
 
-func (db *pgStore) logsTFLogCount(ctx context.Context, steamID steamid.Collection) (map[steamid.SteamID]int, error) {
-	query, args, errQuery := sb.
-		Select("count(l.log_id)", "lp.steam_id").
-		From("logstf l").
-		LeftJoin("logstf_player lp on l.log_id = lp.log_id").
-		Where(sq.Eq{"lp.steam_id": steamID}).
-		GroupBy("lp.steam_id").
-		ToSql()
-	if errQuery != nil {
-		return nil, dbErr(errQuery, "Failed to build query")
-	}
+// logsTFSharedQuery selects the logs that every player in $1 played in, $2 being the number of players. The team
+// of the first player ($3) is used to determine the result of the log.
+const logsTFSharedQuery = `
+	WITH shared AS (
+		SELECT log_id, count(DISTINCT team) = 1 AS same_team, max(team) FILTER (WHERE steam_id = $3) AS team
+		FROM logstf_player
+		WHERE steam_id = ANY($1)
+		GROUP BY log_id
+		HAVING count(DISTINCT steam_id) = $2
+	)`
+
+// logsTFShared returns the logs that all the players played in, split into logs where they were on the same team
+// and logs where they were on opposing teams.
+func (db *pgStore) logsTFShared(ctx context.Context, steamIDs steamid.Collection) (*domain.LogsTFShared, error) {
+	sids := make([]int64, len(steamIDs))
+	for idx, sid := range steamIDs {
+		sids[idx] = sid.Int64()
+	}
+
+	shared := domain.LogsTFShared{SteamIDs: steamIDs} //nolint:exhaustruct
+	record := func(sameTeam bool) *domain.LogsTFSharedRecord {
+		if sameTeam {
+			return &shared.SameTeam
+		}
 
-	counts := map[steamid.SteamID]int{}
+		return &shared.OpposingTeams
+	}
 
-	rows, errRows := db.pool.Query(ctx, query, args...)
+	rows, errRows := db.pool.Query(ctx, logsTFSharedQuery+`
+		SELECT l.log_id, l.title, l.map, l.format, l.views, l.duration, l.score_red, l.score_blu, l.created_on,
+		       s.same_team, s.team
+		FROM shared s
+		JOIN logstf l ON l.log_id = s.log_id
+		ORDER BY l.created_on DESC`, sids, len(sids), sids[0])
 	if errRows != nil {
-		return nil, dbErr(errRows, "Failed to query logs")
+		return nil, dbErr(errRows, "Failed to query shared logs")
 	}
 
 	defer rows.Close()
 
 	for rows.Next() {
 		var (
-			count int
-			sid   int64
+			match    domain.LogsTFMatchInfo
+			sameTeam bool
+			team     domain.Team
 		)
-		if err := rows.Scan(&count, &sid); err != nil {
-			return nil, dbErr(err, "Failed to get results")
+
+		if errScan := rows.Scan(&match.LogID, &match.Title, &match.Map, &match.Format, &match.Views,
+			&match.Duration.Duration, &match.ScoreRED, &match.ScoreBLU, &match.CreatedOn, &sameTeam, &team); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan shared log")
 		}
 
-		counts[steamid.New(sid)] = count
+		result := record(sameTeam)
+		result.Games++
+		result.Logs = append(result.Logs, match)
+
+		switch logsTFResult(match, team) {
+		case resultWin:
+			result.Wins++
+		case resultLoss:
+			result.Losses++
+		case resultTie:
+			result.Ties++
+		}
 	}
 
-	return counts, nil
-}
-
-func (db *pgStore) logsTFNewestID(ctx context.Context) (int, error) {
-	var id int
-	if err := db.pool.QueryRow(ctx, "SELECT coalesce(max(log_id), 1) FROM logstf").Scan(&id); err != nil {
-		return 0, dbErr(err, "failed to get max_id")
+	statRows, errStatRows := db.pool.Query(ctx, logsTFSharedQuery+`
+		SELECT s.same_team, p.steam_id, sum(p.kills), sum(p.assists), sum(p.deaths), sum(p.damage)::bigint,
+		       coalesce(round(avg(p.dpm)::numeric, 2), 0)
+		FROM shared s
+		JOIN logstf_player p ON p.log_id = s.log_id
+		WHERE p.steam_id = ANY($1)
+		GROUP BY s.same_team, p.steam_id
+		ORDER BY p.steam_id`, sids, len(sids), sids[0])
+	if errStatRows != nil {
+		return nil, dbErr(errStatRows, "Failed to query shared log stats")
 	}
 
-	return id, nil
-}
+	defer statRows.Close()
 
-func (db *pgStore) servemeRecords(ctx context.Context) ([]domain.ServeMeRecord, error) {
-	const query = `SELECT steam_id, name, reason, created_on, updated_on FROM serveme`
+	for statRows.Next() {
+		var (
+			stats    domain.LogsTFSharedPlayerStats
+			sameTeam bool
+		)
 
-	rows, errRows := db.pool.Query(ctx, query)
-	if errRows != nil {
-		return nil, dbErr(errRows, "Failed to query serveme records")
-	}
+		if errScan := statRows.Scan(&sameTeam, &stats.SteamID, &stats.KillsSum, &stats.AssistsSum, &stats.DeathsSum,
+			&stats.DamageSum, &stats.DPMAvg.Value); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan shared log stats")
+		}
+
+		stats.KD.Value = float32(stats.KillsSum) / float32(max(stats.DeathsSum, 1))
+
+		result := record(sameTeam)
+		result.Players = append(result.Players, stats)
+	}
+
+	return &shared, nil
+}
+
+// logsTFTeammates returns the players that most often played on the same team as the player.
+func (db *pgStore) logsTFTeammates(ctx context.Context, steamID steamid.SteamID, limit int) ([]domain.LogsTFTeammate, error) {
+	const query = `
+		SELECT o.steam_id, coalesce(pl.persona_name, max(o.name)),
+		       count(*) FILTER (WHERE o.team = p.team),
+		       count(*) FILTER (WHERE o.team = p.team AND CASE p.team WHEN 3 THEN l.score_red > l.score_blu ELSE l.score_blu > l.score_red END),
+		       count(*) FILTER (WHERE o.team = p.team AND CASE p.team WHEN 3 THEN l.score_red < l.score_blu ELSE l.score_blu < l.score_red END),
+		       count(*) FILTER (WHERE o.team != p.team),
+		       max(l.created_on)
+		FROM logstf_player p
+		JOIN logstf_player o ON o.log_id = p.log_id AND o.steam_id != p.steam_id
+		JOIN logstf l ON l.log_id = p.log_id
+		LEFT JOIN player pl ON pl.steam_id = o.steam_id
+		WHERE p.steam_id = $1
+		GROUP BY o.steam_id, pl.persona_name
+		HAVING count(*) FILTER (WHERE o.team = p.team) > 0
+		ORDER BY 3 DESC, 7 DESC
+		LIMIT $2`
+
+	rows, errRows := db.pool.Query(ctx, query, steamID.Int64(), limit)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query teammates")
+	}
+
+	defer rows.Close()
+
+	var teammates []domain.LogsTFTeammate
+
+	for rows.Next() {
+		var teammate domain.LogsTFTeammate
+		if errScan := rows.Scan(&teammate.SteamID, &teammate.Name, &teammate.Games, &teammate.Wins, &teammate.Losses,
+			&teammate.Against, &teammate.LastPlayed); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan teammate")
+		}
+
+		teammates = append(teammates, teammate)
+	}
+
+	return teammates, nil
+}
+
+// logsTFLogCount counts the logs.tf matches played by each player. Imported server logs are not included.
+func (db *pgStore) logsTFLogCount(ctx context.Context, steamID steamid.Collection) (map[steamid.SteamID]int, error) {
+	query, args, errQuery := sb.
+		Select("count(l.log_id)", "lp.steam_id").
+		From("logstf l").
+		LeftJoin("logstf_player lp on l.log_id = lp.log_id").
+		Where(sq.Eq{"lp.steam_id": steamID, "l.source": domain.SourceLogsTF}).
+		GroupBy("lp.steam_id").
+		ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build query")
+	}
+
+	counts := map[steamid.SteamID]int{}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query logs")
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			count int
+			sid   int64
+		)
+		if err := rows.Scan(&count, &sid); err != nil {
+			return nil, dbErr(err, "Failed to get results")
+		}
+
+		counts[steamid.New(sid)] = count
+	}
+
+	return counts, nil
+}
+
+func (db *pgStore) logsTFNewestID(ctx context.Context) (int, error) {
+	var id int
+	if err := db.pool.QueryRow(ctx, "SELECT coalesce(max(log_id), 1) FROM logstf WHERE source = $1", domain.SourceLogsTF).
+		Scan(&id); err != nil {
+		return 0, dbErr(err, "failed to get max_id")
+	}
+
+	return id, nil
+}
+
+// logsTFLocalID allocates a new id for an imported match. These count down from -1.
+func (db *pgStore) logsTFLocalID(ctx context.Context) (int, error) {
+	var logID int
+	if err := db.pool.QueryRow(ctx, "SELECT nextval('logstf_local_id_seq')").Scan(&logID); err != nil {
+		return 0, dbErr(err, "Failed to allocate local log id")
+	}
+
+	return logID, nil
+}
+
+// logsTFChecksumLogID returns the log id of an imported match with the checksum.
+func (db *pgStore) logsTFChecksumLogID(ctx context.Context, checksum string) (int, error) {
+	var logID int
+	if err := db.pool.QueryRow(ctx, "SELECT log_id FROM logstf WHERE checksum = $1", checksum).Scan(&logID); err != nil {
+		return 0, dbErr(err, "Failed to query log checksum")
+	}
+
+	return logID, nil
+}
+
+const logsTFUploadKeyColumns = "upload_key_id, name, enabled, uploads, last_used_on, created_on"
+
+func (db *pgStore) logsTFUploadKeyCreate(ctx context.Context, name string, keyHash string) (logsTFUploadKey, error) {
+	uploadKey := logsTFUploadKey{Name: name, Enabled: true, CreatedOn: time.Now()} //nolint:exhaustruct
+
+	if errQuery := db.pool.QueryRow(ctx, `
+		INSERT INTO logstf_upload_key (name, key_hash, enabled, created_on) 
+		VALUES ($1, $2, $3, $4) 
+		RETURNING upload_key_id`, name, keyHash, uploadKey.Enabled, uploadKey.CreatedOn).
+		Scan(&uploadKey.UploadKeyID); errQuery != nil {
+		return uploadKey, dbErr(errQuery, "Failed to create upload key")
+	}
+
+	return uploadKey, nil
+}
+
+func (db *pgStore) logsTFUploadKeys(ctx context.Context) ([]logsTFUploadKey, error) {
+	rows, errRows := db.pool.Query(ctx, "SELECT "+logsTFUploadKeyColumns+" FROM logstf_upload_key ORDER BY name")
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query upload keys")
+	}
+
+	defer rows.Close()
+
+	var keys []logsTFUploadKey
+
+	for rows.Next() {
+		var uploadKey logsTFUploadKey
+		if errScan := rows.Scan(&uploadKey.UploadKeyID, &uploadKey.Name, &uploadKey.Enabled, &uploadKey.Uploads,
+			&uploadKey.LastUsedOn, &uploadKey.CreatedOn); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan upload key")
+		}
+
+		keys = append(keys, uploadKey)
+	}
+
+	return keys, nil
+}
+
+func (db *pgStore) logsTFUploadKeyByHash(ctx context.Context, keyHash string) (logsTFUploadKey, error) {
+	var uploadKey logsTFUploadKey
+	if errQuery := db.pool.QueryRow(ctx, "SELECT "+logsTFUploadKeyColumns+" FROM logstf_upload_key WHERE key_hash = $1",
+		keyHash).Scan(&uploadKey.UploadKeyID, &uploadKey.Name, &uploadKey.Enabled, &uploadKey.Uploads,
+		&uploadKey.LastUsedOn, &uploadKey.CreatedOn); errQuery != nil {
+		return uploadKey, dbErr(errQuery, "Failed to query upload key")
+	}
+
+	return uploadKey, nil
+}
+
+func (db *pgStore) logsTFUploadKeySetEnabled(ctx context.Context, name string, enabled bool) error {
+	tag, errExec := db.pool.Exec(ctx, "UPDATE logstf_upload_key SET enabled = $2 WHERE name = $1", name, enabled)
+	if errExec != nil {
+		return dbErr(errExec, "Failed to update upload key")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errDatabaseNoResults
+	}
+
+	return nil
+}
+
+// logsTFUploadKeyUsed records a successful upload with the key.
+func (db *pgStore) logsTFUploadKeyUsed(ctx context.Context, uploadKeyID int64) error {
+	if _, errExec := db.pool.Exec(ctx, `
+		UPDATE logstf_upload_key SET uploads = uploads + 1, last_used_on = $2 WHERE upload_key_id = $1`,
+		uploadKeyID, time.Now()); errExec != nil {
+		return dbErr(errExec, "Failed to update upload key")
+	}
+
+	return nil
+}
+
+// logsTFExistingIDs returns the log ids in the range that are already stored.
+func (db *pgStore) logsTFExistingIDs(ctx context.Context, startID int, endID int) ([]int, error) {
+	rows, errRows := db.pool.Query(ctx, `SELECT log_id FROM logstf WHERE log_id BETWEEN $1 AND $2`, startID, endID)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query existing log ids")
+	}
+
+	defer rows.Close()
+
+	var logIDs []int
+
+	for rows.Next() {
+		var logID int
+		if errScan := rows.Scan(&logID); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan log id")
+		}
+
+		logIDs = append(logIDs, logID)
+	}
+
+	return logIDs, nil
+}
+
+func (db *pgStore) logsTFGaps(ctx context.Context) ([]logsTFGap, error) {
+	query, args, errSQL := sb.
+		Select("gap_id", "start_id", "end_id", "status", "reason", "attempts", "next_attempt", "created_on", "updated_on").
+		From("logstf_gap").
+		OrderBy("start_id").
+		ToSql()
+	if errSQL != nil {
+		return nil, dbErr(errSQL, "Failed to generate query")
+	}
+
+	return db.logsTFGapsQuery(ctx, query, args...)
+}
+
+// logsTFGapsDue returns the pending gaps which are ready to be retried, oldest first.
+func (db *pgStore) logsTFGapsDue(ctx context.Context, now time.Time, limit uint64) ([]logsTFGap, error) {
+	query, args, errSQL := sb.
+		Select("gap_id", "start_id", "end_id", "status", "reason", "attempts", "next_attempt", "created_on", "updated_on").
+		From("logstf_gap").
+		Where(sq.And{sq.Eq{"status": gapPending}, sq.LtOrEq{"next_attempt": now}}).
+		OrderBy("next_attempt").
+		Limit(limit).
+		ToSql()
+	if errSQL != nil {
+		return nil, dbErr(errSQL, "Failed to generate query")
+	}
+
+	return db.logsTFGapsQuery(ctx, query, args...)
+}
+
+func (db *pgStore) logsTFGapsQuery(ctx context.Context, query string, args ...any) ([]logsTFGap, error) {
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query logs.tf gaps")
+	}
+
+	defer rows.Close()
+
+	var gaps []logsTFGap
+
+	for rows.Next() {
+		var gap logsTFGap
+		if errScan := rows.Scan(&gap.GapID, &gap.StartID, &gap.EndID, &gap.Status, &gap.Reason, &gap.Attempts,
+			&gap.NextAttempt, &gap.CreatedOn, &gap.UpdatedOn); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan logs.tf gap")
+		}
+
+		gaps = append(gaps, gap)
+	}
+
+	return gaps, nil
+}
+
+// logsTFGapsReplace deletes the old gaps and inserts the new ones in a single transaction.
+func (db *pgStore) logsTFGapsReplace(ctx context.Context, oldGaps []logsTFGap, newGaps []logsTFGap) error {
+	if len(oldGaps) == 0 && len(newGaps) == 0 {
+		return nil
+	}
+
+	transaction, errBegin := db.pool.Begin(ctx)
+	if errBegin != nil {
+		return dbErr(errBegin, "Failed to start tx")
+	}
+
+	defer func() {
+		if err := transaction.Rollback(ctx); err != nil {
+			if !errors.Is(err, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback logs.tf gap tx", ErrAttr(err))
+			}
+		}
+	}()
+
+	for _, gap := range oldGaps {
+		if _, err := transaction.Exec(ctx, `DELETE FROM logstf_gap WHERE gap_id = $1`, gap.GapID); err != nil {
+			return dbErr(err, "Failed to delete logs.tf gap")
+		}
+	}
+
+	if err := db.logsTFGapsInsert(ctx, transaction, newGaps); err != nil {
+		return err
+	}
+
+	if err := transaction.Commit(ctx); err != nil {
+		return dbErr(err, "Failed to commit")
+	}
+
+	return nil
+}
+
+// logsTFGapsBackfill replaces any gaps contained within the range with the new backfill gaps.
+func (db *pgStore) logsTFGapsBackfill(ctx context.Context, startID int, endID int, gaps []logsTFGap) error {
+	transaction, errBegin := db.pool.Begin(ctx)
+	if errBegin != nil {
+		return dbErr(errBegin, "Failed to start tx")
+	}
+
+	defer func() {
+		if err := transaction.Rollback(ctx); err != nil {
+			if !errors.Is(err, pgx.ErrTxClosed) {
+				slog.Error("failed to rollback logs.tf backfill tx", ErrAttr(err))
+			}
+		}
+	}()
+
+	if _, err := transaction.Exec(ctx, `DELETE FROM logstf_gap WHERE start_id >= $1 AND end_id <= $2`,
+		startID, endID); err != nil {
+		return dbErr(err, "Failed to delete logs.tf gaps")
+	}
+
+	if err := db.logsTFGapsInsert(ctx, transaction, gaps); err != nil {
+		return err
+	}
+
+	if err := transaction.Commit(ctx); err != nil {
+		return dbErr(err, "Failed to commit")
+	}
+
+	return nil
+}
+
+func (db *pgStore) logsTFGapsInsert(ctx context.Context, transaction pgx.Tx, gaps []logsTFGap) error {
+	for _, gap := range gaps {
+		query, args, errQuery := sb.Insert("logstf_gap").
+			SetMap(map[string]interface{}{
+				"start_id":     gap.StartID,
+				"end_id":       gap.EndID,
+				"status":       gap.Status,
+				"reason":       gap.Reason,
+				"attempts":     gap.Attempts,
+				"next_attempt": gap.NextAttempt,
+				"created_on":   gap.CreatedOn,
+				"updated_on":   gap.UpdatedOn,
+			}).ToSql()
+		if errQuery != nil {
+			return dbErr(errQuery, "Failed to build query")
+		}
+
+		if _, err := transaction.Exec(ctx, query, args...); err != nil {
+			return dbErr(err, "Failed to insert logs.tf gap")
+		}
+	}
+
+	return nil
+}
+
+func (db *pgStore) servemeRecords(ctx context.Context, categories []domain.BanCategory) ([]domain.ServeMeRecord, error) {
+	builder := sb.
+		Select("steam_id", "name", "reason", "reason_category", "created_on", "updated_on").
+		From("serveme")
+
+	if len(categories) > 0 {
+		builder = builder.Where(sq.Eq{"reason_category": categories})
+	}
+
+	query, args, errQuery := builder.ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to create serveme query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query serveme records")
+	}
 
 	defer rows.Close()
 
@@ -1555,7 +2649,8 @@ func (db *pgStore) servemeRecords(ctx context.Context) ([]domain.ServeMeRecord,
 			sid    int64
 			record domain.ServeMeRecord
 		)
-		if err := rows.Scan(&sid, &record.Name, &record.Reason, &record.CreatedOn, &record.UpdatedOn); err != nil {
+		if err := rows.Scan(&sid, &record.Name, &record.Reason, &record.ReasonCategory, &record.CreatedOn,
+			&record.UpdatedOn); err != nil {
 			return nil, dbErr(err, "Failed to get results")
 		}
 
@@ -1584,9 +2679,12 @@ func (db *pgStore) servemeUpdate(ctx context.Context, entries []domain.ServeMeRe
 	if _, err := db.pool.Exec(ctx, "DELETE FROM serveme"); err != nil {
 		return dbErr(err, "Failed to delete existing entries")
 	}
-	const query = "INSERT INTO serveme (steam_id, name, reason, created_on, updated_on) VALUES ($1, $2, $3, $4, $5)"
+	const query = `
+		INSERT INTO serveme (steam_id, name, reason, reason_category, created_on, updated_on) 
+		VALUES ($1, $2, $3, $4, $5, $6)`
 	for _, entry := range entries {
-		if _, err := db.pool.Exec(ctx, query, entry.SteamID.Int64(), entry.Name, entry.Reason, entry.CreatedOn, entry.UpdatedOn); err != nil {
+		if _, err := db.pool.Exec(ctx, query, entry.SteamID.Int64(), entry.Name, entry.Reason, entry.ReasonCategory,
+			entry.CreatedOn, entry.UpdatedOn); err != nil {
 			return dbErr(err, "Failed to insert serveme record")
 		}
 	}
@@ -1600,7 +2698,7 @@ func (db *pgStore) servemeUpdate(ctx context.Context, entries []domain.ServeMeRe
 
 func (db *pgStore) servemeRecordsSearch(ctx context.Context, collection steamid.Collection) ([]*domain.ServeMeRecord, error) {
 	query, args, errQuery := sb.
-		Select("steam_id", "name", "reason", "created_on", "updated_on").
+		Select("steam_id", "name", "reason", "reason_category", "created_on", "updated_on").
 		From("serveme").
 		Where(sq.Eq{"steam_id": collection}).
 		ToSql()
@@ -1623,7 +2721,8 @@ func (db *pgStore) servemeRecordsSearch(ctx context.Context, collection steamid.
 			record domain.ServeMeRecord
 		)
 
-		if errScan := rows.Scan(&sid, &record.Name, &record.Reason, &record.CreatedOn, &record.UpdatedOn); errScan != nil {
+		if errScan := rows.Scan(&sid, &record.Name, &record.Reason, &record.ReasonCategory, &record.CreatedOn,
+			&record.UpdatedOn); errScan != nil {
 			return nil, dbErr(errScan, "Failed to scan serveme record")
 		}
 
@@ -1678,7 +2777,7 @@ func (db *pgStore) rglTeamGet(ctx context.Context, teamID int) (domain.RGLTeam,
 	var team domain.RGLTeam
 	query, args, errQuery := sb.
 		Select("team_id", "season_id", "division_id", "division_name", "team_leader", "tag", "team_name", "final_rank",
-			"created_at", "updated_at").
+			"created_at", "updated_at", "scraped_on").
 		From("rgl_team").
 		Where(sq.Eq{"team_id": teamID}).
 		ToSql()
@@ -1688,34 +2787,27 @@ func (db *pgStore) rglTeamGet(ctx context.Context, teamID int) (domain.RGLTeam,
 
 	if err := db.pool.QueryRow(ctx, query, args...).
 		Scan(&team.TeamID, &team.SeasonID, &team.DivisionID, &team.DivisionName, &team.TeamLeader, &team.Tag, &team.TeamName, &team.FinalRank,
-			&team.CreatedAt, &team.UpdatedAt); err != nil {
+			&team.CreatedAt, &team.UpdatedAt, &team.ScrapedOn); err != nil {
 		return team, dbErr(err, "Failed to query rgl team")
 	}
 
 	return team, nil
 }
 
-func (db *pgStore) rglTeamInsert(ctx context.Context, team domain.RGLTeam) error {
-	query, args, errQuery := sb.
-		Insert("rgl_team").
-		SetMap(map[string]interface{}{
-			"team_id":       team.TeamID,
-			"season_id":     team.SeasonID,
-			"division_id":   team.DivisionID,
-			"division_name": team.DivisionName,
-			"team_leader":   team.TeamLeader,
-			"tag":           team.Tag,
-			"team_name":     team.TeamName,
-			"final_rank":    team.FinalRank,
-			"created_at":    team.CreatedAt,
-			"updated_at":    team.UpdatedAt,
-		}).ToSql()
-	if errQuery != nil {
-		return dbErr(errQuery, "Failed to insert rgl team")
-	}
+// rglTeamSave inserts or updates a team fetched from the rgl api.
+func (db *pgStore) rglTeamSave(ctx context.Context, team domain.RGLTeam) error {
+	const query = `
+		INSERT INTO rgl_team (team_id, season_id, division_id, division_name, team_leader, tag, team_name, final_rank, 
+		                      created_at, updated_at, scraped_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (team_id) DO UPDATE 
+		SET season_id = $2, division_id = $3, division_name = $4, team_leader = $5, tag = $6, team_name = $7, 
+		    final_rank = $8, updated_at = $10, scraped_on = $11`
 
-	if _, err := db.pool.Exec(ctx, query, args...); err != nil {
-		return dbErr(err, "Failed to exec rgl team insert")
+	if _, err := db.pool.Exec(ctx, query, team.TeamID, team.SeasonID, team.DivisionID, team.DivisionName,
+		team.TeamLeader, team.Tag, team.TeamName, team.FinalRank, team.CreatedAt, team.UpdatedAt,
+		team.ScrapedOn); err != nil {
+		return dbErr(err, "Failed to save rgl team")
 	}
 
 	return nil
@@ -1769,7 +2861,7 @@ func (db *pgStore) rglTeamMemberInsert(ctx context.Context, member domain.RGLTea
 		INSERT INTO rgl_team_member (team_id, steam_id, name, is_team_leader, joined_at, left_at) 
 		VALUES ($1, $2, $3 ,$4, $5, $6)
 		ON CONFLICT (team_id, steam_id)
-		DO UPDATE SET left_at = $6`
+		DO UPDATE SET name = $3, is_team_leader = $4, left_at = $6`
 
 	if _, err := db.pool.Exec(ctx, query, member.TeamID, member.SteamID.Int64(), member.Name, member.IsTeamLeader, member.JoinedAt, member.LeftAt); err != nil {
 		return dbErr(err, "Failed to exec rgl team member insert")
@@ -1778,10 +2870,40 @@ func (db *pgStore) rglTeamMemberInsert(ctx context.Context, member domain.RGLTea
 	return nil
 }
 
+// rglTeamMembersLeft sets left_at for the stored members of the team that are no longer on its current roster.
+// Members that already have a left_at keep it.
+func (db *pgStore) rglTeamMembersLeft(ctx context.Context, teamID int, current steamid.Collection,
+	leftAt time.Time,
+) error {
+	currentIDs := make([]int64, len(current))
+	for idx, sid := range current {
+		currentIDs[idx] = sid.Int64()
+	}
+
+	query, args, errQuery := sb.
+		Update("rgl_team_member").
+		Set("left_at", leftAt).
+		Where(sq.And{
+			sq.Eq{"team_id": teamID},
+			sq.NotEq{"steam_id": currentIDs},
+			sq.Eq{"left_at": nil},
+		}).
+		ToSql()
+	if errQuery != nil {
+		return dbErr(errQuery, "Failed to build rgl team members left query")
+	}
+
+	if _, err := db.pool.Exec(ctx, query, args...); err != nil {
+		return dbErr(err, "Failed to update rgl team members left")
+	}
+
+	return nil
+}
+
 func (db *pgStore) rglBansReplace(ctx context.Context, bans []domain.RGLBan) error {
 	const query = `
-		INSERT INTO rgl_ban (steam_id, alias, expires_at, created_at, reason) 
-		VALUES ($1, $2, $3 ,$4, $5)`
+		INSERT INTO rgl_ban (steam_id, alias, expires_at, created_at, reason, reason_category) 
+		VALUES ($1, $2, $3 ,$4, $5, $6)`
 
 	if _, err := db.pool.Exec(ctx, `DELETE FROM rgl_ban`); err != nil {
 		return dbErr(err, "failed to cleanup rgl_ban table")
@@ -1795,7 +2917,8 @@ func (db *pgStore) rglBansReplace(ctx context.Context, bans []domain.RGLBan) err
 			return err
 		}
 
-		batch.Queue(query, ban.SteamID.Int64(), ban.Alias, ban.ExpiresAt, ban.CreatedAt.Truncate(time.Second), ban.Reason)
+		batch.Queue(query, ban.SteamID.Int64(), ban.Alias, ban.ExpiresAt, ban.CreatedAt.Truncate(time.Second), ban.Reason,
+			ban.ReasonCategory)
 	}
 
 	if err := db.pool.SendBatch(context.Background(), batch).Close(); err != nil {
@@ -1805,8 +2928,21 @@ func (db *pgStore) rglBansReplace(ctx context.Context, bans []domain.RGLBan) err
 	return nil
 }
 
-func (db *pgStore) rglBansGetAll(ctx context.Context) ([]domain.RGLBan, error) {
-	rows, errRows := db.pool.Query(ctx, `SELECT steam_id, alias, expires_at, created_at, reason FROM rgl_ban`)
+func (db *pgStore) rglBansGetAll(ctx context.Context, categories []domain.BanCategory) ([]domain.RGLBan, error) {
+	builder := sb.
+		Select("steam_id", "alias", "expires_at", "created_at", "reason", "reason_category").
+		From("rgl_ban")
+
+	if len(categories) > 0 {
+		builder = builder.Where(sq.Eq{"reason_category": categories})
+	}
+
+	query, args, errQuery := builder.ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build rgl ban query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
 	if errRows != nil && !errors.Is(errRows, errDatabaseNoResults) {
 		return nil, dbErr(errRows, "Failed to load bans")
 	}
@@ -1816,7 +2952,8 @@ func (db *pgStore) rglBansGetAll(ctx context.Context) ([]domain.RGLBan, error) {
 	var bans []domain.RGLBan
 	for rows.Next() {
 		var ban domain.RGLBan
-		if err := rows.Scan(&ban.SteamID, &ban.Alias, &ban.ExpiresAt, &ban.CreatedAt, &ban.Reason); err != nil {
+		if err := rows.Scan(&ban.SteamID, &ban.Alias, &ban.ExpiresAt, &ban.CreatedAt, &ban.Reason,
+			&ban.ReasonCategory); err != nil {
 			return nil, dbErr(err, "Failed to scan rgl bans")
 		}
 
@@ -1874,7 +3011,164 @@ func (db *pgStore) rglMatchInsert(ctx context.Context, match domain.RGLMatch) er
 	return nil
 }
 
-func (db *pgStore) rglPlayerTeamHistory(ctx context.Context, steamIDs steamid.Collection) ([]domain.RGLPlayerTeamHistory, error) {
+func (db *pgStore) rglSeasons(ctx context.Context) ([]domain.RGLSeason, error) {
+	query, args, errQuery := sb.
+		Select("season_id", "maps", "season_name", "format_name", "region_name", "participating_teams", "matches",
+			"created_on").
+		From("rgl_season").
+		OrderBy("season_id DESC").
+		ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build rgl seasons query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query rgl seasons")
+	}
+
+	defer rows.Close()
+
+	seasons := make([]domain.RGLSeason, 0)
+
+	for rows.Next() {
+		var season domain.RGLSeason
+		if err := rows.Scan(&season.SeasonID, &season.Maps, &season.Name, &season.FormatName, &season.RegionName,
+			&season.ParticipatingTeams, &season.Matches, &season.CreatedOn); err != nil {
+			return nil, dbErr(err, "Failed to scan rgl season")
+		}
+
+		seasons = append(seasons, season)
+	}
+
+	return seasons, nil
+}
+
+// rglSeasonStandings returns the teams of a season along with their match record. Teams are ordered by their final
+// rank, with unranked teams last, then by wins.
+func (db *pgStore) rglSeasonStandings(ctx context.Context, seasonID int) ([]domain.RGLTeamStanding, error) {
+	const query = `
+		SELECT t.team_id, t.season_id, t.division_id, t.division_name, t.team_leader, t.tag, t.team_name, 
+		       t.final_rank, t.created_at, t.updated_at,
+		       count(m.match_id) FILTER (WHERE m.winner = t.team_id) AS wins,
+		       count(m.match_id) FILTER (WHERE m.winner != t.team_id) AS losses,
+		       coalesce(sum(CASE WHEN m.team_id_a = t.team_id THEN m.points_a ELSE m.points_b END), 0) AS points
+		FROM rgl_team t
+		LEFT JOIN rgl_match m ON m.season_id = t.season_id AND t.team_id IN (m.team_id_a, m.team_id_b)
+		WHERE t.season_id = $1
+		GROUP BY t.team_id
+		ORDER BY t.final_rank = 0, t.final_rank, wins DESC`
+
+	rows, errRows := db.pool.Query(ctx, query, seasonID)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query rgl season standings")
+	}
+
+	defer rows.Close()
+
+	standings := make([]domain.RGLTeamStanding, 0)
+
+	for rows.Next() {
+		var standing domain.RGLTeamStanding
+		if err := rows.Scan(&standing.TeamID, &standing.SeasonID, &standing.DivisionID, &standing.DivisionName,
+			&standing.TeamLeader, &standing.Tag, &standing.TeamName, &standing.FinalRank, &standing.CreatedAt,
+			&standing.UpdatedAt, &standing.Wins, &standing.Losses, &standing.Points); err != nil {
+			return nil, dbErr(err, "Failed to scan rgl season standing")
+		}
+
+		standings = append(standings, standing)
+	}
+
+	return standings, nil
+}
+
+func (db *pgStore) rglTeamMembers(ctx context.Context, teamID int) ([]domain.RGLTeamMember, error) {
+	query, args, errQuery := sb.
+		Select("team_id", "name", "is_team_leader", "steam_id", "joined_at", "left_at").
+		From("rgl_team_member").
+		Where(sq.Eq{"team_id": teamID}).
+		OrderBy("joined_at").
+		ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build rgl team members query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query rgl team members")
+	}
+
+	defer rows.Close()
+
+	members := make([]domain.RGLTeamMember, 0)
+
+	for rows.Next() {
+		var member domain.RGLTeamMember
+		if err := rows.Scan(&member.TeamID, &member.Name, &member.IsTeamLeader, &member.SteamID, &member.JoinedAt,
+			&member.LeftAt); err != nil {
+			return nil, dbErr(err, "Failed to scan rgl team member")
+		}
+
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// rglMatches returns the matches selected by the builder, which must select from rgl_match aliased as x.
+func (db *pgStore) rglMatches(ctx context.Context, builder sq.SelectBuilder) ([]domain.RGLMatch, error) {
+	query, args, errQuery := builder.
+		Columns("x.match_id", "x.season_name", "x.division_name", "x.division_id", "x.season_id", "x.region_id",
+			"x.match_date", "x.match_name", "x.is_forfeit", "x.winner", "x.team_id_a", "x.points_a", "x.team_id_b",
+			"x.points_b").
+		OrderBy("x.match_date DESC").
+		ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build rgl matches query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query rgl matches")
+	}
+
+	defer rows.Close()
+
+	matches := make([]domain.RGLMatch, 0)
+
+	for rows.Next() {
+		var match domain.RGLMatch
+		if err := rows.Scan(&match.MatchID, &match.SeasonName, &match.DivisionName, &match.DivisionID,
+			&match.SeasonID, &match.RegionID, &match.MatchDate, &match.MatchName, &match.IsForfeit, &match.Winner,
+			&match.TeamIDA, &match.PointsA, &match.TeamIDB, &match.PointsB); err != nil {
+			return nil, dbErr(err, "Failed to scan rgl match")
+		}
+
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+func (db *pgStore) rglTeamMatches(ctx context.Context, teamID int) ([]domain.RGLMatch, error) {
+	return db.rglMatches(ctx, sb.Select().
+		From("rgl_match x").
+		Where(sq.Or{sq.Eq{"x.team_id_a": teamID}, sq.Eq{"x.team_id_b": teamID}}))
+}
+
+// rglPlayerMatches returns the matches played by each team the player was on, while they were on the roster.
+func (db *pgStore) rglPlayerMatches(ctx context.Context, steamID steamid.SteamID) ([]domain.RGLMatch, error) {
+	return db.rglMatches(ctx, sb.Select().
+		From("rgl_match x").
+		Join("rgl_team_member m ON m.team_id IN (x.team_id_a, x.team_id_b)").
+		Where(sq.And{
+			sq.Eq{"m.steam_id": steamID.Int64()},
+			sq.Expr("x.match_date >= m.joined_at"),
+			sq.Or{sq.Eq{"m.left_at": nil}, sq.Expr("x.match_date <= m.left_at")},
+		}))
+}
+
+func (db *pgStore) rglPlayerTeamHistory(ctx context.Context, steamIDs steamid.Collection) ([]domain.RGLPlayerTeamHistory, error) {
 	query, args, errQuery := sb.
 		Select("t.division_name", "t.team_leader", "t.tag", "t.team_name", "t.final_rank",
 			"rtm.name", "rtm.is_team_leader", "rtm.joined_at", "rtm.left_at", "rtm.steam_id").
@@ -1910,8 +3204,8 @@ func (db *pgStore) rglPlayerTeamHistory(ctx context.Context, steamIDs steamid.Co
 
 func (db *pgStore) etf2lBansUpdate(ctx context.Context, bans []domain.ETF2LBan) error {
 	const query = `
-		INSERT INTO etf2l_ban (steam_id, alias, expires_at, created_at, reason) 
-		VALUES ($1, $2, $3 ,$4, $5)`
+		INSERT INTO etf2l_ban (steam_id, alias, expires_at, created_at, reason, reason_category) 
+		VALUES ($1, $2, $3 ,$4, $5, $6)`
 
 	if _, err := db.pool.Exec(ctx, `DELETE FROM etf2l_ban`); err != nil {
 		return dbErr(err, "Failed to delete previous bans")
@@ -1925,7 +3219,7 @@ func (db *pgStore) etf2lBansUpdate(ctx context.Context, bans []domain.ETF2LBan)
 			return err
 		}
 
-		batch.Queue(query, ban.SteamID.Int64(), ban.Alias, ban.ExpiresAt, ban.CreatedAt, ban.Reason)
+		batch.Queue(query, ban.SteamID.Int64(), ban.Alias, ban.ExpiresAt, ban.CreatedAt, ban.Reason, ban.ReasonCategory)
 	}
 
 	if err := db.pool.SendBatch(context.Background(), batch).Close(); err != nil {
@@ -1935,11 +3229,18 @@ func (db *pgStore) etf2lBansUpdate(ctx context.Context, bans []domain.ETF2LBan)
 	return nil
 }
 
-func (db *pgStore) rglBansQuery(ctx context.Context, steamIDs steamid.Collection) ([]domain.RGLBan, error) {
-	query, args, errQuery := sb.Select("steam_id", "alias", "expires_at", "created_at", "reason").
+func (db *pgStore) rglBansQuery(ctx context.Context, steamIDs steamid.Collection,
+	categories []domain.BanCategory,
+) ([]domain.RGLBan, error) {
+	builder := sb.Select("steam_id", "alias", "expires_at", "created_at", "reason", "reason_category").
 		From("rgl_ban").
-		Where(sq.Eq{"steam_id": steamIDs.ToInt64Slice()}).
-		ToSql()
+		Where(sq.Eq{"steam_id": steamIDs.ToInt64Slice()})
+
+	if len(categories) > 0 {
+		builder = builder.Where(sq.Eq{"reason_category": categories})
+	}
+
+	query, args, errQuery := builder.ToSql()
 	if errQuery != nil {
 		return nil, dbErr(errQuery, "Failed to build query")
 	}
@@ -1955,7 +3256,8 @@ func (db *pgStore) rglBansQuery(ctx context.Context, steamIDs steamid.Collection
 
 	for rows.Next() {
 		var ban domain.RGLBan
-		if errScan := rows.Scan(&ban.SteamID, &ban.Alias, &ban.ExpiresAt, &ban.CreatedAt, &ban.Reason); errScan != nil {
+		if errScan := rows.Scan(&ban.SteamID, &ban.Alias, &ban.ExpiresAt, &ban.CreatedAt, &ban.Reason,
+			&ban.ReasonCategory); errScan != nil {
 			return nil, dbErr(errScan, "Failed to scan ban")
 		}
 
@@ -1965,12 +3267,19 @@ func (db *pgStore) rglBansQuery(ctx context.Context, steamIDs steamid.Collection
 	return bans, nil
 }
 
-func (db *pgStore) etf2lBansQuery(ctx context.Context, steamIDs steamid.Collection) ([]domain.ETF2LBan, error) {
-	query, args, errQuery := sb.
-		Select("steam_id", "alias", "expires_at", "created_at", "reason").
+func (db *pgStore) etf2lBansQuery(ctx context.Context, steamIDs steamid.Collection,
+	categories []domain.BanCategory,
+) ([]domain.ETF2LBan, error) {
+	builder := sb.
+		Select("steam_id", "alias", "expires_at", "created_at", "reason", "reason_category").
 		From("etf2l_ban").
-		Where(sq.Eq{"steam_id": steamIDs}).
-		ToSql()
+		Where(sq.Eq{"steam_id": steamIDs})
+
+	if len(categories) > 0 {
+		builder = builder.Where(sq.Eq{"reason_category": categories})
+	}
+
+	query, args, errQuery := builder.ToSql()
 	if errQuery != nil {
 		return nil, dbErr(errQuery, "Failed to build etf2l ban query")
 	}
@@ -1986,7 +3295,8 @@ func (db *pgStore) etf2lBansQuery(ctx context.Context, steamIDs steamid.Collecti
 
 	for rows.Next() {
 		var ban domain.ETF2LBan
-		if errScan := rows.Scan(&ban.SteamID, &ban.Alias, &ban.ExpiresAt, &ban.CreatedAt, &ban.Reason); errScan != nil {
+		if errScan := rows.Scan(&ban.SteamID, &ban.Alias, &ban.ExpiresAt, &ban.CreatedAt, &ban.Reason,
+			&ban.ReasonCategory); errScan != nil {
 			return nil, dbErr(errScan, "Failed to scan etf2l ban")
 		}
 
@@ -1996,30 +3306,873 @@ func (db *pgStore) etf2lBansQuery(ctx context.Context, steamIDs steamid.Collecti
 	return bans, nil
 }
 
-func (db *pgStore) insertJobTx(ctx context.Context, client *river.Client[pgx.Tx], job river.JobArgs, opts *river.InsertOpts) error {
+func (db *pgStore) etf2lTeamGet(ctx context.Context, teamID int) (domain.ETF2LTeam, error) {
+	var team domain.ETF2LTeam
+
+	query, args, errQuery := sb.
+		Select("team_id", "team_name", "tag", "team_type", "country", "scraped_on", "created_on", "updated_on").
+		From("etf2l_team").
+		Where(sq.Eq{"team_id": teamID}).
+		ToSql()
+	if errQuery != nil {
+		return team, dbErr(errQuery, "Failed to build etf2l team query")
+	}
+
+	if err := db.pool.QueryRow(ctx, query, args...).
+		Scan(&team.TeamID, &team.TeamName, &team.Tag, &team.TeamType, &team.Country, &team.ScrapedOn,
+			&team.CreatedOn, &team.UpdatedOn); err != nil {
+		return team, dbErr(err, "Failed to query etf2l team")
+	}
+
+	return team, nil
+}
+
+// etf2lTeamSave inserts or updates a team fetched from its own details.
+func (db *pgStore) etf2lTeamSave(ctx context.Context, team domain.ETF2LTeam) error {
+	const query = `
+		INSERT INTO etf2l_team (team_id, team_name, tag, team_type, country, scraped_on, created_on, updated_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (team_id) DO UPDATE 
+		SET team_name = $2, tag = $3, team_type = $4, country = $5, scraped_on = $6, updated_on = $8`
+
+	if _, err := db.pool.Exec(ctx, query, team.TeamID, team.TeamName, team.Tag, team.TeamType, team.Country,
+		team.ScrapedOn, team.CreatedOn, team.UpdatedOn); err != nil {
+		return dbErr(err, "Failed to save etf2l team")
+	}
+
+	return nil
+}
+
+// etf2lTeamsStale returns the team ids which have never had their details fetched, or were fetched before the
+// given time.
+func (db *pgStore) etf2lTeamsStale(ctx context.Context, teamIDs []int, before time.Time) ([]int, error) {
+	if len(teamIDs) == 0 {
+		return nil, nil
+	}
+
+	query, args, errQuery := sb.
+		Select("team_id").
+		From("etf2l_team").
+		Where(sq.And{
+			sq.Eq{"team_id": teamIDs},
+			sq.Or{sq.Eq{"scraped_on": nil}, sq.Lt{"scraped_on": before}},
+		}).
+		ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build stale etf2l team query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query stale etf2l teams")
+	}
+
+	defer rows.Close()
+
+	var stale []int
+
+	for rows.Next() {
+		var teamID int
+		if err := rows.Scan(&teamID); err != nil {
+			return nil, dbErr(err, "Failed to scan stale etf2l team")
+		}
+
+		stale = append(stale, teamID)
+	}
+
+	return stale, nil
+}
+
+// etf2lTransfersApply updates the team stints from transfers, which must be ordered oldest first. A join starts
+// a new stint, a leave closes the open stint it follows. Applying the same transfers again has no effect.
+func (db *pgStore) etf2lTransfersApply(ctx context.Context, transfers []domain.ETF2LTransfer) error {
+	const (
+		teamQuery = `
+			INSERT INTO etf2l_team (team_id, team_name, team_type, created_on, updated_on)
+			VALUES ($1, $2, $3, $4, $4)
+			ON CONFLICT (team_id) DO NOTHING`
+		joinQuery = `
+			INSERT INTO etf2l_team_member (team_id, steam_id, name, joined_at) 
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (team_id, steam_id, joined_at) DO UPDATE SET name = $3`
+		leaveQuery = `
+			UPDATE etf2l_team_member SET left_at = $3
+			WHERE team_id = $1 AND steam_id = $2 AND left_at IS NULL AND joined_at <= $3`
+	)
+
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	for _, transfer := range transfers {
+		record := newPlayerRecord(transfer.SteamID)
+		if err := db.playerGetOrCreate(ctx, transfer.SteamID, &record); err != nil {
+			return err
+		}
+	}
+
 	transaction, errTx := db.pool.Begin(ctx)
 	if errTx != nil {
-		return dbErr(errTx, "Failed to being tx")
+		return dbErr(errTx, "Failed to begin etf2l transfer tx")
 	}
 
 	defer func() {
 		_ = transaction.Rollback(ctx)
 	}()
 
-	if _, err := client.InsertTx(ctx, transaction, job, opts); err != nil {
-		slog.Error("Failed to insert rgl season followup jobs", ErrAttr(err))
+	batch := &pgx.Batch{}
+	now := time.Now()
 
-		return errors.Join(err, errQueueInsert)
+	for _, transfer := range transfers {
+		batch.Queue(teamQuery, transfer.TeamID, transfer.TeamName, transfer.TeamType, now)
+
+		if transfer.Joined {
+			batch.Queue(joinQuery, transfer.TeamID, transfer.SteamID.Int64(), transfer.Name, transfer.Time)
+		} else {
+			batch.Queue(leaveQuery, transfer.TeamID, transfer.SteamID.Int64(), transfer.Time)
+		}
+	}
+
+	if err := transaction.SendBatch(ctx, batch).Close(); err != nil {
+		return dbErr(err, "Failed to send batch etf2l transfers")
 	}
 
 	if err := transaction.Commit(ctx); err != nil {
-		return dbErr(err, "Failed to commit jobs tx")
+		return dbErr(err, "Failed to commit etf2l transfers")
 	}
 
 	return nil
 }
 
-func (db *pgStore) insertJobsTx(ctx context.Context, client *river.Client[pgx.Tx], jobs []river.InsertManyParams) error {
+func (db *pgStore) etf2lMatchesSave(ctx context.Context, matches []domain.ETF2LMatch) error {
+	const query = `
+		INSERT INTO etf2l_match (match_id, competition_id, competition_name, division_name, round_name, team1_id, 
+		                         team2_id, team1_score, team2_score, default_win, maps, played_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (match_id) DO UPDATE 
+		SET competition_name = $3, division_name = $4, round_name = $5, team1_score = $8, team2_score = $9, 
+		    default_win = $10, maps = $11, played_at = $12`
+
+	if len(matches) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+
+	for _, match := range matches {
+		batch.Queue(query, match.MatchID, match.CompetitionID, match.CompetitionName, match.DivisionName,
+			match.RoundName, match.Team1ID, match.Team2ID, match.Team1Score, match.Team2Score, match.DefaultWin,
+			match.Maps, match.PlayedAt)
+	}
+
+	if err := db.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return dbErr(err, "Failed to save etf2l matches")
+	}
+
+	return nil
+}
+
+// etf2lPlayerScrapedOn returns when the players profile was last fetched.
+func (db *pgStore) etf2lPlayerScrapedOn(ctx context.Context, steamID steamid.SteamID) (time.Time, error) {
+	var scrapedOn time.Time
+
+	if err := db.pool.QueryRow(ctx, `SELECT scraped_on FROM etf2l_player WHERE steam_id = $1`, steamID.Int64()).
+		Scan(&scrapedOn); err != nil {
+		return scrapedOn, dbErr(err, "Failed to query etf2l player")
+	}
+
+	return scrapedOn, nil
+}
+
+func (db *pgStore) etf2lPlayerScraped(ctx context.Context, steamID steamid.SteamID, playerID int, name string) error {
+	const query = `
+		INSERT INTO etf2l_player (steam_id, player_id, name, scraped_on) 
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (steam_id) DO UPDATE SET player_id = $2, name = $3, scraped_on = $4`
+
+	record := newPlayerRecord(steamID)
+	if err := db.playerGetOrCreate(ctx, steamID, &record); err != nil {
+		return err
+	}
+
+	if _, err := db.pool.Exec(ctx, query, steamID.Int64(), playerID, name, time.Now()); err != nil {
+		return dbErr(err, "Failed to save etf2l player")
+	}
+
+	return nil
+}
+
+func (db *pgStore) etf2lPlayerTeamHistory(ctx context.Context, steamIDs steamid.Collection) ([]domain.ETF2LPlayerTeamHistory, error) {
+	query, args, errQuery := sb.
+		Select("t.team_id", "t.team_name", "t.tag", "t.team_type", "m.name", "m.joined_at", "m.left_at",
+			"m.steam_id").
+		From("etf2l_team_member m").
+		Join("etf2l_team t ON t.team_id = m.team_id").
+		Where(sq.Eq{"m.steam_id": steamIDs.ToInt64Slice()}).
+		OrderBy("m.joined_at DESC").ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to construct etf2l team history query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query etf2l team history")
+	}
+
+	defer rows.Close()
+
+	history := make([]domain.ETF2LPlayerTeamHistory, 0)
+
+	for rows.Next() {
+		var hist domain.ETF2LPlayerTeamHistory
+		if err := rows.Scan(&hist.TeamID, &hist.TeamName, &hist.Tag, &hist.TeamType, &hist.Name, &hist.JoinedAt,
+			&hist.LeftAt, &hist.SteamID); err != nil {
+			return nil, dbErr(err, "Failed to scan etf2l team history")
+		}
+
+		history = append(history, hist)
+	}
+
+	return history, nil
+}
+
+func (db *pgStore) ugcBansReplace(ctx context.Context, bans []domain.UGCBan) error {
+	const query = `
+		INSERT INTO ugc_ban (steam_id, alias, expires_at, created_at, reason, reason_category) 
+		VALUES ($1, $2, $3 ,$4, $5, $6)`
+
+	for _, ban := range bans {
+		record := newPlayerRecord(ban.SteamID)
+		if err := db.playerGetOrCreate(ctx, ban.SteamID, &record); err != nil {
+			return err
+		}
+	}
+
+	transaction, errTx := db.pool.Begin(ctx)
+	if errTx != nil {
+		return dbErr(errTx, "Failed to begin ugc ban tx")
+	}
+
+	defer func() {
+		_ = transaction.Rollback(ctx)
+	}()
+
+	if _, err := transaction.Exec(ctx, `DELETE FROM ugc_ban`); err != nil {
+		return dbErr(err, "Failed to delete previous ugc bans")
+	}
+
+	batch := &pgx.Batch{}
+
+	for _, ban := range bans {
+		batch.Queue(query, ban.SteamID.Int64(), ban.Alias, ban.ExpiresAt, ban.CreatedAt, ban.Reason, ban.ReasonCategory)
+	}
+
+	if err := transaction.SendBatch(ctx, batch).Close(); err != nil {
+		return dbErr(err, "Failed to send batch ugc bans")
+	}
+
+	if err := transaction.Commit(ctx); err != nil {
+		return dbErr(err, "Failed to commit ugc bans")
+	}
+
+	return nil
+}
+
+func (db *pgStore) ugcBansQuery(ctx context.Context, steamIDs steamid.Collection,
+	categories []domain.BanCategory,
+) ([]domain.UGCBan, error) {
+	builder := sb.
+		Select("steam_id", "alias", "expires_at", "created_at", "reason", "reason_category").
+		From("ugc_ban")
+
+	if steamIDs != nil {
+		builder = builder.Where(sq.Eq{"steam_id": steamIDs.ToInt64Slice()})
+	}
+
+	if len(categories) > 0 {
+		builder = builder.Where(sq.Eq{"reason_category": categories})
+	}
+
+	query, args, errQuery := builder.OrderBy("created_at DESC").ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build ugc ban query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to exec ugc ban query")
+	}
+
+	defer rows.Close()
+
+	var bans []domain.UGCBan
+
+	for rows.Next() {
+		var ban domain.UGCBan
+		if errScan := rows.Scan(&ban.SteamID, &ban.Alias, &ban.ExpiresAt, &ban.CreatedAt, &ban.Reason,
+			&ban.ReasonCategory); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan ugc ban")
+		}
+
+		bans = append(bans, ban)
+	}
+
+	return bans, nil
+}
+
+func (db *pgStore) ugcTeamGet(ctx context.Context, teamID int) (domain.UGCTeam, error) {
+	var team domain.UGCTeam
+
+	query, args, errQuery := sb.
+		Select("team_id", "team_name", "tag", "format_name", "division_name", "region_name", "scraped_on",
+			"created_on", "updated_on").
+		From("ugc_team").
+		Where(sq.Eq{"team_id": teamID}).
+		ToSql()
+	if errQuery != nil {
+		return team, dbErr(errQuery, "Failed to build ugc team query")
+	}
+
+	if err := db.pool.QueryRow(ctx, query, args...).
+		Scan(&team.TeamID, &team.TeamName, &team.Tag, &team.FormatName, &team.DivisionName, &team.RegionName,
+			&team.ScrapedOn, &team.CreatedOn, &team.UpdatedOn); err != nil {
+		return team, dbErr(err, "Failed to query ugc team")
+	}
+
+	return team, nil
+}
+
+// ugcTeamSave inserts or updates a team scraped from its own page.
+func (db *pgStore) ugcTeamSave(ctx context.Context, team domain.UGCTeam) error {
+	const query = `
+		INSERT INTO ugc_team (team_id, team_name, tag, format_name, division_name, region_name, scraped_on, created_on, 
+		                      updated_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (team_id) DO UPDATE 
+		SET team_name = $2, tag = $3, format_name = $4, division_name = $5, region_name = $6, scraped_on = $7, 
+		    updated_on = $9`
+
+	if _, err := db.pool.Exec(ctx, query, team.TeamID, team.TeamName, team.Tag, team.FormatName, team.DivisionName,
+		team.RegionName, team.ScrapedOn, team.CreatedOn, team.UpdatedOn); err != nil {
+		return dbErr(err, "Failed to save ugc team")
+	}
+
+	return nil
+}
+
+// ugcTeamEnsure inserts a team seen in a players team history, without overwriting the details from its own page.
+func (db *pgStore) ugcTeamEnsure(ctx context.Context, team domain.UGCTeam) error {
+	const query = `
+		INSERT INTO ugc_team (team_id, team_name, format_name, division_name, created_on, updated_on)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (team_id) DO NOTHING`
+
+	if _, err := db.pool.Exec(ctx, query, team.TeamID, team.TeamName, team.FormatName, team.DivisionName,
+		team.CreatedOn, team.UpdatedOn); err != nil {
+		return dbErr(err, "Failed to ensure ugc team")
+	}
+
+	return nil
+}
+
+// ugcTeamsStale returns the team ids which have never had their page scraped, or were scraped before the given time.
+func (db *pgStore) ugcTeamsStale(ctx context.Context, teamIDs []int, before time.Time) ([]int, error) {
+	if len(teamIDs) == 0 {
+		return nil, nil
+	}
+
+	query, args, errQuery := sb.
+		Select("team_id").
+		From("ugc_team").
+		Where(sq.And{
+			sq.Eq{"team_id": teamIDs},
+			sq.Or{sq.Eq{"scraped_on": nil}, sq.Lt{"scraped_on": before}},
+		}).
+		ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build stale ugc team query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query stale ugc teams")
+	}
+
+	defer rows.Close()
+
+	var stale []int
+
+	for rows.Next() {
+		var teamID int
+		if err := rows.Scan(&teamID); err != nil {
+			return nil, dbErr(err, "Failed to scan stale ugc team")
+		}
+
+		stale = append(stale, teamID)
+	}
+
+	return stale, nil
+}
+
+// ugcTeamMemberSave inserts or updates a roster entry. The leader flag is only shown on the team page, so it is
+// kept when the entry is updated from the players team history.
+func (db *pgStore) ugcTeamMemberSave(ctx context.Context, member domain.UGCTeamMember) error {
+	const query = `
+		INSERT INTO ugc_team_member (team_id, steam_id, name, is_team_leader, joined_at, left_at) 
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (team_id, steam_id, joined_at)
+		DO UPDATE SET name = $3, is_team_leader = ugc_team_member.is_team_leader OR $4, left_at = $6`
+
+	record := newPlayerRecord(member.SteamID)
+	if err := db.playerGetOrCreate(ctx, member.SteamID, &record); err != nil {
+		return err
+	}
+
+	if _, err := db.pool.Exec(ctx, query, member.TeamID, member.SteamID.Int64(), member.Name, member.IsTeamLeader,
+		member.JoinedAt, member.LeftAt); err != nil {
+		return dbErr(err, "Failed to save ugc team member")
+	}
+
+	return nil
+}
+
+// ugcPlayerScrapedOn returns when the players page was last scraped.
+func (db *pgStore) ugcPlayerScrapedOn(ctx context.Context, steamID steamid.SteamID) (time.Time, error) {
+	var scrapedOn time.Time
+
+	if err := db.pool.QueryRow(ctx, `SELECT scraped_on FROM ugc_player WHERE steam_id = $1`, steamID.Int64()).
+		Scan(&scrapedOn); err != nil {
+		return scrapedOn, dbErr(err, "Failed to query ugc player")
+	}
+
+	return scrapedOn, nil
+}
+
+func (db *pgStore) ugcPlayerScraped(ctx context.Context, steamID steamid.SteamID, name string) error {
+	const query = `
+		INSERT INTO ugc_player (steam_id, name, scraped_on) 
+		VALUES ($1, $2, $3)
+		ON CONFLICT (steam_id) DO UPDATE SET name = $2, scraped_on = $3`
+
+	record := newPlayerRecord(steamID)
+	if err := db.playerGetOrCreate(ctx, steamID, &record); err != nil {
+		return err
+	}
+
+	if _, err := db.pool.Exec(ctx, query, steamID.Int64(), name, time.Now()); err != nil {
+		return dbErr(err, "Failed to save ugc player")
+	}
+
+	return nil
+}
+
+func (db *pgStore) ugcPlayerTeamHistory(ctx context.Context, steamIDs steamid.Collection) ([]domain.UGCPlayerTeamHistory, error) {
+	query, args, errQuery := sb.
+		Select("t.team_id", "t.team_name", "t.tag", "t.format_name", "t.division_name",
+			"m.name", "m.is_team_leader", "m.joined_at", "m.left_at", "m.steam_id").
+		From("ugc_team_member m").
+		Join("ugc_team t ON t.team_id = m.team_id").
+		Where(sq.Eq{"m.steam_id": steamIDs.ToInt64Slice()}).
+		OrderBy("m.joined_at DESC").ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to construct ugc team history query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query ugc team history")
+	}
+
+	defer rows.Close()
+
+	history := make([]domain.UGCPlayerTeamHistory, 0)
+
+	for rows.Next() {
+		var hist domain.UGCPlayerTeamHistory
+		if err := rows.Scan(&hist.TeamID, &hist.TeamName, &hist.Tag, &hist.FormatName, &hist.DivisionName,
+			&hist.Name, &hist.IsTeamLeader, &hist.JoinedAt, &hist.LeftAt, &hist.SteamID); err != nil {
+			return nil, dbErr(err, "Failed to scan ugc team history")
+		}
+
+		history = append(history, hist)
+	}
+
+	return history, nil
+}
+
+// ozfortressBansReplace replaces the bans of a single player, bans are only available from the users profile.
+func (db *pgStore) ozfortressBansReplace(ctx context.Context, steamID steamid.SteamID, bans []domain.OzfortressBan) error {
+	const query = `
+		INSERT INTO ozfortress_ban (steam_id, alias, expires_at, created_at, reason, reason_category) 
+		VALUES ($1, $2, $3 ,$4, $5, $6)`
+
+	record := newPlayerRecord(steamID)
+	if err := db.playerGetOrCreate(ctx, steamID, &record); err != nil {
+		return err
+	}
+
+	transaction, errTx := db.pool.Begin(ctx)
+	if errTx != nil {
+		return dbErr(errTx, "Failed to begin ozfortress ban tx")
+	}
+
+	defer func() {
+		_ = transaction.Rollback(ctx)
+	}()
+
+	if _, err := transaction.Exec(ctx, `DELETE FROM ozfortress_ban WHERE steam_id = $1`, steamID.Int64()); err != nil {
+		return dbErr(err, "Failed to delete previous ozfortress bans")
+	}
+
+	batch := &pgx.Batch{}
+
+	for _, ban := range bans {
+		batch.Queue(query, ban.SteamID.Int64(), ban.Alias, ban.ExpiresAt, ban.CreatedAt, ban.Reason, ban.ReasonCategory)
+	}
+
+	if err := transaction.SendBatch(ctx, batch).Close(); err != nil {
+		return dbErr(err, "Failed to send batch ozfortress bans")
+	}
+
+	if err := transaction.Commit(ctx); err != nil {
+		return dbErr(err, "Failed to commit ozfortress bans")
+	}
+
+	return nil
+}
+
+func (db *pgStore) ozfortressBansQuery(ctx context.Context, steamIDs steamid.Collection,
+	categories []domain.BanCategory,
+) ([]domain.OzfortressBan, error) {
+	builder := sb.
+		Select("steam_id", "alias", "expires_at", "created_at", "reason", "reason_category").
+		From("ozfortress_ban")
+
+	if steamIDs != nil {
+		builder = builder.Where(sq.Eq{"steam_id": steamIDs.ToInt64Slice()})
+	}
+
+	if len(categories) > 0 {
+		builder = builder.Where(sq.Eq{"reason_category": categories})
+	}
+
+	query, args, errQuery := builder.OrderBy("created_at DESC").ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build ozfortress ban query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to exec ozfortress ban query")
+	}
+
+	defer rows.Close()
+
+	var bans []domain.OzfortressBan
+
+	for rows.Next() {
+		var ban domain.OzfortressBan
+		if errScan := rows.Scan(&ban.SteamID, &ban.Alias, &ban.ExpiresAt, &ban.CreatedAt, &ban.Reason,
+			&ban.ReasonCategory); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan ozfortress ban")
+		}
+
+		bans = append(bans, ban)
+	}
+
+	return bans, nil
+}
+
+// ozfortressRosterSave inserts or updates a roster and its players. Players are never removed, the api only lists
+// the current players, so removing them would lose the history of anyone who has left.
+func (db *pgStore) ozfortressRosterSave(ctx context.Context, roster domain.OzfortressRoster,
+	members []domain.OzfortressRosterMember,
+) error {
+	const rosterQuery = `
+		INSERT INTO ozfortress_roster (roster_id, team_id, team_name, roster_name, division_name, league_id, 
+		                               league_name, created_on, updated_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (roster_id) DO UPDATE 
+		SET team_id = $2, team_name = $3, roster_name = $4, division_name = $5, league_id = $6, league_name = $7, 
+		    updated_on = $9`
+
+	const memberQuery = `
+		INSERT INTO ozfortress_roster_member (roster_id, steam_id, name) 
+		VALUES ($1, $2, $3)
+		ON CONFLICT (roster_id, steam_id) DO UPDATE SET name = $3`
+
+	for _, member := range members {
+		record := newPlayerRecord(member.SteamID)
+		if err := db.playerGetOrCreate(ctx, member.SteamID, &record); err != nil {
+			return err
+		}
+	}
+
+	transaction, errTx := db.pool.Begin(ctx)
+	if errTx != nil {
+		return dbErr(errTx, "Failed to begin ozfortress roster tx")
+	}
+
+	defer func() {
+		_ = transaction.Rollback(ctx)
+	}()
+
+	if _, err := transaction.Exec(ctx, rosterQuery, roster.RosterID, roster.TeamID, roster.TeamName, roster.RosterName,
+		roster.DivisionName, roster.LeagueID, roster.LeagueName, roster.CreatedOn, roster.UpdatedOn); err != nil {
+		return dbErr(err, "Failed to save ozfortress roster")
+	}
+
+	batch := &pgx.Batch{}
+
+	for _, member := range members {
+		batch.Queue(memberQuery, member.RosterID, member.SteamID.Int64(), member.Name)
+	}
+
+	if err := transaction.SendBatch(ctx, batch).Close(); err != nil {
+		return dbErr(err, "Failed to send batch ozfortress roster members")
+	}
+
+	if err := transaction.Commit(ctx); err != nil {
+		return dbErr(err, "Failed to commit ozfortress roster")
+	}
+
+	return nil
+}
+
+// ozfortressRosterMaxID returns the highest roster id fetched so far, or 0 when none have been.
+func (db *pgStore) ozfortressRosterMaxID(ctx context.Context) (int, error) {
+	var rosterID int
+
+	if err := db.pool.QueryRow(ctx, `SELECT coalesce(max(roster_id), 0) FROM ozfortress_roster`).
+		Scan(&rosterID); err != nil {
+		return 0, dbErr(err, "Failed to query max ozfortress roster")
+	}
+
+	return rosterID, nil
+}
+
+func (db *pgStore) ozfortressMatchSave(ctx context.Context, match domain.OzfortressMatch) error {
+	const query = `
+		INSERT INTO ozfortress_match (match_id, league_id, round_name, status, home_roster_id, away_roster_id, 
+		                              home_score, away_score, forfeit_by, maps, created_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (match_id) DO UPDATE 
+		SET round_name = $3, status = $4, home_roster_id = $5, away_roster_id = $6, home_score = $7, away_score = $8, 
+		    forfeit_by = $9, maps = $10`
+
+	if _, err := db.pool.Exec(ctx, query, match.MatchID, match.LeagueID, match.RoundName, match.Status,
+		match.HomeRosterID, match.AwayRosterID, match.HomeScore, match.AwayScore, match.ForfeitBy, match.Maps,
+		match.CreatedOn); err != nil {
+		return dbErr(err, "Failed to save ozfortress match")
+	}
+
+	return nil
+}
+
+// ozfortressMatchesPending returns the match ids which are unknown, or have not had their result confirmed yet.
+func (db *pgStore) ozfortressMatchesPending(ctx context.Context, matchIDs []int) ([]int, error) {
+	if len(matchIDs) == 0 {
+		return nil, nil
+	}
+
+	query, args, errQuery := sb.
+		Select("match_id").
+		From("ozfortress_match").
+		Where(sq.And{sq.Eq{"match_id": matchIDs}, sq.Eq{"status": ozfortressMatchConfirmed}}).
+		ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build confirmed ozfortress match query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query confirmed ozfortress matches")
+	}
+
+	defer rows.Close()
+
+	confirmed := map[int]bool{}
+
+	for rows.Next() {
+		var matchID int
+		if err := rows.Scan(&matchID); err != nil {
+			return nil, dbErr(err, "Failed to scan confirmed ozfortress match")
+		}
+
+		confirmed[matchID] = true
+	}
+
+	var pending []int
+
+	for _, matchID := range matchIDs {
+		if !confirmed[matchID] {
+			pending = append(pending, matchID)
+		}
+	}
+
+	return pending, nil
+}
+
+// ozfortressPlayerScrapedOn returns when the players user profile was last fetched.
+func (db *pgStore) ozfortressPlayerScrapedOn(ctx context.Context, steamID steamid.SteamID) (time.Time, error) {
+	var scrapedOn time.Time
+
+	if err := db.pool.QueryRow(ctx, `SELECT scraped_on FROM ozfortress_player WHERE steam_id = $1`, steamID.Int64()).
+		Scan(&scrapedOn); err != nil {
+		return scrapedOn, dbErr(err, "Failed to query ozfortress player")
+	}
+
+	return scrapedOn, nil
+}
+
+func (db *pgStore) ozfortressPlayerScraped(ctx context.Context, steamID steamid.SteamID, userID int, name string) error {
+	const query = `
+		INSERT INTO ozfortress_player (steam_id, user_id, name, scraped_on) 
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (steam_id) DO UPDATE SET user_id = $2, name = $3, scraped_on = $4`
+
+	record := newPlayerRecord(steamID)
+	if err := db.playerGetOrCreate(ctx, steamID, &record); err != nil {
+		return err
+	}
+
+	if _, err := db.pool.Exec(ctx, query, steamID.Int64(), userID, name, time.Now()); err != nil {
+		return dbErr(err, "Failed to save ozfortress player")
+	}
+
+	return nil
+}
+
+// ozfortressPlayerHistory returns the rosters each player has been on, along with each rosters confirmed match
+// record. Forfeits count as a win for the other team.
+func (db *pgStore) ozfortressPlayerHistory(ctx context.Context, steamIDs steamid.Collection) ([]domain.OzfortressHistory, error) {
+	const query = `
+		SELECT r.roster_id, r.team_id, r.team_name, r.division_name, r.league_name, m.name, m.steam_id,
+		       count(x.match_id) FILTER (WHERE
+		           (x.home_roster_id = r.roster_id AND 
+		            ((x.forfeit_by = '' AND x.home_score > x.away_score) OR x.forfeit_by = 'away_team_forfeit')) OR
+		           (x.away_roster_id = r.roster_id AND 
+		            ((x.forfeit_by = '' AND x.away_score > x.home_score) OR x.forfeit_by = 'home_team_forfeit'))),
+		       count(x.match_id) FILTER (WHERE
+		           (x.home_roster_id = r.roster_id AND 
+		            ((x.forfeit_by = '' AND x.home_score < x.away_score) OR x.forfeit_by = 'home_team_forfeit')) OR
+		           (x.away_roster_id = r.roster_id AND 
+		            ((x.forfeit_by = '' AND x.away_score < x.home_score) OR x.forfeit_by = 'away_team_forfeit'))),
+		       count(x.match_id) FILTER (WHERE
+		           x.forfeit_by = '' AND x.away_roster_id IS NOT NULL AND x.home_score = x.away_score)
+		FROM ozfortress_roster_member m
+		JOIN ozfortress_roster r ON r.roster_id = m.roster_id
+		LEFT JOIN ozfortress_match x ON x.status = $2 AND 
+		                                (x.home_roster_id = r.roster_id OR x.away_roster_id = r.roster_id)
+		WHERE m.steam_id = ANY($1)
+		GROUP BY r.roster_id, m.steam_id, m.name
+		ORDER BY r.roster_id DESC`
+
+	rows, errRows := db.pool.Query(ctx, query, steamIDs.ToInt64Slice(), ozfortressMatchConfirmed)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query ozfortress history")
+	}
+
+	defer rows.Close()
+
+	history := make([]domain.OzfortressHistory, 0)
+
+	for rows.Next() {
+		var hist domain.OzfortressHistory
+		if err := rows.Scan(&hist.RosterID, &hist.TeamID, &hist.TeamName, &hist.DivisionName, &hist.LeagueName,
+			&hist.Name, &hist.SteamID, &hist.Wins, &hist.Losses, &hist.Draws); err != nil {
+			return nil, dbErr(err, "Failed to scan ozfortress history")
+		}
+
+		history = append(history, hist)
+	}
+
+	return history, nil
+}
+
+// banReasonTables are the tables which store a ban reason alongside a reason_category.
+func banReasonTables() []string {
+	return []string{"sb_ban", "rgl_ban", "etf2l_ban", "ugc_ban", "ozfortress_ban", "serveme"}
+}
+
+// banReasonsUncategorized returns the distinct reasons in the table which have not been classified yet.
+func (db *pgStore) banReasonsUncategorized(ctx context.Context, table string) ([]string, error) {
+	query, args, errQuery := sb.
+		Select("reason").
+		Distinct().
+		From(table).
+		Where(sq.Eq{"reason_category": ""}).
+		ToSql()
+	if errQuery != nil {
+		return nil, dbErr(errQuery, "Failed to build uncategorized reason query")
+	}
+
+	rows, errRows := db.pool.Query(ctx, query, args...)
+	if errRows != nil {
+		return nil, dbErr(errRows, "Failed to query uncategorized reasons")
+	}
+
+	defer rows.Close()
+
+	var reasons []string
+
+	for rows.Next() {
+		var reason string
+		if errScan := rows.Scan(&reason); errScan != nil {
+			return nil, dbErr(errScan, "Failed to scan reason")
+		}
+
+		reasons = append(reasons, reason)
+	}
+
+	return reasons, nil
+}
+
+func (db *pgStore) banReasonCategoriesUpdate(ctx context.Context, table string, categories map[string]domain.BanCategory) error {
+	// Table names are only ever sourced from banReasonTables.
+	query := fmt.Sprintf(`UPDATE %s SET reason_category = $1 WHERE reason = $2 AND reason_category = ''`, table)
+
+	batch := &pgx.Batch{}
+
+	for reason, category := range categories {
+		batch.Queue(query, category, reason)
+	}
+
+	if err := db.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return dbErr(err, "Failed to send batch reason categories")
+	}
+
+	return nil
+}
+
+func (db *pgStore) insertJobTx(ctx context.Context, client *river.Client[pgx.Tx], job river.JobArgs, opts *river.InsertOpts) error {
+	transaction, errTx := db.pool.Begin(ctx)
+	if errTx != nil {
+		return dbErr(errTx, "Failed to being tx")
+	}
+
+	defer func() {
+		_ = transaction.Rollback(ctx)
+	}()
+
+	if _, err := client.InsertTx(ctx, transaction, job, opts); err != nil {
+		slog.Error("Failed to insert followup jobs", ErrAttr(err))
+
+		return errors.Join(err, errQueueInsert)
+	}
+
+	if err := transaction.Commit(ctx); err != nil {
+		return dbErr(err, "Failed to commit jobs tx")
+	}
+
+	return nil
+}
+
+func (db *pgStore) insertJobsTx(ctx context.Context, client *river.Client[pgx.Tx], jobs []river.InsertManyParams) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
 	transaction, errTx := db.pool.Begin(ctx)
 	if errTx != nil {
 		return dbErr(errTx, "Failed to being tx")
@@ -2030,7 +4183,7 @@ func (db *pgStore) insertJobsTx(ctx context.Context, client *river.Client[pgx.Tx
 	}()
 
 	if _, err := client.InsertManyTx(ctx, transaction, jobs); err != nil {
-		slog.Error("Failed to insert rgl season followup jobs", ErrAttr(err))
+		slog.Error("Failed to insert followup jobs", ErrAttr(err))
 
 		return errors.Join(err, errQueueInsert)
 	}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
@@ -42,9 +48,383 @@ func TestStore(t *testing.T) {
 
 	t.Run("sourceBansStoreTest", sourceBansStoreTest(database))               //nolint:paralleltest
 	t.Run("sourceBansPlayerRecordTest", sourceBansPlayerRecordTest(database)) //nolint:paralleltest
+	t.Run("banCategoryStoreTest", banCategoryStoreTest(database))             //nolint:paralleltest
+	t.Run("sourceBansCheckpointTest", sourceBansCheckpointTest(database))     //nolint:paralleltest
+	t.Run("sourceBansSiteStatusTest", sourceBansSiteStatusTest(database))     //nolint:paralleltest
+	t.Run("logsTFGapTest", logsTFGapTest(database))                           //nolint:paralleltest
+	t.Run("logsTFPlayerStatsTest", logsTFPlayerStatsTest(database))           //nolint:paralleltest
+	t.Run("tf2LogImportTest", tf2LogImportTest(database))                     //nolint:paralleltest
+	t.Run("logsTFUploadTest", logsTFUploadTest(database))                     //nolint:paralleltest
+	t.Run("ugcStoreTest", ugcStoreTest(database))                             //nolint:paralleltest
+	t.Run("ozfortressStoreTest", ozfortressStoreTest(database))               //nolint:paralleltest
+	t.Run("etf2lStoreTest", etf2lStoreTest(database))                         //nolint:paralleltest
+	t.Run("rglStoreTest", rglStoreTest(database))                             //nolint:paralleltest
 	t.Run("bot_detector", bdTest(database))
 }
 
+func logsTFPlayerStatsTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		body, errRead := os.ReadFile("testdata/logstf_detail.json")
+		require.NoError(t, errRead)
+
+		match, errMatch := parseMatchFromJSON(3124689, body)
+		require.NoError(t, errMatch)
+		require.NoError(t, database.logsTFMatchCreate(ctx, match))
+
+		stored, errStored := database.logsTFMatchGet(ctx, match.LogID)
+		require.NoError(t, errStored)
+		require.Equal(t, domain.FormatUltiduo, stored.Format)
+
+		medic := match.Medics[0]
+
+		stats, errStats := database.logsTFPlayerClassStats(ctx, medic.SteamID, logsTFStatsFilter{}) //nolint:exhaustruct
+		require.NoError(t, errStats)
+		require.NotEmpty(t, stats)
+
+		idx := slices.IndexFunc(stats, func(s domain.LogsTFPlayerClassStats) bool { return s.Class == domain.Medic })
+		require.GreaterOrEqual(t, idx, 0)
+		require.Equal(t, 1, stats[idx].Games)
+		require.Equal(t, medic.Healing, stats[idx].HealingSum)
+
+		filtered, errFiltered := database.logsTFPlayerClassStats(ctx, medic.SteamID,
+			logsTFStatsFilter{Format: domain.Format6v6}) //nolint:exhaustruct
+		require.NoError(t, errFiltered)
+		require.Empty(t, filtered)
+
+		byMap, errByMap := database.logsTFPlayerClassStats(ctx, medic.SteamID,
+			logsTFStatsFilter{Map: "koth_cascade", Since: match.CreatedOn.Add(-time.Hour)}) //nolint:exhaustruct
+		require.NoError(t, errByMap)
+		require.Len(t, byMap, len(stats))
+
+		wildcard, errWildcard := database.logsTFPlayerClassStats(ctx, medic.SteamID,
+			logsTFStatsFilter{Map: "koth_%"}) //nolint:exhaustruct
+		require.NoError(t, errWildcard)
+		require.Empty(t, wildcard)
+
+		maps, errMaps := database.logsTFMapStats(ctx, logsTFStatsFilter{Map: "koth_cascade"}, 10) //nolint:exhaustruct
+		require.NoError(t, errMaps)
+		require.Len(t, maps, 1)
+		require.Equal(t, 1, maps[0].Games)
+		require.Equal(t, 1, maps[0].RedWins+maps[0].BluWins+maps[0].Ties)
+
+		trends, errTrends := database.logsTFFormatTrends(ctx, logsTFStatsFilter{ //nolint:exhaustruct
+			Format: domain.FormatUltiduo,
+			Until:  match.CreatedOn.Add(time.Hour),
+		}, intervalYear)
+		require.NoError(t, errTrends)
+		require.Len(t, trends, 1)
+		require.Equal(t, 1, trends[0].Games)
+		require.InDelta(t, float32(100), trends[0].Share.Value, 0.001)
+
+		// Views can't be read before the first refresh
+		_, errPercentiles := database.logsTFPlayerPercentiles(ctx, medic.SteamID)
+		require.ErrorIs(t, errPercentiles, errDatabaseNoResults)
+
+		require.NoError(t, database.logsTFLeaderboardsRefresh(ctx))
+		require.NoError(t, database.logsTFLeaderboardsRefresh(ctx))
+
+		// Other tests can add their own players to the views, so only this medics entries are checked.
+		isMedic := func(entry domain.LogsTFLeaderboardEntry) bool { return entry.SteamID == medic.SteamID }
+
+		board, errBoard := database.logsTFLeaderboard(ctx, statHeals, domain.Medic, string(domain.FormatUltiduo), 1, 100)
+		require.NoError(t, errBoard)
+
+		boardIdx := slices.IndexFunc(board, isMedic)
+		require.GreaterOrEqual(t, boardIdx, 0)
+		require.Equal(t, boardIdx+1, board[boardIdx].Rank)
+		require.Equal(t, 1, board[boardIdx].Games)
+
+		all, errAll := database.logsTFLeaderboard(ctx, statHeals, domain.Medic, leaderboardAllFormats, 1, 100)
+		require.NoError(t, errAll)
+		require.True(t, slices.ContainsFunc(all, isMedic))
+
+		notEnough, errNotEnough := database.logsTFLeaderboard(ctx, statHeals, domain.Medic, leaderboardAllFormats, 2, 100)
+		require.NoError(t, errNotEnough)
+		require.False(t, slices.ContainsFunc(notEnough, isMedic))
+
+		// Not enough matches to be ranked
+		summary, errSummary := database.logsTFPlayerSummary(ctx, medic.SteamID)
+		require.NoError(t, errSummary)
+		require.Nil(t, summary.Percentiles)
+
+		medicIdx := slices.IndexFunc(match.Players, func(p domain.LogsTFPlayer) bool { return p.SteamID == medic.SteamID })
+		require.GreaterOrEqual(t, medicIdx, 0)
+
+		var teammate, opponent domain.LogsTFPlayer
+
+		for _, player := range match.Players {
+			switch {
+			case player.SteamID == medic.SteamID:
+				continue
+			case player.Team == match.Players[medicIdx].Team:
+				teammate = player
+			default:
+				opponent = player
+			}
+		}
+
+		shared, errShared := database.logsTFShared(ctx, steamid.Collection{medic.SteamID, teammate.SteamID})
+		require.NoError(t, errShared)
+		require.Equal(t, 1, shared.SameTeam.Games)
+		require.Equal(t, 1, shared.SameTeam.Wins+shared.SameTeam.Losses+shared.SameTeam.Ties)
+		require.Len(t, shared.SameTeam.Players, 2)
+		require.Equal(t, 0, shared.OpposingTeams.Games)
+
+		opposing, errOpposing := database.logsTFShared(ctx, steamid.Collection{medic.SteamID, opponent.SteamID})
+		require.NoError(t, errOpposing)
+		require.Equal(t, 0, opposing.SameTeam.Games)
+		require.Equal(t, 1, opposing.OpposingTeams.Games)
+		require.Len(t, opposing.OpposingTeams.Logs, 1)
+
+		teammates, errTeammates := database.logsTFTeammates(ctx, medic.SteamID, 10)
+		require.NoError(t, errTeammates)
+		require.Len(t, teammates, 1)
+		require.Equal(t, teammate.SteamID, teammates[0].SteamID)
+		require.Equal(t, 1, teammates[0].Games)
+		require.Equal(t, 0, teammates[0].Against)
+	}
+}
+
+func tf2LogImportTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		body, errRead := os.ReadFile("testdata/tf2_server.log")
+		require.NoError(t, errRead)
+
+		match, errImport := importTF2Log(ctx, database, body, "scrim", "")
+		require.NoError(t, errImport)
+		require.Less(t, match.LogID, 0)
+
+		stored, errStored := database.logsTFMatchGet(ctx, match.LogID)
+		require.NoError(t, errStored)
+		require.Equal(t, domain.SourceLocal, stored.Source)
+		require.Equal(t, "scrim", stored.Title)
+		require.Len(t, stored.Players, 4)
+
+		_, errDuplicate := importTF2Log(ctx, database, body, "scrim", "")
+		require.ErrorIs(t, errDuplicate, errTF2LogDuplicate)
+
+		// Imported logs don't affect where the logs.tf scraper resumes from
+		newest, errNewest := database.logsTFNewestID(ctx)
+		require.NoError(t, errNewest)
+		require.Greater(t, newest, 0)
+
+		// Or the players logs.tf counts
+		counts, errCounts := database.logsTFLogCount(ctx, steamid.Collection{stored.Players[0].SteamID})
+		require.NoError(t, errCounts)
+		require.Empty(t, counts)
+	}
+}
+
+func logsTFUploadTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		key, keyHash, errKey := newUploadKey()
+		require.NoError(t, errKey)
+
+		uploadKey, errCreate := database.logsTFUploadKeyCreate(ctx, "league", keyHash)
+		require.NoError(t, errCreate)
+
+		logFile, errRead := os.ReadFile("testdata/tf2_server.log")
+		require.NoError(t, errRead)
+
+		// Differs from the file imported by tf2LogImportTest so it's not rejected as a duplicate
+		logFile = append(logFile, []byte("L 10/16/2026 - 12:03:21: Log file closed.\n")...)
+
+		upload := func(key string) (int, logsTFUploadResponse) {
+			body := bytes.NewBuffer(nil)
+			form := multipart.NewWriter(body)
+			require.NoError(t, form.WriteField("title", "league match"))
+			require.NoError(t, form.WriteField("map", "koth_product_final"))
+			require.NoError(t, form.WriteField("key", key))
+
+			file, errFile := form.CreateFormFile("logfile", "log.log")
+			require.NoError(t, errFile)
+
+			_, errWrite := file.Write(logFile)
+			require.NoError(t, errWrite)
+			require.NoError(t, form.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/upload", body)
+			request.Header.Set("Content-Type", form.FormDataContentType())
+
+			recorder := httptest.NewRecorder()
+			handlePostLogsTFUpload(database, appConfig{})(recorder, request) //nolint:exhaustruct
+
+			var response logsTFUploadResponse
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+
+			return recorder.Code, response
+		}
+
+		status, response := upload("invalid")
+		require.Equal(t, http.StatusUnauthorized, status)
+		require.False(t, response.Success)
+
+		status, response = upload(key)
+		require.Equal(t, http.StatusOK, status)
+		require.True(t, response.Success)
+		require.Less(t, response.LogID, 0)
+		require.Equal(t, fmt.Sprintf("/log/%d", response.LogID), response.URL)
+
+		match, errMatch := database.logsTFMatchGet(ctx, response.LogID)
+		require.NoError(t, errMatch)
+		require.Equal(t, "league match", match.Title)
+		// The map from the log is preferred
+		require.Equal(t, "ultiduo_baloo_v2", match.Map)
+
+		status, _ = upload(key)
+		require.Equal(t, http.StatusConflict, status)
+
+		used, errUsed := database.logsTFUploadKeyByHash(ctx, keyHash)
+		require.NoError(t, errUsed)
+		require.Equal(t, uploadKey.UploadKeyID, used.UploadKeyID)
+		require.Equal(t, 1, used.Uploads)
+		require.NotNil(t, used.LastUsedOn)
+
+		require.NoError(t, database.logsTFUploadKeySetEnabled(ctx, "league", false))
+
+		_, errDisabled := checkUploadKey(ctx, database, appConfig{}, key) //nolint:exhaustruct
+		require.ErrorIs(t, errDisabled, errUnauthorized)
+
+		require.ErrorIs(t, database.logsTFUploadKeySetEnabled(ctx, "unknown", false), errDatabaseNoResults)
+	}
+}
+
+func logsTFGapTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		now := time.Now().Truncate(time.Second)
+
+		failures := newLogsTFFailures()
+		failures.add(10, gapReasonParse)
+		failures.add(11, gapReasonParse)
+		failures.add(20, gapReasonNotFound)
+		require.NoError(t, database.logsTFGapsReplace(ctx, nil, failures.gaps(now.Add(-2*logsTFGapBaseDelay), nil)))
+
+		// Missing logs are not retried automatically
+		due, errDue := database.logsTFGapsDue(ctx, now, logsTFGapBatchSize)
+		require.NoError(t, errDue)
+		require.Len(t, due, 1)
+		require.Equal(t, 10, due[0].StartID)
+		require.Equal(t, 11, due[0].EndID)
+
+		retryFailures := newLogsTFFailures()
+		retryFailures.add(11, gapReasonRequest)
+		require.NoError(t, database.logsTFGapsReplace(ctx, due, due[0].retry(retryFailures, now)))
+
+		due, errDue = database.logsTFGapsDue(ctx, now, logsTFGapBatchSize)
+		require.NoError(t, errDue)
+		require.Empty(t, due)
+
+		require.NoError(t, database.logsTFGapsBackfill(ctx, 1, 1500, newLogsTFBackfill(1, 1500, now)))
+
+		gaps, errGaps := database.logsTFGaps(ctx)
+		require.NoError(t, errGaps)
+		require.Len(t, gaps, 2)
+		require.Equal(t, gapReasonBackfill, gaps[0].Reason)
+		require.Equal(t, 1001, gaps[1].StartID)
+	}
+}
+
+func sourceBansSiteStatusTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		var site domain.SbSite
+		require.NoError(t, database.sourcebansSiteGetOrCreate(ctx, "status-site", &site))
+		require.True(t, site.Enabled)
+		require.Nil(t, site.LastScrapedOn)
+
+		player := newPlayerRecord(steamid.New(76561197961279983))
+		require.NoError(t, database.playerRecordSave(ctx, &player))
+
+		ban := newSourcebansRecord(site, player.SteamID, "name", "reason", time.Now().AddDate(0, 0, -1), time.Hour, false)
+		require.NoError(t, database.sourcebansBanRecordSave(ctx, &ban))
+		require.NoError(t, database.sourcebansSiteScraped(ctx, site.SiteID))
+
+		site.Enabled = false
+		require.NoError(t, database.sourcebansSiteSave(ctx, &site))
+
+		var disabled domain.SbSite
+		require.NoError(t, database.sourcebansSiteGetByName(ctx, site.Name, &disabled))
+		require.False(t, disabled.Enabled)
+		require.NotNil(t, disabled.LastScrapedOn)
+
+		summaries, errSummaries := database.sourcebansSiteSummaries(ctx)
+		require.NoError(t, errSummaries)
+
+		found := false
+
+		for _, summary := range summaries {
+			if summary.SiteID == site.SiteID {
+				found = true
+
+				require.Equal(t, 1, summary.BanCount)
+			}
+		}
+
+		require.True(t, found)
+
+		deleted, errPurge := database.sourcebansSitePurge(ctx, site.SiteID)
+		require.NoError(t, errPurge)
+		require.Equal(t, int64(1), deleted)
+
+		bans, errBans := database.sourcebansRecordBySID(ctx, steamid.Collection{player.SteamID}, nil)
+		require.NoError(t, errBans)
+		require.Empty(t, bans[player.SteamID.String()])
+	}
+}
+
+func sourceBansCheckpointTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		site := newSourcebansSite("checkpoint-site")
+		require.NoError(t, database.sourcebansSiteSave(ctx, &site))
+
+		_, errMissing := database.sourcebansCheckpointGet(ctx, site.SiteID)
+		require.ErrorIs(t, errMissing, errDatabaseNoResults)
+
+		checkpoint := sbCheckpoint{ //nolint:exhaustruct
+			SiteID: site.SiteID,
+			Page:   2,
+			URL:    "https://example.com/index.php?p=banlist&page=2",
+		}
+		require.NoError(t, database.sourcebansCheckpointSave(ctx, &checkpoint))
+
+		checkpoint.Page = 3
+		checkpoint.URL = "https://example.com/index.php?p=banlist&page=3"
+		require.NoError(t, database.sourcebansCheckpointSave(ctx, &checkpoint))
+
+		saved, errSaved := database.sourcebansCheckpointGet(ctx, site.SiteID)
+		require.NoError(t, errSaved)
+		require.Equal(t, checkpoint.Page, saved.Page)
+		require.Equal(t, checkpoint.URL, saved.URL)
+
+		require.NoError(t, database.sourcebansCheckpointDelete(ctx, site.SiteID))
+
+		_, errDeleted := database.sourcebansCheckpointGet(ctx, site.SiteID)
+		require.ErrorIs(t, errDeleted, errDatabaseNoResults)
+	}
+}
+
 func sourceBansStoreTest(database *pgStore) func(t *testing.T) {
 	return func(t *testing.T) {
 		t.Parallel()
@@ -53,11 +433,13 @@ func sourceBansStoreTest(database *pgStore) func(t *testing.T) {
 		require.Error(t, database.sourcebansSiteGet(context.Background(), 99999, &site))
 
 		site2 := newSourcebansSite("test-site")
+		site2.Timezone = "Europe/Moscow"
 		require.NoError(t, database.sourcebansSiteSave(context.Background(), &site2))
 
 		var site3 domain.SbSite
 		require.NoError(t, database.sourcebansSiteGet(context.Background(), site2.SiteID, &site3))
 		require.Equal(t, site2.Name, site3.Name)
+		require.Equal(t, site2.Timezone, site3.Timezone)
 		require.Equal(t, site2.UpdatedOn.Second(), site3.UpdatedOn.Second())
 
 		pRecord := newPlayerRecord(testIDCamper)
@@ -74,6 +456,51 @@ func sourceBansStoreTest(database *pgStore) func(t *testing.T) {
 	}
 }
 
+func banCategoryStoreTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		ids := generateIDs(3)
+		cheater, toxic, categorized := ids[0], ids[1], ids[2]
+		now := time.Now()
+
+		// Rows saved before classification existed have an empty reason_category. The already categorized row
+		// has a reason that would classify differently, so any update to it would be visible.
+		require.NoError(t, database.rglBansReplace(ctx, []domain.RGLBan{
+			{SteamID: cheater, Alias: "cheater", ExpiresAt: now.AddDate(1, 0, 0), CreatedAt: now, Reason: "Aimbot"},
+			{SteamID: toxic, Alias: "toxic", ExpiresAt: now.AddDate(1, 0, 0), CreatedAt: now, Reason: "Harassment"},
+			{
+				SteamID: categorized, Alias: "categorized", ExpiresAt: now.AddDate(1, 0, 0), CreatedAt: now,
+				Reason: "Aimbot", ReasonCategory: domain.CategoryOther,
+			},
+		}))
+
+		reasons, errReasons := database.banReasonsUncategorized(ctx, "rgl_ban")
+		require.NoError(t, errReasons)
+		require.ElementsMatch(t, []string{"Aimbot", "Harassment"}, reasons)
+
+		require.NoError(t, updateBanCategories(ctx, database))
+
+		bans, errBans := database.rglBansQuery(ctx, ids, nil)
+		require.NoError(t, errBans)
+		require.Len(t, bans, 3)
+
+		categories := map[steamid.SteamID]domain.BanCategory{}
+		for _, ban := range bans {
+			categories[ban.SteamID] = ban.ReasonCategory
+		}
+
+		require.Equal(t, domain.CategoryCheating, categories[cheater])
+		require.Equal(t, domain.CategoryToxicity, categories[toxic])
+		require.Equal(t, domain.CategoryOther, categories[categorized])
+
+		remaining, errRemaining := database.banReasonsUncategorized(ctx, "rgl_ban")
+		require.NoError(t, errRemaining)
+		require.Empty(t, remaining)
+	}
+}
+
 func sourceBansPlayerRecordTest(database *pgStore) func(t *testing.T) {
 	return func(t *testing.T) {
 		t.Parallel()
@@ -190,3 +617,291 @@ func bdTest(database *pgStore) func(t *testing.T) {
 		require.Equal(t, newName, listAEdited.BDListName)
 	}
 }
+
+func ugcStoreTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		bans := parseUGCBans(readUGCFixture(t, "ugc_bans.html"))
+		require.NoError(t, database.ugcBansReplace(ctx, bans))
+		require.NoError(t, database.ugcBansReplace(ctx, bans))
+
+		found, errFound := database.ugcBansQuery(ctx, steamid.Collection{bans[0].SteamID}, nil)
+		require.NoError(t, errFound)
+		require.Len(t, found, 1)
+		require.Equal(t, bans[0].Reason, found[0].Reason)
+
+		all, errAll := database.ugcBansQuery(ctx, nil, nil)
+		require.NoError(t, errAll)
+		require.Len(t, all, len(bans))
+
+		// A player page adds teams that are queued for scraping.
+		sid := steamid.New(76561198084134025)
+		name, teams := parseUGCPlayer(readUGCFixture(t, "ugc_player.html"), sid)
+
+		for _, playerTeam := range teams {
+			require.NoError(t, database.ugcTeamEnsure(ctx, playerTeam.team))
+			require.NoError(t, database.ugcTeamMemberSave(ctx, playerTeam.member))
+		}
+
+		require.NoError(t, database.ugcPlayerScraped(ctx, sid, name))
+
+		scrapedOn, errScraped := database.ugcPlayerScrapedOn(ctx, sid)
+		require.NoError(t, errScraped)
+		require.Less(t, time.Since(scrapedOn), time.Minute)
+
+		stale, errStale := database.ugcTeamsStale(ctx, []int{31337, 1234}, time.Now().Add(-ugcRefreshInterval))
+		require.NoError(t, errStale)
+		require.ElementsMatch(t, []int{31337, 1234}, stale)
+
+		// Scraping the team page fills in the details and marks the leader.
+		team, members, errTeam := parseUGCTeam(readUGCFixture(t, "ugc_team.html"), 31337)
+		require.NoError(t, errTeam)
+		require.NoError(t, database.ugcTeamSave(ctx, team))
+
+		for _, member := range members {
+			require.NoError(t, database.ugcTeamMemberSave(ctx, member))
+		}
+
+		stale, errStale = database.ugcTeamsStale(ctx, []int{31337, 1234}, time.Now().Add(-ugcRefreshInterval))
+		require.NoError(t, errStale)
+		require.Equal(t, []int{1234}, stale)
+
+		saved, errSaved := database.ugcTeamGet(ctx, 31337)
+		require.NoError(t, errSaved)
+		require.Equal(t, "FROYO", saved.Tag)
+
+		history, errHistory := database.ugcPlayerTeamHistory(ctx, steamid.Collection{sid})
+		require.NoError(t, errHistory)
+		require.Len(t, history, 2)
+		require.Equal(t, "Froyo Tech", history[0].TeamName)
+		require.Equal(t, "FROYO", history[0].Tag)
+		require.Nil(t, history[0].LeftAt)
+		require.NotNil(t, history[1].LeftAt)
+	}
+}
+
+func ozfortressStoreTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestOzfortressClient(t)
+		sid := steamid.New(76561198084134025)
+
+		user, errUser := client.user(ctx, sid)
+		require.NoError(t, errUser)
+		require.NoError(t, database.ozfortressBansReplace(ctx, sid, user.toBans(sid)))
+		require.NoError(t, database.ozfortressBansReplace(ctx, sid, user.toBans(sid)))
+		require.NoError(t, database.ozfortressPlayerScraped(ctx, sid, user.ID, user.Name))
+
+		bans, errBans := database.ozfortressBansQuery(ctx, steamid.Collection{sid}, nil)
+		require.NoError(t, errBans)
+		require.Len(t, bans, 2)
+
+		scrapedOn, errScraped := database.ozfortressPlayerScrapedOn(ctx, sid)
+		require.NoError(t, errScraped)
+		require.Less(t, time.Since(scrapedOn), time.Minute)
+
+		apiRoster, errRoster := client.roster(ctx, 1210)
+		require.NoError(t, errRoster)
+
+		roster, members := apiRoster.toDomain()
+		require.NoError(t, database.ozfortressRosterSave(ctx, roster, members))
+
+		maxID, errMax := database.ozfortressRosterMaxID(ctx)
+		require.NoError(t, errMax)
+		require.Equal(t, 1210, maxID)
+
+		pending, errPending := database.ozfortressMatchesPending(ctx, []int{9001, 9002})
+		require.NoError(t, errPending)
+		require.Equal(t, []int{9001, 9002}, pending)
+
+		apiMatch, errMatch := client.match(ctx, 9001)
+		require.NoError(t, errMatch)
+		require.NoError(t, database.ozfortressMatchSave(ctx, apiMatch.toDomain()))
+
+		pending, errPending = database.ozfortressMatchesPending(ctx, []int{9001, 9002})
+		require.NoError(t, errPending)
+		require.Equal(t, []int{9002}, pending)
+
+		history, errHistory := database.ozfortressPlayerHistory(ctx, steamid.Collection{sid})
+		require.NoError(t, errHistory)
+		require.Len(t, history, 1)
+		require.Equal(t, "Dingo Den", history[0].TeamName)
+		require.Equal(t, "koala", history[0].Name)
+		require.Equal(t, 1, history[0].Wins)
+		require.Equal(t, 0, history[0].Losses)
+	}
+}
+
+func etf2lStoreTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		client := newTestETF2LClient(t)
+		sid := steamid.New(76561198084134025)
+
+		transfers, errTransfers := client.playerTransfers(ctx, 21341)
+		require.NoError(t, errTransfers)
+		require.NoError(t, database.etf2lTransfersApply(ctx, transfers))
+		require.NoError(t, database.etf2lTransfersApply(ctx, transfers))
+		require.NoError(t, database.etf2lPlayerScraped(ctx, sid, 21341, "habib"))
+
+		scrapedOn, errScraped := database.etf2lPlayerScrapedOn(ctx, sid)
+		require.NoError(t, errScraped)
+		require.Less(t, time.Since(scrapedOn), time.Minute)
+
+		// Teams only seen in transfers have not had their details fetched.
+		stale, errStale := database.etf2lTeamsStale(ctx, []int{2042, 3311}, time.Now().Add(-etf2lRefreshInterval))
+		require.NoError(t, errStale)
+		require.ElementsMatch(t, []int{2042, 3311}, stale)
+
+		team, errTeam := client.team(ctx, 3311)
+		require.NoError(t, errTeam)
+		require.NoError(t, database.etf2lTeamSave(ctx, team.toDomain()))
+
+		stale, errStale = database.etf2lTeamsStale(ctx, []int{2042, 3311}, time.Now().Add(-etf2lRefreshInterval))
+		require.NoError(t, errStale)
+		require.Equal(t, []int{2042}, stale)
+
+		saved, errSaved := database.etf2lTeamGet(ctx, 3311)
+		require.NoError(t, errSaved)
+		require.Equal(t, "SG", saved.Tag)
+
+		matches, errMatches := client.teamResults(ctx, 3311)
+		require.NoError(t, errMatches)
+		require.NoError(t, database.etf2lMatchesSave(ctx, matches))
+		require.NoError(t, database.etf2lMatchesSave(ctx, matches))
+
+		history, errHistory := database.etf2lPlayerTeamHistory(ctx, steamid.Collection{sid})
+		require.NoError(t, errHistory)
+		require.Len(t, history, 2)
+		require.Equal(t, "Seagull Gaming", history[0].TeamName)
+		require.Equal(t, "SG", history[0].Tag)
+		require.Nil(t, history[0].LeftAt)
+		require.Equal(t, "Kurwa Gaming", history[1].TeamName)
+		require.NotNil(t, history[1].LeftAt)
+	}
+}
+
+func rglStoreTest(database *pgStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		current, former := steamid.New(76561197970669109), steamid.New(76561198053621664)
+
+		for _, sid := range []steamid.SteamID{current, former} {
+			record := newPlayerRecord(sid)
+			require.NoError(t, database.playerGetOrCreate(ctx, sid, &record))
+		}
+
+		require.NoError(t, database.rglSeasonInsert(ctx, domain.RGLSeason{
+			SeasonID:           9001,
+			Name:               "Sixes S99",
+			Maps:               []string{"cp_process_f12"},
+			FormatName:         "Sixes",
+			RegionName:         "NA",
+			ParticipatingTeams: []int{9101, 9102},
+			Matches:            []int{9201, 9202, 9203},
+			CreatedOn:          utcDate(2024, time.January, 1),
+		}))
+
+		scrapedOn := time.Now()
+
+		for _, teamID := range []int{9101, 9102} {
+			require.NoError(t, database.rglTeamSave(ctx, domain.RGLTeam{
+				TeamID:       teamID,
+				SeasonID:     9001,
+				DivisionID:   1,
+				DivisionName: "Invite",
+				TeamLeader:   current,
+				CreatedAt:    utcDate(2024, time.January, 1),
+				UpdatedAt:    utcDate(2024, time.January, 1),
+				Tag:          fmt.Sprintf("T%d", teamID),
+				TeamName:     fmt.Sprintf("Team %d", teamID),
+				FinalRank:    0,
+				ScrapedOn:    &scrapedOn,
+			}))
+		}
+
+		team, errTeam := database.rglTeamGet(ctx, 9101)
+		require.NoError(t, errTeam)
+		require.NotNil(t, team.ScrapedOn)
+
+		// Both players start on the roster, the next fetch of the roster no longer lists the former player.
+		require.NoError(t, database.rglTeamMemberInsert(ctx, domain.RGLTeamMember{
+			TeamID: 9101, Name: "current", IsTeamLeader: true, SteamID: current,
+			JoinedAt: utcDate(2024, time.January, 1), LeftAt: nil,
+		}))
+		require.NoError(t, database.rglTeamMemberInsert(ctx, domain.RGLTeamMember{
+			TeamID: 9101, Name: "former", IsTeamLeader: false, SteamID: former,
+			JoinedAt: utcDate(2024, time.February, 1), LeftAt: nil,
+		}))
+
+		left := utcDate(2024, time.February, 15)
+		require.NoError(t, database.rglTeamMembersLeft(ctx, 9101, steamid.Collection{current}, left))
+		// Players already marked as left keep their original left_at.
+		require.NoError(t, database.rglTeamMembersLeft(ctx, 9101, steamid.Collection{current}, time.Now()))
+
+		for idx, winner := range []int{9101, 9102, 9101} {
+			require.NoError(t, database.rglMatchInsert(ctx, domain.RGLMatch{
+				MatchID:      9201 + idx,
+				SeasonID:     9001,
+				SeasonName:   "Sixes S99",
+				DivisionName: "Invite",
+				DivisionID:   1,
+				RegionID:     1,
+				MatchDate:    utcDate(2024, time.January, 10).AddDate(0, idx, 0),
+				MatchName:    fmt.Sprintf("Week %d", idx+1),
+				Winner:       winner,
+				TeamIDA:      9101,
+				PointsA:      3,
+				TeamIDB:      9102,
+				PointsB:      1,
+			}))
+		}
+
+		seasons, errSeasons := database.rglSeasons(ctx)
+		require.NoError(t, errSeasons)
+		require.True(t, slices.ContainsFunc(seasons, func(season domain.RGLSeason) bool {
+			return season.SeasonID == 9001
+		}))
+
+		standings, errStandings := database.rglSeasonStandings(ctx, 9001)
+		require.NoError(t, errStandings)
+		require.Len(t, standings, 2)
+		require.Equal(t, 9101, standings[0].TeamID)
+		require.Equal(t, 2, standings[0].Wins)
+		require.Equal(t, 1, standings[0].Losses)
+		require.InDelta(t, 9.0, standings[0].Points, 0.01)
+		require.Equal(t, 1, standings[1].Wins)
+
+		roster, errRoster := database.rglTeamMembers(ctx, 9101)
+		require.NoError(t, errRoster)
+		require.Len(t, roster, 2)
+		require.Equal(t, current, roster[0].SteamID)
+		require.Nil(t, roster[0].LeftAt)
+		require.Equal(t, former, roster[1].SteamID)
+		require.NotNil(t, roster[1].LeftAt)
+		require.True(t, left.Equal(*roster[1].LeftAt))
+
+		teamMatches, errTeamMatches := database.rglTeamMatches(ctx, 9102)
+		require.NoError(t, errTeamMatches)
+		require.Len(t, teamMatches, 3)
+		require.Equal(t, 9203, teamMatches[0].MatchID)
+
+		currentMatches, errCurrent := database.rglPlayerMatches(ctx, current)
+		require.NoError(t, errCurrent)
+		require.Len(t, currentMatches, 3)
+
+		formerMatches, errFormer := database.rglPlayerMatches(ctx, former)
+		require.NoError(t, errFormer)
+		require.Len(t, formerMatches, 1)
+		require.Equal(t, 9202, formerMatches[0].MatchID)
+	}
+}
@@ -0,0 +1,835 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+)
+
+var (
+	errTF2LogRead      = errors.New("failed to read tf2 log")
+	errTF2LogNoMatch   = errors.New("tf2 log does not contain any completed rounds")
+	errTF2LogDuplicate = errors.New("tf2 log has already been imported")
+)
+
+const (
+	tf2LogTimeFormat = "01/02/2006 - 15:04:05"
+	// tf2LogMaxSize is the largest log accepted, full highlander matches are usually only a few MB.
+	tf2LogMaxSize = 20 << 20
+	// tf2LogMinStreak is the smallest number of kills without dying that is recorded as a killstreak.
+	tf2LogMinStreak = 3
+	// tf2LogMajorAdvantage is how long the other team has to wait for their uber, after a medic dies, for it to
+	// count as a major advantage lost.
+	tf2LogMajorAdvantage = 30 * time.Second
+	// tf2LogDeathAfterCharge is how soon after using their uber a medic has to die for it to count.
+	tf2LogDeathAfterCharge = 20 * time.Second
+)
+
+const tf2LogPlayerPattern = `"(.*?)<(\d+)><([^>]*)><([^>]*)>"`
+
+var (
+	rxTF2LogLine    = regexp.MustCompile(`^L (\d{2}/\d{2}/\d{4} - \d{2}:\d{2}:\d{2}): (.*)$`)
+	rxTF2LogActor   = regexp.MustCompile(`^` + tf2LogPlayerPattern + ` (.*)$`)
+	rxTF2LogKill    = regexp.MustCompile(`^killed ` + tf2LogPlayerPattern + ` with "([^"]*)"(.*)$`)
+	rxTF2LogTrigger = regexp.MustCompile(`^triggered "([^"]+)"(?: against ` + tf2LogPlayerPattern + `)?(.*)$`)
+	rxTF2LogSay     = regexp.MustCompile(`^say(?:_team)? "(.*)"$`)
+	rxTF2LogRole    = regexp.MustCompile(`^(?:changed role to|spawned as) "(\w+)"`)
+	rxTF2LogPickup  = regexp.MustCompile(`^picked up item "(\w+)"`)
+	rxTF2LogWorld   = regexp.MustCompile(`^World triggered "(\w+)"(.*)$`)
+	rxTF2LogScore   = regexp.MustCompile(`^Team "(\w+)" (current|final) score "(\d+)"`)
+	rxTF2LogCapture = regexp.MustCompile(`^Team "(\w+)" triggered "pointcaptured"(.*)$`)
+	rxTF2LogMap     = regexp.MustCompile(`^(?:Loading|Started) map "([^"]+)"`)
+	rxTF2LogProps   = regexp.MustCompile(`\((\w+) "([^"]*)"\)`)
+	rxTF2LogCapper  = regexp.MustCompile(`^(.*?)<(\d+)><([^>]*)><([^>]*)>$`)
+)
+
+type tf2LogWeapon struct {
+	kills  int
+	damage int
+	shots  int
+	hits   int
+}
+
+type tf2LogMedic struct {
+	stats      domain.LogsTFMedic
+	uberLens   []time.Duration
+	buildTimes []time.Duration
+	useTimes   []time.Duration
+	buildStart time.Time
+	readyAt    time.Time
+	deployedAt time.Time
+}
+
+type tf2LogPlayer struct {
+	player        domain.LogsTFPlayer
+	classes       map[domain.PlayerClass]*domain.LogsTFPlayerClass
+	weapons       map[domain.PlayerClass]map[string]*tf2LogWeapon
+	class         domain.PlayerClass
+	classSince    time.Time
+	medic         tf2LogMedic
+	headshotKills int
+	headshotHits  int
+	streak        int
+	streakStart   time.Time
+}
+
+// tf2LogParser builds a match from the events in a tf2 server log. Combat stats are only counted while a round is
+// in progress, so warmup and humiliation are excluded like they are on logs.tf.
+type tf2LogParser struct {
+	match      domain.LogsTFMatch
+	players    map[steamid.SteamID]*tf2LogPlayer
+	round      *domain.LogsTFRound
+	roundStart time.Time
+	pausedAt   time.Time
+	start      time.Time
+	now        time.Time
+	// hasHeadshotHits is set when the server logs headshots on damage events, which is preferred over counting
+	// headshot kills.
+	hasHeadshotHits bool
+	hasFinalScore   bool
+}
+
+// parseTF2Log parses a tf2 server log into a domain.LogsTFMatch. The returned match does not have a log id set yet.
+func parseTF2Log(reader io.Reader) (*domain.LogsTFMatch, error) {
+	parser := tf2LogParser{ //nolint:exhaustruct
+		players: map[steamid.SteamID]*tf2LogPlayer{},
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		parser.parseLine(strings.TrimRight(scanner.Text(), "\r"))
+	}
+
+	if errScan := scanner.Err(); errScan != nil {
+		return nil, errors.Join(errScan, errTF2LogRead)
+	}
+
+	return parser.finish()
+}
+
+// importTF2Log parses and stores a server log as a local match. Logs are identified by their checksum, so the
+// same file can't be imported twice. The map name is only used when the log doesn't include the map change, which
+// is common for logs that are started when the match begins.
+func importTF2Log(ctx context.Context, database *pgStore, body []byte, title string, mapName string) (*domain.LogsTFMatch, error) {
+	sum := sha256.Sum256(body)
+	checksum := hex.EncodeToString(sum[:])
+
+	existingID, errExisting := database.logsTFChecksumLogID(ctx, checksum)
+	if errExisting == nil {
+		return nil, fmt.Errorf("%w: log id %d", errTF2LogDuplicate, existingID)
+	} else if !errors.Is(errExisting, errDatabaseNoResults) {
+		return nil, errExisting
+	}
+
+	match, errMatch := parseTF2Log(bytes.NewReader(body))
+	if errMatch != nil {
+		return nil, errMatch
+	}
+
+	if match.Map == "" {
+		match.Map = mapName
+	}
+
+	if title != "" {
+		match.Title = title
+	} else if match.Title == "" {
+		match.Title = match.Map
+	}
+
+	logID, errLogID := database.logsTFLocalID(ctx)
+	if errLogID != nil {
+		return nil, errLogID
+	}
+
+	setLogsTFMatchID(match, logID)
+	match.Checksum = checksum
+
+	if errCreate := database.logsTFMatchCreate(ctx, match); errCreate != nil {
+		if errors.Is(errCreate, errDatabaseUnique) {
+			return nil, errors.Join(errCreate, errTF2LogDuplicate)
+		}
+
+		return nil, errCreate
+	}
+
+	return match, nil
+}
+
+// setLogsTFMatchID sets the log id of the match and all of its stats.
+func setLogsTFMatchID(match *domain.LogsTFMatch, logID int) {
+	match.LogID = logID
+
+	for idx := range match.Players {
+		match.Players[idx].LogID = logID
+
+		for classIdx := range match.Players[idx].Classes {
+			class := &match.Players[idx].Classes[classIdx]
+			class.LogID = logID
+
+			for weaponIdx := range class.Weapons {
+				class.Weapons[weaponIdx].LogID = logID
+			}
+		}
+	}
+
+	for idx := range match.Medics {
+		match.Medics[idx].LogID = logID
+	}
+
+	for idx := range match.Rounds {
+		match.Rounds[idx].LogID = logID
+	}
+
+	for idx := range match.Chat {
+		match.Chat[idx].LogID = logID
+	}
+
+	for idx := range match.Killstreaks {
+		match.Killstreaks[idx].LogID = logID
+	}
+}
+
+func tf2LogTeam(team string) domain.Team {
+	switch team {
+	case "Red":
+		return domain.RED
+	case "Blue":
+		return domain.BLU
+	default:
+		return 0
+	}
+}
+
+func tf2LogProps(value string) map[string]string {
+	props := map[string]string{}
+	for _, match := range rxTF2LogProps.FindAllStringSubmatch(value, -1) {
+		props[match[1]] = match[2]
+	}
+
+	return props
+}
+
+func (p *tf2LogParser) parseLine(line string) {
+	lineMatch := rxTF2LogLine.FindStringSubmatch(line)
+	if lineMatch == nil {
+		return
+	}
+
+	now, errTime := time.Parse(tf2LogTimeFormat, lineMatch[1])
+	if errTime != nil {
+		return
+	}
+
+	p.now = now
+	event := lineMatch[2]
+
+	if actorMatch := rxTF2LogActor.FindStringSubmatch(event); actorMatch != nil {
+		p.parsePlayerEvent(actorMatch[1], actorMatch[3], actorMatch[4], actorMatch[5])
+
+		return
+	}
+
+	if worldMatch := rxTF2LogWorld.FindStringSubmatch(event); worldMatch != nil {
+		p.parseWorldEvent(worldMatch[1], tf2LogProps(worldMatch[2]))
+
+		return
+	}
+
+	if scoreMatch := rxTF2LogScore.FindStringSubmatch(event); scoreMatch != nil {
+		score, _ := strconv.Atoi(scoreMatch[3])
+		p.setScore(tf2LogTeam(scoreMatch[1]), score, scoreMatch[2] == "final")
+
+		return
+	}
+
+	if captureMatch := rxTF2LogCapture.FindStringSubmatch(event); captureMatch != nil {
+		p.capture(tf2LogTeam(captureMatch[1]), tf2LogProps(captureMatch[2]))
+
+		return
+	}
+
+	if mapMatch := rxTF2LogMap.FindStringSubmatch(event); mapMatch != nil && p.match.Map == "" {
+		p.match.Map = mapMatch[1]
+	}
+}
+
+// player returns the stats for the player, creating them on first use. Bots and the console are ignored.
+func (p *tf2LogParser) player(name string, steamID string, team string) *tf2LogPlayer {
+	sid := steamid.New(steamID)
+	if !sid.Valid() {
+		return nil
+	}
+
+	player, found := p.players[sid]
+	if !found {
+		player = &tf2LogPlayer{ //nolint:exhaustruct
+			player:  domain.LogsTFPlayer{SteamID: sid}, //nolint:exhaustruct
+			classes: map[domain.PlayerClass]*domain.LogsTFPlayerClass{},
+			weapons: map[domain.PlayerClass]map[string]*tf2LogWeapon{},
+		}
+		player.medic.stats.SteamID = sid
+		p.players[sid] = player
+	}
+
+	player.player.Name = name
+
+	if playerTeam := tf2LogTeam(team); playerTeam != 0 {
+		player.player.Team = playerTeam
+	}
+
+	return player
+}
+
+func (p *tf2LogParser) inRound() bool {
+	return p.round != nil && p.pausedAt.IsZero()
+}
+
+func (p *tf2LogParser) parsePlayerEvent(name string, steamID string, team string, action string) {
+	if sayMatch := rxTF2LogSay.FindStringSubmatch(action); sayMatch != nil {
+		p.say(name, steamID, team, sayMatch[1])
+
+		return
+	}
+
+	actor := p.player(name, steamID, team)
+	if actor == nil {
+		return
+	}
+
+	if roleMatch := rxTF2LogRole.FindStringSubmatch(action); roleMatch != nil {
+		p.setClass(actor, stringToClass(roleMatch[1]))
+
+		return
+	}
+
+	if !p.inRound() {
+		return
+	}
+
+	if killMatch := rxTF2LogKill.FindStringSubmatch(action); killMatch != nil {
+		victim := p.player(killMatch[1], killMatch[3], killMatch[4])
+		p.kill(actor, victim, killMatch[5], tf2LogProps(killMatch[6]))
+
+		return
+	}
+
+	if triggerMatch := rxTF2LogTrigger.FindStringSubmatch(action); triggerMatch != nil {
+		var target *tf2LogPlayer
+		if triggerMatch[3] != "" {
+			target = p.player(triggerMatch[2], triggerMatch[4], triggerMatch[5])
+		}
+
+		p.trigger(actor, triggerMatch[1], target, tf2LogProps(triggerMatch[6]))
+
+		return
+	}
+
+	if pickupMatch := rxTF2LogPickup.FindStringSubmatch(action); pickupMatch != nil {
+		// Weighted the same as logs.tf
+		switch pickupMatch[1] {
+		case "medkit_small":
+			actor.player.HealthPacks++
+		case "medkit_medium":
+			actor.player.HealthPacks += 2
+		case "medkit_large":
+			actor.player.HealthPacks += 4
+		}
+
+		return
+	}
+
+	if strings.HasPrefix(action, "committed suicide") {
+		p.death(actor)
+	}
+}
+
+func (p *tf2LogParser) say(name string, steamID string, team string, message string) {
+	// Console messages are kept with the zero steam id, like the logs.tf chat.
+	var sid steamid.SteamID
+
+	if steamID != "Console" {
+		player := p.player(name, steamID, team)
+		if player == nil {
+			return
+		}
+
+		sid = player.player.SteamID
+	}
+
+	p.match.Chat = append(p.match.Chat, domain.LogsTFChat{
+		LogID:   0,
+		SteamID: sid,
+		Name:    name,
+		Message: message,
+	})
+}
+
+// class returns the stats for the players current class.
+func (p *tf2LogParser) class(player *tf2LogPlayer) *domain.LogsTFPlayerClass {
+	class, found := player.classes[player.class]
+	if !found {
+		class = &domain.LogsTFPlayerClass{SteamID: player.player.SteamID, Class: player.class} //nolint:exhaustruct
+		player.classes[player.class] = class
+	}
+
+	return class
+}
+
+func (p *tf2LogParser) weapon(player *tf2LogPlayer, name string) *tf2LogWeapon {
+	weapons, found := player.weapons[player.class]
+	if !found {
+		weapons = map[string]*tf2LogWeapon{}
+		player.weapons[player.class] = weapons
+	}
+
+	weapon, found := weapons[name]
+	if !found {
+		weapon = &tf2LogWeapon{} //nolint:exhaustruct
+		weapons[name] = weapon
+	}
+
+	return weapon
+}
+
+// flushClassTime adds the time played on the current class since it was last counted.
+func (p *tf2LogParser) flushClassTime(player *tf2LogPlayer) {
+	if player.class != domain.Spectator && p.inRound() && !player.classSince.IsZero() {
+		p.class(player).Played.Duration += p.now.Sub(player.classSince)
+	}
+
+	player.classSince = p.now
+}
+
+func (p *tf2LogParser) setClass(player *tf2LogPlayer, class domain.PlayerClass) {
+	if class == player.class {
+		return
+	}
+
+	p.flushClassTime(player)
+	player.class = class
+}
+
+func (p *tf2LogParser) kill(attacker *tf2LogPlayer, victim *tf2LogPlayer, weapon string, props map[string]string) {
+	attacker.player.Kills++
+	p.class(attacker).Kills++
+	p.weapon(attacker, weapon).kills++
+
+	switch props["customkill"] {
+	case "headshot":
+		attacker.headshotKills++
+	case "backstab":
+		attacker.player.Backstabs++
+	}
+
+	if attacker.streak == 0 {
+		attacker.streakStart = p.now
+	}
+
+	attacker.streak++
+
+	switch attacker.player.Team {
+	case domain.RED:
+		p.round.KillsRED++
+	case domain.BLU:
+		p.round.KillsBLU++
+	}
+
+	if victim != nil {
+		p.death(victim)
+	}
+}
+
+func (p *tf2LogParser) death(player *tf2LogPlayer) {
+	player.player.Deaths++
+	p.class(player).Deaths++
+	p.endStreak(player)
+}
+
+func (p *tf2LogParser) endStreak(player *tf2LogPlayer) {
+	if player.streak >= tf2LogMinStreak {
+		p.match.Killstreaks = append(p.match.Killstreaks, domain.LogsTFKillstreak{
+			LogID:   0,
+			SteamID: player.player.SteamID,
+			Streak:  player.streak,
+			Time:    domain.JSONDuration{Duration: player.streakStart.Sub(p.start)},
+		})
+	}
+
+	player.streak = 0
+}
+
+func (p *tf2LogParser) trigger(actor *tf2LogPlayer, event string, target *tf2LogPlayer, props map[string]string) {
+	value := func(key string) float64 {
+		parsed, _ := strconv.ParseFloat(props[key], 64)
+
+		return parsed
+	}
+
+	medic := &actor.medic
+
+	switch event {
+	case "kill assist":
+		actor.player.Assists++
+		p.class(actor).Assists++
+	case "damage":
+		if target == nil || target == actor {
+			return
+		}
+
+		damage := int(value("damage"))
+		actor.player.Damage += int64(damage)
+		p.class(actor).Damage += damage
+		p.weapon(actor, props["weapon"]).damage += damage
+		target.player.DamageTaken += damage
+
+		if props["airshot"] == "1" {
+			actor.player.Airshots++
+		}
+
+		if props["headshot"] == "1" {
+			p.hasHeadshotHits = true
+			actor.headshotHits++
+		}
+
+		switch actor.player.Team {
+		case domain.RED:
+			p.round.DamageRED += damage
+		case domain.BLU:
+			p.round.DamageBLU += damage
+		}
+	case "healed":
+		if target == nil {
+			return
+		}
+
+		healing := int(value("healing"))
+		medic.stats.Healing += int64(healing)
+		target.player.HealingTaken += healing
+	case "first_heal_after_spawn":
+		medic.buildStart = p.now
+	case "chargeready":
+		if !medic.buildStart.IsZero() {
+			medic.buildTimes = append(medic.buildTimes, p.now.Sub(medic.buildStart))
+		}
+
+		medic.readyAt = p.now
+	case "chargedeployed":
+		p.chargeDeployed(actor, props["medigun"])
+	case "chargeended":
+		medic.uberLens = append(medic.uberLens, time.Duration(value("duration")*float64(time.Second)))
+		medic.buildStart = p.now
+	case "medic_death":
+		if target == nil {
+			return
+		}
+
+		if props["ubercharge"] == "1" {
+			target.medic.stats.Drops++
+		}
+
+		if !target.medic.deployedAt.IsZero() && p.now.Sub(target.medic.deployedAt) <= tf2LogDeathAfterCharge {
+			target.medic.stats.DeathAfterCharge++
+		}
+
+		target.medic.buildStart = time.Time{}
+		target.medic.readyAt = time.Time{}
+		target.medic.deployedAt = time.Time{}
+	case "medic_death_ex":
+		if uber := value("uberpct"); uber >= 95 && uber < 100 {
+			medic.stats.NearFullDeath++
+		}
+	case "lost_uber_advantage":
+		lost := time.Duration(value("time") * float64(time.Second))
+		if lost >= tf2LogMajorAdvantage {
+			medic.stats.MajorAdvLost++
+		}
+
+		medic.stats.BiggestAdvLost.Duration = max(medic.stats.BiggestAdvLost.Duration, lost)
+	case "shot_fired":
+		p.weapon(actor, props["weapon"]).shots++
+	case "shot_hit":
+		p.weapon(actor, props["weapon"]).hits++
+	}
+}
+
+func (p *tf2LogParser) chargeDeployed(actor *tf2LogPlayer, medigun string) {
+	medic := &actor.medic
+
+	switch medigun {
+	case "kritzkrieg":
+		medic.stats.ChargesKritz++
+	case "quickfix":
+		medic.stats.ChargesQuickfix++
+	case "vaccinator":
+		medic.stats.ChargesVacc++
+	default:
+		medic.stats.ChargesMedigun++
+	}
+
+	if !medic.readyAt.IsZero() {
+		medic.useTimes = append(medic.useTimes, p.now.Sub(medic.readyAt))
+	}
+
+	medic.readyAt = time.Time{}
+	medic.deployedAt = p.now
+
+	switch actor.player.Team {
+	case domain.RED:
+		p.round.UbersRED++
+	case domain.BLU:
+		p.round.UbersBLU++
+	}
+}
+
+func (p *tf2LogParser) capture(team domain.Team, props map[string]string) {
+	if !p.inRound() {
+		return
+	}
+
+	if p.round.MidFight == 0 {
+		p.round.MidFight = team
+	}
+
+	for key, value := range props {
+		if !strings.HasPrefix(key, "player") {
+			continue
+		}
+
+		if capper := rxTF2LogCapper.FindStringSubmatch(value); capper != nil {
+			if player := p.player(capper[1], capper[3], capper[4]); player != nil {
+				player.player.Caps++
+			}
+		}
+	}
+}
+
+func (p *tf2LogParser) parseWorldEvent(event string, props map[string]string) {
+	switch event {
+	case "Round_Start":
+		p.startRound()
+	case "Round_Win", "Round_Stalemate", "Game_Over":
+		p.endRound()
+	case "Round_Length":
+		if seconds, errSeconds := strconv.ParseFloat(props["seconds"], 64); errSeconds == nil && len(p.match.Rounds) > 0 {
+			p.match.Rounds[len(p.match.Rounds)-1].Length.Duration = time.Duration(seconds * float64(time.Second))
+		}
+	case "Game_Paused":
+		if p.round != nil && p.pausedAt.IsZero() {
+			for _, player := range p.players {
+				p.flushClassTime(player)
+			}
+
+			p.pausedAt = p.now
+		}
+	case "Game_Unpaused":
+		if !p.pausedAt.IsZero() {
+			p.roundStart = p.roundStart.Add(p.now.Sub(p.pausedAt))
+			p.pausedAt = time.Time{}
+
+			for _, player := range p.players {
+				player.classSince = p.now
+			}
+		}
+	}
+}
+
+func (p *tf2LogParser) startRound() {
+	// Rounds restarted without finishing, eg: mp_restartgame, are dropped.
+	if p.start.IsZero() {
+		p.start = p.now
+	}
+
+	p.pausedAt = time.Time{}
+	p.roundStart = p.now
+	p.round = &domain.LogsTFRound{Round: len(p.match.Rounds) + 1} //nolint:exhaustruct
+
+	for _, player := range p.players {
+		player.classSince = p.now
+	}
+}
+
+func (p *tf2LogParser) endRound() {
+	if p.round == nil {
+		return
+	}
+
+	for _, player := range p.players {
+		p.flushClassTime(player)
+	}
+
+	p.round.Length.Duration = p.now.Sub(p.roundStart)
+	p.round.ScoreRED = p.match.ScoreRED
+	p.round.ScoreBLU = p.match.ScoreBLU
+	p.match.Rounds = append(p.match.Rounds, *p.round)
+	p.round = nil
+}
+
+// setScore updates the match score. The current score is logged after each round ends, so it's also used as the
+// score of the last round.
+func (p *tf2LogParser) setScore(team domain.Team, score int, final bool) {
+	if p.hasFinalScore && !final {
+		return
+	}
+
+	p.hasFinalScore = p.hasFinalScore || final
+
+	switch team {
+	case domain.RED:
+		p.match.ScoreRED = score
+	case domain.BLU:
+		p.match.ScoreBLU = score
+	default:
+		return
+	}
+
+	if len(p.match.Rounds) > 0 && p.round == nil {
+		last := &p.match.Rounds[len(p.match.Rounds)-1]
+		last.ScoreRED = p.match.ScoreRED
+		last.ScoreBLU = p.match.ScoreBLU
+	}
+}
+
+func (p *tf2LogParser) finish() (*domain.LogsTFMatch, error) {
+	// Logs that end mid round, eg: the server crashed, keep the partial round.
+	p.endRound()
+
+	if len(p.match.Rounds) == 0 {
+		return nil, errTF2LogNoMatch
+	}
+
+	match := p.match
+	match.Source = domain.SourceLocal
+	match.CreatedOn = p.start
+
+	if match.Title == "" {
+		match.Title = match.Map
+	}
+
+	for _, round := range match.Rounds {
+		match.Duration.Duration += round.Length.Duration
+	}
+
+	minutes := match.Duration.Minutes()
+
+	for _, player := range p.players {
+		p.endStreak(player)
+
+		if player.player.Team == 0 || len(player.classes) == 0 {
+			continue
+		}
+
+		match.Players = append(match.Players, p.finishPlayer(player, minutes))
+
+		if medic, isMedic := p.finishMedic(player, minutes); isMedic {
+			match.Medics = append(match.Medics, medic)
+		}
+	}
+
+	slices.SortFunc(match.Players, func(a, b domain.LogsTFPlayer) int {
+		return cmp.Or(cmp.Compare(b.Team, a.Team), cmp.Compare(a.SteamID.Int64(), b.SteamID.Int64()))
+	})
+
+	slices.SortFunc(match.Medics, func(a, b domain.LogsTFMedic) int {
+		return cmp.Compare(b.Healing, a.Healing)
+	})
+
+	slices.SortStableFunc(match.Killstreaks, func(a, b domain.LogsTFKillstreak) int {
+		return cmp.Compare(a.Time.Duration, b.Time.Duration)
+	})
+
+	match.Format = logsTFFormat(&match)
+
+	return &match, nil
+}
+
+func (p *tf2LogParser) finishPlayer(player *tf2LogPlayer, minutes float64) domain.LogsTFPlayer {
+	result := player.player
+	result.DPM = perMinute(result.Damage, minutes)
+	result.DTM = perMinute(int64(result.DamageTaken), minutes)
+	result.KD = float32(result.Kills) / float32(max(result.Deaths, 1))
+	result.KAD = float32(result.Kills+result.Assists) / float32(max(result.Deaths, 1))
+
+	result.Headshots = player.headshotKills
+	if p.hasHeadshotHits {
+		result.Headshots = player.headshotHits
+	}
+
+	for classID, class := range player.classes {
+		if classID == domain.Spectator {
+			continue
+		}
+
+		for name, weapon := range player.weapons[classID] {
+			accuracy := 0
+			if weapon.shots > 0 {
+				accuracy = weapon.hits * 100 / weapon.shots
+			}
+
+			class.Weapons = append(class.Weapons, domain.LogsTFPlayerClassWeapon{
+				LogID:    0,
+				SteamID:  result.SteamID,
+				Class:    classID,
+				Weapon:   name,
+				Kills:    weapon.kills,
+				Damage:   weapon.damage,
+				Accuracy: accuracy,
+			})
+		}
+
+		slices.SortFunc(class.Weapons, func(a, b domain.LogsTFPlayerClassWeapon) int {
+			return cmp.Compare(a.Weapon, b.Weapon)
+		})
+
+		result.Classes = append(result.Classes, *class)
+	}
+
+	slices.SortFunc(result.Classes, func(a, b domain.LogsTFPlayerClass) int {
+		return cmp.Or(cmp.Compare(b.Played.Duration, a.Played.Duration), cmp.Compare(a.Class, b.Class))
+	})
+
+	return result
+}
+
+// finishMedic builds the medic stats for players that played medic and healed.
+func (p *tf2LogParser) finishMedic(player *tf2LogPlayer, minutes float64) (domain.LogsTFMedic, bool) {
+	if _, playedMedic := player.classes[domain.Medic]; !playedMedic || player.medic.stats.Healing == 0 {
+		return domain.LogsTFMedic{}, false //nolint:exhaustruct
+	}
+
+	average := func(durations []time.Duration) domain.JSONDuration {
+		if len(durations) == 0 {
+			return domain.JSONDuration{}
+		}
+
+		var total time.Duration
+		for _, duration := range durations {
+			total += duration
+		}
+
+		return domain.JSONDuration{Duration: total / time.Duration(len(durations))}
+	}
+
+	medic := player.medic.stats
+	medic.HealingPerMin = perMinute(medic.Healing, minutes)
+	medic.AvgUberLen = average(player.medic.uberLens)
+	medic.AvgTimeBuild = average(player.medic.buildTimes)
+	medic.AvgTimeUse = average(player.medic.useTimes)
+
+	return medic, true
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTF2Log(t *testing.T) {
+	t.Parallel()
+
+	body, errRead := os.Open("testdata/tf2_server.log")
+	require.NoError(t, errRead)
+
+	defer logCloser(body)
+
+	match, errMatch := parseTF2Log(body)
+	require.NoError(t, errMatch)
+
+	require.Equal(t, domain.SourceLocal, match.Source)
+	require.Equal(t, "ultiduo_baloo_v2", match.Map)
+	require.Equal(t, "ultiduo_baloo_v2", match.Title)
+	require.Equal(t, domain.FormatUltiduo, match.Format)
+	require.Equal(t, 80*time.Second, match.Duration.Duration)
+	require.Equal(t, time.Date(2026, 10, 16, 12, 0, 30, 0, time.UTC), match.CreatedOn)
+	require.Equal(t, 1, match.ScoreRED)
+	require.Equal(t, 1, match.ScoreBLU)
+
+	require.Equal(t, []domain.LogsTFRound{
+		{
+			Round: 1, Length: domain.JSONDuration{Duration: 50 * time.Second}, ScoreRED: 1, ScoreBLU: 0,
+			KillsRED: 3, DamageRED: 90, DamageBLU: 40, UbersBLU: 1, MidFight: domain.RED,
+		},
+		{
+			Round: 2, Length: domain.JSONDuration{Duration: 30 * time.Second}, ScoreRED: 1, ScoreBLU: 1,
+			KillsBLU: 1,
+		},
+	}, match.Rounds)
+
+	require.Len(t, match.Players, 4)
+
+	// Warmup and humiliation kills are not counted
+	alpha := match.Players[2]
+	require.Equal(t, steamid.New("[U:1:1001]"), alpha.SteamID)
+	require.Equal(t, domain.RED, alpha.Team)
+	require.Equal(t, 3, alpha.Kills)
+	require.Equal(t, 1, alpha.Deaths)
+	require.Equal(t, int64(90), alpha.Damage)
+	require.Equal(t, 40, alpha.DamageTaken)
+	require.Equal(t, 1, alpha.Airshots)
+	require.Equal(t, 1, alpha.Caps)
+	require.Equal(t, 2, alpha.HealthPacks)
+	require.Equal(t, 50, alpha.HealingTaken)
+	require.Equal(t, []domain.LogsTFPlayerClass{{
+		SteamID: alpha.SteamID,
+		Class:   domain.Soldier,
+		Played:  domain.JSONDuration{Duration: 80 * time.Second},
+		Kills:   3,
+		Deaths:  1,
+		Damage:  90,
+		Weapons: []domain.LogsTFPlayerClassWeapon{{
+			SteamID:  alpha.SteamID,
+			Class:    domain.Soldier,
+			Weapon:   "tf_projectile_rocket",
+			Kills:    3,
+			Damage:   90,
+			Accuracy: 50,
+		}},
+	}}, alpha.Classes)
+
+	// Time spent paused is not counted
+	charlie := match.Players[0]
+	require.Equal(t, steamid.New("[U:1:1003]"), charlie.SteamID)
+	require.Equal(t, 2, charlie.Deaths)
+	require.Len(t, charlie.Classes, 2)
+	require.Equal(t, domain.Soldier, charlie.Classes[0].Class)
+	require.Equal(t, 60*time.Second, charlie.Classes[0].Played.Duration)
+	require.Equal(t, domain.Scout, charlie.Classes[1].Class)
+	require.Equal(t, 20*time.Second, charlie.Classes[1].Played.Duration)
+	require.Equal(t, 1, charlie.Classes[1].Kills)
+
+	require.Equal(t, 1, match.Players[3].Assists)
+
+	require.Len(t, match.Medics, 1)
+	require.Equal(t, steamid.New("[U:1:1002]"), match.Medics[0].SteamID)
+	require.Equal(t, int64(50), match.Medics[0].Healing)
+
+	require.Equal(t, []domain.LogsTFKillstreak{{
+		SteamID: alpha.SteamID,
+		Streak:  3,
+		Time:    domain.JSONDuration{Duration: 15 * time.Second},
+	}}, match.Killstreaks)
+
+	require.Equal(t, []domain.LogsTFChat{
+		{SteamID: alpha.SteamID, Name: "Alpha", Message: "gg"},
+		{Name: "Console", Message: "round 2"},
+	}, match.Chat)
+
+	setLogsTFMatchID(match, -5)
+	require.Equal(t, -5, match.LogID)
+	require.Equal(t, -5, match.Players[2].Classes[0].Weapons[0].LogID)
+	require.Equal(t, -5, match.Chat[1].LogID)
+}
+
+func TestParseTF2LogNoRounds(t *testing.T) {
+	t.Parallel()
+
+	_, errMatch := parseTF2Log(strings.NewReader(`L 10/16/2026 - 12:00:00: Loading map "cp_process_f12"`))
+	require.ErrorIs(t, errMatch, errTF2LogNoMatch)
+}
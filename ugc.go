@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/leighmacdonald/bd-api/domain"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"golang.org/x/time/rate"
+)
+
+var (
+	errUGCStatus   = errors.New("unexpected ugc response status")
+	errUGCNotFound = errors.New("ugc page not found")
+	errUGCDocument = errors.New("failed to parse ugc document")
+	errUGCTeam     = errors.New("failed to parse ugc team")
+)
+
+const (
+	ugcBaseURL = "https://www.ugcleague.com"
+	// ugcRefreshInterval is how long scraped team and player pages are considered fresh.
+	ugcRefreshInterval = 7 * 24 * time.Hour
+	ugcRefillRate      = 0.5
+	ugcBucketSize      = 2
+)
+
+var (
+	rxUGCPlayerID = regexp.MustCompile(`player_id=(\d+)`)
+	rxUGCClanID   = regexp.MustCompile(`clan_id=(\d+)`)
+	rxUGCTag      = regexp.MustCompile(`^(.*?)\s*\[(.*)]$`)
+)
+
+// ugcPermanent is the expiry used for bans without an end date.
+func ugcPermanent() time.Time {
+	return time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func NewUGCLimiter() *LimiterCustom {
+	return &LimiterCustom{Limiter: rate.NewLimiter(ugcRefillRate, ugcBucketSize)}
+}
+
+// ugcClient scrapes the ugcleague.com pages, UGC does not have an api.
+type ugcClient struct {
+	httpClient *http.Client
+	limiter    *LimiterCustom
+	baseURL    string
+}
+
+func newUGCClient() *ugcClient {
+	return &ugcClient{httpClient: NewHTTPClient(), limiter: NewUGCLimiter(), baseURL: ugcBaseURL}
+}
+
+// ugcPlayerTeam is a row in a players team history.
+type ugcPlayerTeam struct {
+	team   domain.UGCTeam
+	member domain.UGCTeamMember
+}
+
+func (c *ugcClient) document(ctx context.Context, path string) (*goquery.Document, error) {
+	c.limiter.Wait(ctx)
+
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if errReq != nil {
+		return nil, errors.Join(errReq, errRequestCreate)
+	}
+
+	resp, errResp := c.httpClient.Do(req)
+	if errResp != nil {
+		return nil, errors.Join(errResp, errRequestPerform)
+	}
+
+	defer logCloser(resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, errUGCNotFound
+	default:
+		return nil, fmt.Errorf("%w: %d", errUGCStatus, resp.StatusCode)
+	}
+
+	doc, errDoc := goquery.NewDocumentFromReader(resp.Body)
+	if errDoc != nil {
+		return nil, errors.Join(errDoc, errUGCDocument)
+	}
+
+	return doc, nil
+}
+
+func (c *ugcClient) bans(ctx context.Context) ([]domain.UGCBan, error) {
+	doc, errDoc := c.document(ctx, "/players_banned.cfm")
+	if errDoc != nil {
+		return nil, errDoc
+	}
+
+	return parseUGCBans(doc.Selection), nil
+}
+
+func (c *ugcClient) team(ctx context.Context, teamID int) (domain.UGCTeam, []domain.UGCTeamMember, error) {
+	doc, errDoc := c.document(ctx, "/team_page.cfm?clan_id="+strconv.Itoa(teamID))
+	if errDoc != nil {
+		return domain.UGCTeam{}, nil, errDoc
+	}
+
+	return parseUGCTeam(doc.Selection, teamID)
+}
+
+func (c *ugcClient) player(ctx context.Context, steamID steamid.SteamID) (string, []ugcPlayerTeam, error) {
+	doc, errDoc := c.document(ctx, "/players_page.cfm?player_id="+steamID.String())
+	if errDoc != nil {
+		return "", nil, errDoc
+	}
+
+	name, teams := parseUGCPlayer(doc.Selection, steamID)
+	if name == "" {
+		// Unknown players get an empty profile page rather than a 404.
+		return "", nil, errUGCNotFound
+	}
+
+	return name, teams, nil
+}
+
+// parseUGCBans parses the banned players table. Rows without a valid steam id are skipped.
+func parseUGCBans(doc *goquery.Selection) []domain.UGCBan {
+	var bans []domain.UGCBan
+
+	doc.Find("table#bans tbody tr").Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td")
+		if cells.Length() < 5 {
+			return
+		}
+
+		sid := ugcSteamID(cells.Eq(0))
+		if !sid.Valid() {
+			sid = steamid.New(cellText(cells.Eq(1)))
+		}
+
+		if !sid.Valid() {
+			return
+		}
+
+		createdAt, _ := parseUGCDate(cellText(cells.Eq(3)))
+
+		expiresAt, found := parseUGCDate(cellText(cells.Eq(4)))
+		if !found {
+			expiresAt = ugcPermanent()
+		}
+
+		reason := cellText(cells.Eq(2))
+
+		bans = append(bans, domain.UGCBan{
+			SteamID:        sid,
+			Alias:          cellText(cells.Eq(0)),
+			ExpiresAt:      expiresAt,
+			CreatedAt:      createdAt,
+			Reason:         reason,
+			ReasonCategory: classifyReason(reason),
+		})
+	})
+
+	return bans
+}
+
+// parseUGCTeam parses a team page, including the current roster.
+func parseUGCTeam(doc *goquery.Selection, teamID int) (domain.UGCTeam, []domain.UGCTeamMember, error) {
+	name, tag := cellText(doc.Find(".team-name").First()), ""
+	if match := rxUGCTag.FindStringSubmatch(name); match != nil {
+		name, tag = match[1], match[2]
+	}
+
+	if name == "" {
+		return domain.UGCTeam{}, nil, errUGCTeam
+	}
+
+	now := time.Now()
+	team := domain.UGCTeam{
+		TeamID:       teamID,
+		TeamName:     name,
+		Tag:          tag,
+		FormatName:   cellText(doc.Find(".team-info .format").First()),
+		DivisionName: cellText(doc.Find(".team-info .division").First()),
+		RegionName:   cellText(doc.Find(".team-info .region").First()),
+		ScrapedOn:    &now,
+		CreatedOn:    now,
+		UpdatedOn:    now,
+	}
+
+	var members []domain.UGCTeamMember
+
+	doc.Find("table#roster tbody tr").Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td")
+		if cells.Length() < 3 {
+			return
+		}
+
+		sid := ugcSteamID(cells.Eq(0))
+		joinedAt, found := parseUGCDate(strings.TrimPrefix(cellText(cells.Eq(2)), "Joined "))
+
+		if !sid.Valid() || !found {
+			return
+		}
+
+		members = append(members, domain.UGCTeamMember{
+			TeamID:       teamID,
+			SteamID:      sid,
+			Name:         cellText(cells.Eq(0)),
+			IsTeamLeader: strings.EqualFold(cellText(cells.Eq(1)), "leader"),
+			JoinedAt:     joinedAt,
+			LeftAt:       nil,
+		})
+	})
+
+	return team, members, nil
+}
+
+// parseUGCPlayer parses the players name and team history from their player page.
+func parseUGCPlayer(doc *goquery.Selection, steamID steamid.SteamID) (string, []ugcPlayerTeam) {
+	name := cellText(doc.Find(".player-name").First())
+
+	var teams []ugcPlayerTeam
+
+	doc.Find("table#team-history tbody tr").Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td")
+		if cells.Length() < 5 {
+			return
+		}
+
+		teamID := ugcClanID(cells.Eq(0))
+		joinedAt, found := parseUGCDate(cellText(cells.Eq(3)))
+
+		if teamID == 0 || !found {
+			return
+		}
+
+		var leftAt *time.Time
+		if left, leftFound := parseUGCDate(cellText(cells.Eq(4))); leftFound {
+			leftAt = &left
+		}
+
+		now := time.Now()
+
+		teams = append(teams, ugcPlayerTeam{
+			team: domain.UGCTeam{
+				TeamID:       teamID,
+				TeamName:     cellText(cells.Eq(0)),
+				Tag:          "",
+				FormatName:   cellText(cells.Eq(1)),
+				DivisionName: cellText(cells.Eq(2)),
+				RegionName:   "",
+				ScrapedOn:    nil,
+				CreatedOn:    now,
+				UpdatedOn:    now,
+			},
+			member: domain.UGCTeamMember{
+				TeamID:       teamID,
+				SteamID:      steamID,
+				Name:         name,
+				IsTeamLeader: false,
+				JoinedAt:     joinedAt,
+				LeftAt:       leftAt,
+			},
+		})
+	})
+
+	return name, teams
+}
+
+// parseUGCDate parses the dates used across the site. Blank values, and values such as "Permanent" or "-", are
+// reported as not found.
+func parseUGCDate(value string) (time.Time, bool) {
+	for _, layout := range []string{"Jan 2, 2006", "January 2, 2006", "01/02/2006", time.DateOnly} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func cellText(sel *goquery.Selection) string {
+	return strings.Join(strings.Fields(sel.Text()), " ")
+}
+
+// ugcSteamID reads the steam id from a players_page.cfm link.
+func ugcSteamID(sel *goquery.Selection) steamid.SteamID {
+	href, _ := sel.Find("a[href*='player_id=']").Attr("href")
+
+	match := rxUGCPlayerID.FindStringSubmatch(href)
+	if match == nil {
+		return steamid.SteamID{}
+	}
+
+	return steamid.New(match[1])
+}
+
+// ugcClanID reads the team id from a team_page.cfm link.
+func ugcClanID(sel *goquery.Selection) int {
+	href, _ := sel.Find("a[href*='clan_id=']").Attr("href")
+
+	match := rxUGCClanID.FindStringSubmatch(href)
+	if match == nil {
+		return 0
+	}
+
+	teamID, _ := strconv.Atoi(match[1])
+
+	return teamID
+}
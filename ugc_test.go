@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func readUGCFixture(t *testing.T, name string) *goquery.Selection {
+	t.Helper()
+
+	file, errOpen := os.Open("testdata/" + name)
+	require.NoError(t, errOpen)
+
+	defer logCloser(file)
+
+	doc, errDoc := goquery.NewDocumentFromReader(file)
+	require.NoError(t, errDoc)
+
+	return doc.Selection
+}
+
+func utcDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseUGCBans(t *testing.T) {
+	t.Parallel()
+
+	bans := parseUGCBans(readUGCFixture(t, "ugc_bans.html"))
+	require.Len(t, bans, 3)
+
+	require.Equal(t, steamid.New(76561198084134025), bans[0].SteamID)
+	require.Equal(t, "cheater", bans[0].Alias)
+	require.Equal(t, "Cheating - VAC ban on record", bans[0].Reason)
+	require.Equal(t, utcDate(2024, time.January, 5), bans[0].CreatedAt)
+	require.Equal(t, ugcPermanent(), bans[0].ExpiresAt)
+
+	require.Equal(t, "ringer man", bans[1].Alias)
+	require.Equal(t, utcDate(2024, time.September, 10), bans[1].ExpiresAt)
+
+	// Falls back to the steam id column when the name is not linked.
+	require.Equal(t, steamid.New("[U:1:32596031]"), bans[2].SteamID)
+}
+
+func TestParseUGCTeam(t *testing.T) {
+	t.Parallel()
+
+	team, members, errTeam := parseUGCTeam(readUGCFixture(t, "ugc_team.html"), 31337)
+	require.NoError(t, errTeam)
+
+	require.Equal(t, 31337, team.TeamID)
+	require.Equal(t, "Froyo Tech", team.TeamName)
+	require.Equal(t, "FROYO", team.Tag)
+	require.Equal(t, "TF2 Highlander", team.FormatName)
+	require.Equal(t, "Platinum", team.DivisionName)
+	require.Equal(t, "North America", team.RegionName)
+	require.NotNil(t, team.ScrapedOn)
+
+	require.Len(t, members, 2)
+	require.Equal(t, steamid.New(76561197970669109), members[0].SteamID)
+	require.True(t, members[0].IsTeamLeader)
+	require.Equal(t, utcDate(2020, time.June, 4), members[0].JoinedAt)
+	require.False(t, members[1].IsTeamLeader)
+	require.Nil(t, members[1].LeftAt)
+
+	_, _, errEmpty := parseUGCTeam(readUGCFixture(t, "ugc_player.html"), 1)
+	require.ErrorIs(t, errEmpty, errUGCTeam)
+}
+
+func TestParseUGCPlayer(t *testing.T) {
+	t.Parallel()
+
+	sid := steamid.New(76561198084134025)
+	name, teams := parseUGCPlayer(readUGCFixture(t, "ugc_player.html"), sid)
+
+	require.Equal(t, "medic main", name)
+	require.Len(t, teams, 2)
+
+	require.Equal(t, 31337, teams[0].team.TeamID)
+	require.Nil(t, teams[0].team.ScrapedOn)
+	require.Equal(t, "TF2 Highlander", teams[0].team.FormatName)
+	require.Equal(t, sid, teams[0].member.SteamID)
+	require.Equal(t, "medic main", teams[0].member.Name)
+	require.Nil(t, teams[0].member.LeftAt)
+
+	require.Equal(t, 1234, teams[1].team.TeamID)
+	require.Equal(t, "Old Team", teams[1].team.TeamName)
+	require.Equal(t, utcDate(2021, time.January, 2), teams[1].member.JoinedAt)
+	require.NotNil(t, teams[1].member.LeftAt)
+	require.Equal(t, utcDate(2023, time.July, 30), *teams[1].member.LeftAt)
+}
+
+func TestUGCClient(t *testing.T) {
+	t.Parallel()
+
+	server := newFixtureServer(t, map[string]string{
+		"/players_banned.cfm":                           "testdata/ugc_bans.html",
+		"/team_page.cfm?clan_id=31337":                  "testdata/ugc_team.html",
+		"/players_page.cfm?player_id=76561198084134025": "testdata/ugc_player.html",
+	}, nil)
+
+	client := newUGCClient()
+	client.baseURL = server.URL
+	ctx := context.Background()
+
+	bans, errBans := client.bans(ctx)
+	require.NoError(t, errBans)
+	require.Len(t, bans, 3)
+
+	team, members, errTeam := client.team(ctx, 31337)
+	require.NoError(t, errTeam)
+	require.Equal(t, "Froyo Tech", team.TeamName)
+	require.Len(t, members, 2)
+
+	_, _, errMissing := client.team(ctx, 1)
+	require.ErrorIs(t, errMissing, errUGCNotFound)
+
+	name, teams, errPlayer := client.player(ctx, steamid.New(76561198084134025))
+	require.NoError(t, errPlayer)
+	require.Equal(t, "medic main", name)
+	require.Len(t, teams, 2)
+}